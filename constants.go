@@ -1,26 +1,58 @@
 package feedstore
 
+const CASCADE_NONE = "none"
+const CASCADE_SOFT = "soft"
+const CASCADE_HARD = "hard"
+
+// PURGE_BATCH_SIZE is the number of rows deleted per statement by
+// PurgeSoftDeleted and EnforceRetention, small enough to stay under every
+// parameter limit
+const PURGE_BATCH_SIZE = 500
+
+// FEED_FETCH_INTERVAL_DEFAULT is the fetch interval in seconds of a new
+// feed, and of a feed whose fetch interval is not valid
+const FEED_FETCH_INTERVAL_DEFAULT = 600
+
 const FEED_STATUS_ACTIVE = "active"
 const FEED_STATUS_INACTIVE = "inactive"
 const LINK_STATUS_ACTIVE = "active"
 const LINK_STATUS_INACTIVE = "inactive"
 
+// LINK_STATUS_HIDDEN is the status of a link hidden by moderation
+const LINK_STATUS_HIDDEN = "hidden"
+
+// The resolutions of the reports of a link, see LinkReportResolve
+const MODERATION_DISMISS = "dismiss"
+const MODERATION_HIDE = "hide"
+const MODERATION_SOFT_DELETE = "soft_delete"
+
+const COLUMN_APPLIED_AT = "applied_at"
+const COLUMN_CASCADE_DELETED_BY = "cascade_deleted_by"
+const COLUMN_CATEGORY = "category"
 const COLUMN_CHECKED_AT = "checked_at"
 const COLUMN_CREATED_AT = "created_at"
 const COLUMN_ID = "id"
 const COLUMN_DESCRIPTION = "description"
+const COLUMN_ETAG = "etag"
 const COLUMN_FEED_ID = "feed_id"
 const COLUMN_FETCH_INTERVAL = "fetch_interval"
+const COLUMN_GUID = "guid"
 const COLUMN_LAST_FETCHED_AT = "last_fetched_at"
+const COLUMN_LAST_MODIFIED = "last_modified"
 const COLUMN_MEMO = "memo"
 const COLUMN_NAME = "name"
+const COLUMN_NEXT_FETCH_AT = "next_fetch_at"
+const COLUMN_REPORT_COUNT = "report_count"
 const COLUMN_REPORTED_AT = "reported_at"
+const COLUMN_RETENTION_MAX_AGE = "retention_max_age"
+const COLUMN_RETENTION_MAX_ITEMS = "retention_max_items"
 const COLUMN_STATUS = "status"
 const COLUMN_SOFT_DELETED_AT = "soft_deleted_at"
 const COLUMN_TIME = "time"
 const COLUMN_TITLE = "title"
 const COLUMN_UPDATED_AT = "updated_at"
 const COLUMN_URL = "url"
+const COLUMN_VERSION = "version"
 const COLUMN_VOTES_DOWN = "votes_down"
 const COLUMN_VOTES_UP = "votes_up"
 const COLUMN_VIEWS = "views"
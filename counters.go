@@ -0,0 +1,131 @@
+package feedstore
+
+import (
+	"context"
+	"log"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dracory/database"
+	"github.com/dromara/carbon/v2"
+	"github.com/spf13/cast"
+)
+
+// LinkIncrementViews adds delta to the views of the link, and returns the
+// new number of views.
+//
+// The views are incremented by a single UPDATE statement, so concurrent
+// increments are not lost. The updated at time of the link is not changed.
+func (st *storeImplementation) LinkIncrementViews(ctx context.Context, id string, delta int64) (int64, error) {
+	if delta < 0 {
+		return 0, newValidationError("delta", "delta cannot be negative")
+	}
+
+	values, err := st.linkIncrement(ctx, id, map[string]int64{COLUMN_VIEWS: delta})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return values[COLUMN_VIEWS], nil
+}
+
+// LinkVote adds an up vote, or a down vote, to the link, and returns the new
+// numbers of up and down votes.
+//
+// The vote is counted by a single UPDATE statement, so concurrent votes are
+// not lost. The updated at time of the link is not changed.
+func (st *storeImplementation) LinkVote(ctx context.Context, id string, up bool) (votesUp int64, votesDown int64, err error) {
+	increments := map[string]int64{COLUMN_VOTES_UP: 0, COLUMN_VOTES_DOWN: 1}
+
+	if up {
+		increments = map[string]int64{COLUMN_VOTES_UP: 1, COLUMN_VOTES_DOWN: 0}
+	}
+
+	values, err := st.linkIncrement(ctx, id, increments)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return values[COLUMN_VOTES_UP], values[COLUMN_VOTES_DOWN], nil
+}
+
+// linkIncrement adds the increments to the counter columns of the link,
+// which must not be soft deleted, and returns the new values of the
+// columns. The values are read in the transaction of the update, which
+// holds the row, so they include no later increments. A missing link is
+// reported when the read finds no row.
+func (st *storeImplementation) linkIncrement(ctx context.Context, id string, increments map[string]int64) (map[string]int64, error) {
+	if id == "" {
+		return nil, newValidationError("id", "link id is empty")
+	}
+
+	record := goqu.Record{}
+	columns := []any{}
+
+	for column, delta := range increments {
+		record[column] = goqu.L("? + ?", goqu.C(column), delta)
+		columns = append(columns, column)
+	}
+
+	notSoftDeleted := goqu.C(COLUMN_SOFT_DELETED_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	values := map[string]int64{}
+
+	err := st.inTx(ctx, func(txStore *storeImplementation) error {
+		sqlStr, params, errSql := goqu.Dialect(txStore.dbDriverName).
+			Update(txStore.linkTableName).
+			Prepared(true).
+			Set(record).
+			Where(goqu.C(COLUMN_ID).Eq(id), notSoftDeleted).
+			ToSQL()
+
+		if errSql != nil {
+			return errSql
+		}
+
+		if txStore.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		if _, err := database.Execute(txStore.toQueryableContext(ctx), sqlStr, params...); err != nil {
+			return err
+		}
+
+		sqlStr, params, errSql = goqu.Dialect(txStore.dbDriverName).
+			From(txStore.linkTableName).
+			Prepared(true).
+			Select(columns...).
+			Where(goqu.C(COLUMN_ID).Eq(id), notSoftDeleted).
+			ToSQL()
+
+		if errSql != nil {
+			return errSql
+		}
+
+		if txStore.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		rows, err := database.SelectToMapString(txStore.toQueryableContext(ctx), sqlStr, params...)
+
+		if err != nil {
+			return err
+		}
+
+		if len(rows) == 0 {
+			return notFoundError("link", id)
+		}
+
+		for column := range increments {
+			values[column] = cast.ToInt64(rows[0][column])
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -0,0 +1,71 @@
+package feedstore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned when the feed or link looked up does not exist
+var ErrNotFound = errors.New("feedstore: not found")
+
+// ErrValidation is matched by every ValidationError, so invalid arguments
+// and queries can be told apart with errors.Is
+var ErrValidation = errors.New("feedstore: validation failed")
+
+// ErrDuplicate is returned when a row violates a unique constraint, i.e. a
+// feed or link with an existing ID, or a link with the GUID of another link
+// of the same feed. The driver error stays available through errors.As.
+var ErrDuplicate = errors.New("feedstore: duplicate")
+
+// ValidationError reports an invalid argument or query field
+type ValidationError struct {
+	// Field is the name of the invalid argument or query field, e.g. "feed"
+	// or "status_in"
+	Field string
+
+	// Message describes what is wrong with it
+	Message string
+}
+
+// Error returns the message of the validation error
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is ErrValidation
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// newValidationError returns a ValidationError for the field
+func newValidationError(field string, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
+
+// notFoundError returns ErrNotFound annotated with what was looked up
+func notFoundError(what string, id string) error {
+	return fmt.Errorf("%w: %s %s", ErrNotFound, what, id)
+}
+
+// duplicateError wraps err with ErrDuplicate when it is a unique constraint
+// violation, and returns it unchanged otherwise
+func duplicateError(err error) error {
+	if err == nil || !isUniqueViolation(err) {
+		return err
+	}
+
+	return fmt.Errorf("%w: %w", ErrDuplicate, err)
+}
+
+// isUniqueViolation recognizes the unique constraint violations reported by
+// the supported drivers. The messages are matched, so the drivers do not
+// have to be imported.
+func isUniqueViolation(err error) bool {
+	message := err.Error()
+
+	return strings.Contains(message, "UNIQUE constraint failed") || // SQLite
+		strings.Contains(message, "Error 1062") || // MySQL, ER_DUP_ENTRY
+		strings.Contains(message, "SQLSTATE 23505") || // PostgreSQL, pgx
+		strings.Contains(message, "duplicate key value violates unique constraint") // PostgreSQL, lib/pq
+}
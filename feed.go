@@ -1,6 +1,8 @@
 package feedstore
 
 import (
+	"strconv"
+
 	"github.com/dracory/dataobject"
 	"github.com/dracory/sb"
 	"github.com/dracory/uid"
@@ -31,11 +33,16 @@ func NewFeed() *feedImplementation {
 	feed.SetID(uid.NanoUid())
 	feed.SetStatus(FEED_STATUS_INACTIVE)
 	// feed.SetName("")
+	feed.SetCategory("")
 	feed.SetDescription("")
 	feed.SetURL("")
-	feed.SetFetchInterval("600")
+	feed.SetFetchInterval(strconv.Itoa(FEED_FETCH_INTERVAL_DEFAULT))
 	feed.SetLastFetchedAt(sb.NULL_DATETIME)
+	feed.SetETag("")
+	feed.SetLastModified("")
 	feed.SetMemo("")
+	feed.SetRetentionMaxAge("0")
+	feed.SetRetentionMaxItems("0")
 	feed.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
 	feed.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
 	feed.SetSoftDeletedAt(sb.MAX_DATETIME)
@@ -57,6 +64,15 @@ func NewFeedFromExistingData(data map[string]string) *feedImplementation {
 
 // == SETTERS AND GETTERS =====================================================
 
+func (feed *feedImplementation) Category() string {
+	return feed.Get(COLUMN_CATEGORY)
+}
+
+func (feed *feedImplementation) SetCategory(category string) FeedInterface {
+	feed.Set(COLUMN_CATEGORY, category)
+	return feed
+}
+
 func (feed *feedImplementation) CreatedAt() string {
 	return feed.Get(COLUMN_CREATED_AT)
 }
@@ -77,6 +93,15 @@ func (feed *feedImplementation) SetDescription(description string) FeedInterface
 	return feed
 }
 
+func (feed *feedImplementation) ETag() string {
+	return feed.Get(COLUMN_ETAG)
+}
+
+func (feed *feedImplementation) SetETag(etag string) FeedInterface {
+	feed.Set(COLUMN_ETAG, etag)
+	return feed
+}
+
 func (feed *feedImplementation) FetchInterval() string {
 	return feed.Get(COLUMN_FETCH_INTERVAL)
 }
@@ -85,8 +110,11 @@ func (feed *feedImplementation) FetchIntervalInt64() (int64, error) {
 	return cast.ToInt64E(feed.FetchInterval())
 }
 
+// SetFetchInterval sets the number of seconds between fetches of the feed,
+// and moves the next fetch time accordingly
 func (feed *feedImplementation) SetFetchInterval(fetchInterval string) FeedInterface {
 	feed.Set(COLUMN_FETCH_INTERVAL, fetchInterval)
+	feed.SetNextFetchAt(feedNextFetchAt(feed.LastFetchedAt(), fetchInterval))
 	return feed
 }
 
@@ -102,8 +130,20 @@ func (feed *feedImplementation) SetID(id string) FeedInterface {
 func (feed *feedImplementation) LastFetchedAt() string {
 	return feed.Get(COLUMN_LAST_FETCHED_AT)
 }
+
+// SetLastFetchedAt sets the time the feed was last fetched, and moves the
+// next fetch time to one fetch interval later
 func (feed *feedImplementation) SetLastFetchedAt(lastFetchedAt string) FeedInterface {
 	feed.Set(COLUMN_LAST_FETCHED_AT, lastFetchedAt)
+	feed.SetNextFetchAt(feedNextFetchAt(lastFetchedAt, feed.FetchInterval()))
+	return feed
+}
+
+func (feed *feedImplementation) LastModified() string {
+	return feed.Get(COLUMN_LAST_MODIFIED)
+}
+func (feed *feedImplementation) SetLastModified(lastModified string) FeedInterface {
+	feed.Set(COLUMN_LAST_MODIFIED, lastModified)
 	return feed
 }
 
@@ -123,6 +163,48 @@ func (feed *feedImplementation) SetName(name string) FeedInterface {
 	return feed
 }
 
+func (feed *feedImplementation) NextFetchAt() string {
+	return feed.Get(COLUMN_NEXT_FETCH_AT)
+}
+
+// SetNextFetchAt sets the time from which the feed is due to be fetched.
+// It is kept in step by SetLastFetchedAt and SetFetchInterval, setting it
+// directly, e.g. to sb.NULL_DATETIME, schedules a fetch out of turn.
+func (feed *feedImplementation) SetNextFetchAt(nextFetchAt string) FeedInterface {
+	feed.Set(COLUMN_NEXT_FETCH_AT, nextFetchAt)
+	return feed
+}
+
+func (feed *feedImplementation) RetentionMaxAge() string {
+	return feed.Get(COLUMN_RETENTION_MAX_AGE)
+}
+
+func (feed *feedImplementation) RetentionMaxAgeInt64() (int64, error) {
+	return cast.ToInt64E(feed.RetentionMaxAge())
+}
+
+// SetRetentionMaxAge sets the age in seconds after which the links of the
+// feed are removed by EnforceRetention, "0" keeps them forever
+func (feed *feedImplementation) SetRetentionMaxAge(retentionMaxAge string) FeedInterface {
+	feed.Set(COLUMN_RETENTION_MAX_AGE, retentionMaxAge)
+	return feed
+}
+
+func (feed *feedImplementation) RetentionMaxItems() string {
+	return feed.Get(COLUMN_RETENTION_MAX_ITEMS)
+}
+
+func (feed *feedImplementation) RetentionMaxItemsInt64() (int64, error) {
+	return cast.ToInt64E(feed.RetentionMaxItems())
+}
+
+// SetRetentionMaxItems sets the number of most recent links of the feed
+// kept by EnforceRetention, "0" keeps all of them
+func (feed *feedImplementation) SetRetentionMaxItems(retentionMaxItems string) FeedInterface {
+	feed.Set(COLUMN_RETENTION_MAX_ITEMS, retentionMaxItems)
+	return feed
+}
+
 func (feed *feedImplementation) SoftDeletedAt() string {
 	return feed.Get(COLUMN_SOFT_DELETED_AT)
 }
@@ -163,3 +245,26 @@ func (feed *feedImplementation) SetURL(url string) FeedInterface {
 	feed.Set(COLUMN_URL, url)
 	return feed
 }
+
+// feedNextFetchAt returns the time a feed last fetched at lastFetchedAt is
+// due again. A feed which was never fetched is due at once. An invalid or
+// non-positive interval counts as the default interval.
+func feedNextFetchAt(lastFetchedAt string, fetchInterval string) string {
+	if lastFetchedAt == "" || lastFetchedAt == sb.NULL_DATETIME {
+		return sb.NULL_DATETIME
+	}
+
+	lastFetched := carbon.Parse(lastFetchedAt, carbon.UTC)
+
+	if !lastFetched.IsValid() {
+		return sb.NULL_DATETIME
+	}
+
+	interval, err := cast.ToInt64E(fetchInterval)
+
+	if err != nil || interval <= 0 {
+		interval = FEED_FETCH_INTERVAL_DEFAULT
+	}
+
+	return lastFetched.AddSeconds(int(interval)).ToDateTimeString(carbon.UTC)
+}
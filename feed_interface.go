@@ -7,21 +7,36 @@ type FeedInterface interface {
 	DataChanged() map[string]string
 	MarkAsNotDirty()
 
+	Category() string
+	SetCategory(category string) FeedInterface
 	CreatedAt() string
 	CreatedAtCarbon() *carbon.Carbon
 	SetCreatedAt(createdAt string) FeedInterface
 	Description() string
 	SetDescription(description string) FeedInterface
+	ETag() string
+	SetETag(etag string) FeedInterface
 	FetchInterval() string
+	FetchIntervalInt64() (int64, error)
 	SetFetchInterval(fetchInterval string) FeedInterface
 	ID() string
 	SetID(id string) FeedInterface
 	LastFetchedAt() string
 	SetLastFetchedAt(lastFetchedAt string) FeedInterface
+	LastModified() string
+	SetLastModified(lastModified string) FeedInterface
 	Memo() string
 	SetMemo(memo string) FeedInterface
 	Name() string
 	SetName(name string) FeedInterface
+	NextFetchAt() string
+	SetNextFetchAt(nextFetchAt string) FeedInterface
+	RetentionMaxAge() string
+	RetentionMaxAgeInt64() (int64, error)
+	SetRetentionMaxAge(retentionMaxAge string) FeedInterface
+	RetentionMaxItems() string
+	RetentionMaxItemsInt64() (int64, error)
+	SetRetentionMaxItems(retentionMaxItems string) FeedInterface
 	SoftDeletedAt() string
 	SoftDeletedAtCarbon() *carbon.Carbon
 	SetSoftDeletedAt(softDeletedAt string) FeedInterface
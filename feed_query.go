@@ -1,7 +1,6 @@
 package feedstore
 
 import (
-	"errors"
 	"strings"
 
 	"github.com/doug-martin/goqu/v9"
@@ -11,6 +10,9 @@ import (
 
 // feedQuery implements the FeedQueryInterface
 type feedQuery struct {
+	isCategorySet bool
+	category      string
+
 	isCountOnlySet bool
 	countOnly      bool
 
@@ -35,6 +37,12 @@ type feedQuery struct {
 	isLimitSet bool
 	limit      int
 
+	isNextFetchAtLteSet bool
+	nextFetchAtLte      string
+
+	isNextFetchAtGteSet bool
+	nextFetchAtGte      string
+
 	isOffsetSet bool
 	offset      int
 
@@ -64,6 +72,9 @@ type feedQuery struct {
 
 	isUpdatedAtLteSet bool
 	updatedAtLte      string
+
+	isURLSet bool
+	url      string
 }
 
 var _ FeedQueryInterface = (*feedQuery)(nil)
@@ -73,42 +84,80 @@ func FeedQuery() FeedQueryInterface {
 	return &feedQuery{}
 }
 
+// feedQueryCopy returns a copy of the query, which the store can change without
+// changing the query of the caller
+func feedQueryCopy(query FeedQueryInterface) (*feedQuery, error) {
+	q, ok := query.(*feedQuery)
+
+	if !ok {
+		return nil, newValidationError("query", "feed query: only queries created by FeedQuery are supported")
+	}
+
+	copied := *q
+
+	return &copied, nil
+}
+
 // Validate validates the query parameters
 func (q *feedQuery) Validate() error {
 	if q.IsOwnerIDSet() && q.GetOwnerID() == "" {
-		return errors.New("document query: owner_id cannot be empty")
+		return newValidationError("owner_id", "feed query: owner_id cannot be empty")
+	}
+
+	if q.IsCategorySet() && q.GetCategory() == "" {
+		return newValidationError("category", "feed query: category cannot be empty")
 	}
 
 	if q.IsCreatedAtGteSet() && q.GetCreatedAtGte() == "" {
-		return errors.New("document query: created_at_gte cannot be empty")
+		return newValidationError("created_at_gte", "feed query: created_at_gte cannot be empty")
 	}
 
 	if q.IsCreatedAtLteSet() && q.GetCreatedAtLte() == "" {
-		return errors.New("document query: created_at_lte cannot be empty")
+		return newValidationError("created_at_lte", "feed query: created_at_lte cannot be empty")
 	}
 
 	if q.IsIDSet() && q.GetID() == "" {
-		return errors.New("document query: id cannot be empty")
+		return newValidationError("id", "feed query: id cannot be empty")
 	}
 
 	if q.IsIDInSet() && len(q.GetIDIn()) < 1 {
-		return errors.New("document query: id_in cannot be empty array")
+		return newValidationError("id_in", "feed query: id_in cannot be empty array")
+	}
+
+	if q.IsLastFetchedAtGteSet() && q.GetLastFetchedAtGte() == "" {
+		return newValidationError("last_fetched_at_gte", "feed query: last_fetched_at_gte cannot be empty")
+	}
+
+	if q.IsLastFetchedAtLteSet() && q.GetLastFetchedAtLte() == "" {
+		return newValidationError("last_fetched_at_lte", "feed query: last_fetched_at_lte cannot be empty")
 	}
 
 	if q.IsLimitSet() && q.GetLimit() < 0 {
-		return errors.New("document query: limit cannot be negative")
+		return newValidationError("limit", "feed query: limit cannot be negative")
+	}
+
+	if q.IsNextFetchAtGteSet() && q.GetNextFetchAtGte() == "" {
+		return newValidationError("next_fetch_at_gte", "feed query: next_fetch_at_gte cannot be empty")
+	}
+
+	if q.IsNextFetchAtLteSet() && q.GetNextFetchAtLte() == "" {
+		return newValidationError("next_fetch_at_lte", "feed query: next_fetch_at_lte cannot be empty")
 	}
 
 	if q.IsOffsetSet() && q.GetOffset() < 0 {
-		return errors.New("document query: offset cannot be negative")
+		return newValidationError("offset", "feed query: offset cannot be negative")
 	}
 
 	if q.IsStatusSet() && q.GetStatus() == "" {
-		return errors.New("document query: status cannot be empty")
+		return newValidationError("status", "feed query: status cannot be empty")
 	}
 
 	if q.IsStatusInSet() && len(q.GetStatusIn()) < 1 {
-		return errors.New("document query: status_in cannot be empty array")
+		return newValidationError("status_in", "feed query: status_in cannot be empty array")
+	}
+
+	if q.IsURLSet() && q.GetURL() == "" {
+		return newValidationError("url", "feed query: url cannot be empty")
 	}
 
 	return nil
@@ -116,7 +165,7 @@ func (q *feedQuery) Validate() error {
 
 func (q *feedQuery) ToSelectDataset(st StoreInterface) (selectDataset *goqu.SelectDataset, columns []any, err error) {
 	if st == nil {
-		return nil, []any{}, errors.New("store cannot be nil")
+		return nil, []any{}, newValidationError("store", "store cannot be nil")
 	}
 
 	if err := q.Validate(); err != nil {
@@ -125,6 +174,11 @@ func (q *feedQuery) ToSelectDataset(st StoreInterface) (selectDataset *goqu.Sele
 
 	sql := goqu.Dialect(st.GetDriverName()).From(st.GetFeedTableName())
 
+	// Category filter
+	if q.IsCategorySet() {
+		sql = sql.Where(goqu.C(COLUMN_CATEGORY).Eq(q.GetCategory()))
+	}
+
 	// Created At filter
 	if q.IsCreatedAtGteSet() {
 		sql = sql.Where(goqu.C(COLUMN_CREATED_AT).Gte(q.GetCreatedAtGte()))
@@ -144,6 +198,24 @@ func (q *feedQuery) ToSelectDataset(st StoreInterface) (selectDataset *goqu.Sele
 		sql = sql.Where(goqu.C(COLUMN_ID).In(q.GetIDIn()))
 	}
 
+	// Last Fetched At filter
+	if q.IsLastFetchedAtGteSet() {
+		sql = sql.Where(goqu.C(COLUMN_LAST_FETCHED_AT).Gte(q.GetLastFetchedAtGte()))
+	}
+
+	if q.IsLastFetchedAtLteSet() {
+		sql = sql.Where(goqu.C(COLUMN_LAST_FETCHED_AT).Lte(q.GetLastFetchedAtLte()))
+	}
+
+	// Next Fetch At filter
+	if q.IsNextFetchAtGteSet() {
+		sql = sql.Where(goqu.C(COLUMN_NEXT_FETCH_AT).Gte(q.GetNextFetchAtGte()))
+	}
+
+	if q.IsNextFetchAtLteSet() {
+		sql = sql.Where(goqu.C(COLUMN_NEXT_FETCH_AT).Lte(q.GetNextFetchAtLte()))
+	}
+
 	// Status filter
 	if q.IsStatusSet() {
 		sql = sql.Where(goqu.C(COLUMN_STATUS).Eq(q.GetStatus()))
@@ -163,6 +235,11 @@ func (q *feedQuery) ToSelectDataset(st StoreInterface) (selectDataset *goqu.Sele
 		sql = sql.Where(goqu.C(COLUMN_UPDATED_AT).Lte(q.GetUpdatedAtLte()))
 	}
 
+	// URL filter
+	if q.IsURLSet() {
+		sql = sql.Where(goqu.C(COLUMN_URL).Eq(q.GetURL()))
+	}
+
 	if !q.IsCountOnlySet() {
 		if q.IsLimitSet() {
 			sql = sql.Limit(uint(q.GetLimit()))
@@ -252,6 +329,24 @@ func (q *feedQuery) SetOwnerID(ownerID string) FeedQueryInterface {
 	return q
 }
 
+func (q *feedQuery) IsCategorySet() bool {
+	return q.isCategorySet
+}
+
+func (q *feedQuery) GetCategory() string {
+	if q.IsCategorySet() {
+		return q.category
+	}
+
+	return ""
+}
+
+func (q *feedQuery) SetCategory(category string) FeedQueryInterface {
+	q.isCategorySet = true
+	q.category = category
+	return q
+}
+
 func (q *feedQuery) IsCountOnlySet() bool {
 	return q.isCountOnlySet
 }
@@ -394,6 +489,42 @@ func (q *feedQuery) SetLimit(limit int) FeedQueryInterface {
 	return q
 }
 
+func (q *feedQuery) IsNextFetchAtLteSet() bool {
+	return q.isNextFetchAtLteSet
+}
+
+func (q *feedQuery) GetNextFetchAtLte() string {
+	if q.IsNextFetchAtLteSet() {
+		return q.nextFetchAtLte
+	}
+
+	return ""
+}
+
+func (q *feedQuery) SetNextFetchAtLte(nextFetchAtLte string) FeedQueryInterface {
+	q.isNextFetchAtLteSet = true
+	q.nextFetchAtLte = nextFetchAtLte
+	return q
+}
+
+func (q *feedQuery) IsNextFetchAtGteSet() bool {
+	return q.isNextFetchAtGteSet
+}
+
+func (q *feedQuery) GetNextFetchAtGte() string {
+	if q.IsNextFetchAtGteSet() {
+		return q.nextFetchAtGte
+	}
+
+	return ""
+}
+
+func (q *feedQuery) SetNextFetchAtGte(nextFetchAtGte string) FeedQueryInterface {
+	q.isNextFetchAtGteSet = true
+	q.nextFetchAtGte = nextFetchAtGte
+	return q
+}
+
 func (q *feedQuery) IsOffsetSet() bool {
 	return q.isOffsetSet
 }
@@ -555,3 +686,21 @@ func (q *feedQuery) SetWithSoftDeleted(withSoftDeleted bool) FeedQueryInterface
 	q.withSoftDeleted = withSoftDeleted
 	return q
 }
+
+func (q *feedQuery) IsURLSet() bool {
+	return q.isURLSet
+}
+
+func (q *feedQuery) GetURL() string {
+	if q.IsURLSet() {
+		return q.url
+	}
+
+	return ""
+}
+
+func (q *feedQuery) SetURL(url string) FeedQueryInterface {
+	q.isURLSet = true
+	q.url = url
+	return q
+}
@@ -26,6 +26,10 @@ type FeedQueryInterface interface {
 
 	// Field query methods
 
+	IsCategorySet() bool
+	GetCategory() string
+	SetCategory(category string) FeedQueryInterface
+
 	IsCreatedAtGteSet() bool
 	GetCreatedAtGte() string
 	SetCreatedAtGte(createdAt string) FeedQueryInterface
@@ -54,6 +58,14 @@ type FeedQueryInterface interface {
 	GetLimit() int
 	SetLimit(limit int) FeedQueryInterface
 
+	IsNextFetchAtLteSet() bool
+	GetNextFetchAtLte() string
+	SetNextFetchAtLte(nextFetchAtLte string) FeedQueryInterface
+
+	IsNextFetchAtGteSet() bool
+	GetNextFetchAtGte() string
+	SetNextFetchAtGte(nextFetchAtGte string) FeedQueryInterface
+
 	IsOffsetSet() bool
 	GetOffset() int
 	SetOffset(offset int) FeedQueryInterface
@@ -69,6 +81,9 @@ type FeedQueryInterface interface {
 	IsStatusSet() bool
 	GetStatus() string
 	SetStatus(status string) FeedQueryInterface
+
+	IsStatusInSet() bool
+	GetStatusIn() []string
 	SetStatusIn(statuses []string) FeedQueryInterface
 
 	IsUpdatedAtGteSet() bool
@@ -78,4 +93,8 @@ type FeedQueryInterface interface {
 	IsUpdatedAtLteSet() bool
 	GetUpdatedAtLte() string
 	SetUpdatedAtLte(updatedAt string) FeedQueryInterface
+
+	IsURLSet() bool
+	GetURL() string
+	SetURL(url string) FeedQueryInterface
 }
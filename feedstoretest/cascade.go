@@ -0,0 +1,146 @@
+package feedstoretest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/dracory/feedstore"
+)
+
+// Helper function to create a feed with three links
+func createCascadeFeed(t *testing.T, store feedstore.StoreInterface) (feedstore.FeedInterface, []feedstore.LinkInterface) {
+	t.Helper()
+	ctx := context.Background()
+
+	feed := newFeed("Cascading", "https://example.com/cascading.xml")
+	if err := store.FeedCreate(ctx, feed); err != nil {
+		t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+	}
+
+	links := []feedstore.LinkInterface{}
+	for i := 0; i < 3; i++ {
+		link := newLink(feed.ID(), fmt.Sprintf("Link %d", i), fmt.Sprintf("https://example.com/%d", i))
+		if err := store.LinkCreate(ctx, link); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+		links = append(links, link)
+	}
+
+	return feed, links
+}
+
+// testFeedCascade returns the test of the links of a feed which is soft
+// deleted and then deleted, in a store with the cascade mode
+func testFeedCascade(expectedAfterSoftDelete, expectedAfterDelete, expectedStoredAfterDelete int64) func(t *testing.T, store feedstore.StoreInterface) {
+	return func(t *testing.T, store feedstore.StoreInterface) {
+		ctx := context.Background()
+		feed, _ := createCascadeFeed(t, store)
+
+		if err := store.FeedSoftDeleteByID(ctx, feed.ID()); err != nil {
+			t.Fatalf("FeedSoftDeleteByID should succeed, but got error: %v", err)
+		}
+		if count, _ := store.LinkCount(ctx, feedstore.LinkQuery().SetFeedID(feed.ID())); count != expectedAfterSoftDelete {
+			t.Errorf("Expected %d visible links after soft deleting the feed, got %d", expectedAfterSoftDelete, count)
+		}
+
+		if err := store.FeedDeleteByID(ctx, feed.ID()); err != nil {
+			t.Fatalf("FeedDeleteByID should succeed, but got error: %v", err)
+		}
+		if count, _ := store.LinkCount(ctx, feedstore.LinkQuery().SetFeedID(feed.ID())); count != expectedAfterDelete {
+			t.Errorf("Expected %d visible links after deleting the feed, got %d", expectedAfterDelete, count)
+		}
+		if count, _ := store.LinkCount(ctx, feedstore.LinkQuery().SetFeedID(feed.ID()).SetWithSoftDeleted(true)); count != expectedStoredAfterDelete {
+			t.Errorf("Expected %d stored links after deleting the feed, got %d", expectedStoredAfterDelete, count)
+		}
+	}
+}
+
+func testFeedCascadeRestore(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+	feed, links := createCascadeFeed(t, store)
+
+	// A link removed on its own before the feed must stay removed
+	links[0].SetSoftDeletedAt("2020-01-01 00:00:00")
+	if err := store.LinkUpdate(ctx, links[0]); err != nil {
+		t.Fatalf("LinkUpdate should succeed, but got error: %v", err)
+	}
+
+	if err := store.FeedSoftDelete(ctx, feed); err != nil {
+		t.Fatalf("FeedSoftDelete should succeed, but got error: %v", err)
+	}
+	if count, _ := store.LinkCount(ctx, feedstore.LinkQuery().SetFeedID(feed.ID())); count != 0 {
+		t.Fatalf("Expected the links to be soft deleted with the feed, got %d visible", count)
+	}
+
+	// A link removed on its own in the same second as the feed must stay
+	// removed too
+	late := newLink(feed.ID(), "Late", "https://example.com/late").SetSoftDeletedAt(feed.SoftDeletedAt())
+	if err := store.LinkCreate(ctx, late); err != nil {
+		t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+	}
+
+	if err := store.FeedRestoreByID(ctx, feed.ID()); err != nil {
+		t.Fatalf("FeedRestoreByID should succeed, but got error: %v", err)
+	}
+
+	restored, err := store.LinkList(ctx, feedstore.LinkQuery().SetFeedID(feed.ID()))
+	if err != nil {
+		t.Fatalf("LinkList should succeed, but got error: %v", err)
+	}
+	if !sameIDs([]string{links[1].ID(), links[2].ID()}, ids(restored)) {
+		t.Errorf("Expected only the links deleted with the feed to be restored, got %v", ids(restored))
+	}
+}
+
+func testFeedCascadeRestoreByQuery(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+	feed, links := createCascadeFeed(t, store)
+	other, otherLinks := createCascadeFeed(t, store)
+
+	for _, f := range []feedstore.FeedInterface{feed, other} {
+		if err := store.FeedSoftDelete(ctx, f); err != nil {
+			t.Fatalf("FeedSoftDelete should succeed, but got error: %v", err)
+		}
+	}
+
+	restoredCount, err := store.FeedRestoreByQuery(ctx, feedstore.FeedQuery().SetID(feed.ID()))
+	if err != nil {
+		t.Fatalf("FeedRestoreByQuery should succeed, but got error: %v", err)
+	}
+	if restoredCount != 1 {
+		t.Errorf("Expected 1 feed to be restored, got %d", restoredCount)
+	}
+
+	restored, err := store.LinkList(ctx, feedstore.LinkQuery())
+	if err != nil {
+		t.Fatalf("LinkList should succeed, but got error: %v", err)
+	}
+	if !sameIDs(ids(links), ids(restored)) {
+		t.Errorf("Expected the links of the restored feed to be restored, got %v", ids(restored))
+	}
+
+	deleted, err := store.LinkList(ctx, feedstore.LinkQuery().SetFeedID(other.ID()).SetOnlySoftDeleted(true))
+	if err != nil {
+		t.Fatalf("LinkList should succeed, but got error: %v", err)
+	}
+	if !sameIDs(ids(otherLinks), ids(deleted)) {
+		t.Errorf("Expected the links of the other feed to stay soft deleted, got %v", ids(deleted))
+	}
+}
+
+func testFeedCascadeRestoreDisabled(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+	feed, _ := createCascadeFeed(t, store)
+
+	if err := store.FeedSoftDelete(ctx, feed); err != nil {
+		t.Fatalf("FeedSoftDelete should succeed, but got error: %v", err)
+	}
+	if err := store.FeedRestoreByID(ctx, feed.ID()); err != nil {
+		t.Fatalf("FeedRestoreByID should succeed, but got error: %v", err)
+	}
+
+	if count, _ := store.LinkCount(ctx, feedstore.LinkQuery().SetFeedID(feed.ID())); count != 0 {
+		t.Errorf("Expected the links to stay soft deleted without cascade restore, got %d visible", count)
+	}
+}
@@ -0,0 +1,453 @@
+package feedstoretest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/dracory/feedstore"
+	"github.com/dracory/sb"
+)
+
+func testFeedCreate(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	feed := newFeed("Feed", "https://example.com/feed.xml").SetCategory("Tech")
+	if err := store.FeedCreate(ctx, feed); err != nil {
+		t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+	}
+	if len(feed.DataChanged()) != 0 {
+		t.Errorf("Feed should not be dirty after FeedCreate, got changes %v", feed.DataChanged())
+	}
+
+	if err := store.FeedCreate(ctx, nil); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("FeedCreate with a nil feed should return ErrValidation, but got: %v", err)
+	}
+
+	sameID := newFeed("Same ID", "https://example.com/other.xml").SetID(feed.ID())
+	if err := store.FeedCreate(ctx, sameID); !errors.Is(err, feedstore.ErrDuplicate) {
+		t.Errorf("FeedCreate with an existing ID should return ErrDuplicate, but got: %v", err)
+	}
+
+	count, err := store.FeedCount(ctx, feedstore.FeedQuery())
+	if err != nil {
+		t.Fatalf("FeedCount should succeed, but got error: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 feed, got %d", count)
+	}
+}
+
+func testFeedFindByID(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	feed := newFeed("Feed", "https://example.com/feed.xml").
+		SetCategory("Tech").
+		SetDescription("Description").
+		SetFetchInterval("3600")
+	if err := store.FeedCreate(ctx, feed); err != nil {
+		t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+	}
+
+	found, err := store.FeedFindByID(ctx, feed.ID())
+	if err != nil {
+		t.Fatalf("FeedFindByID should succeed, but got error: %v", err)
+	}
+
+	if found.ID() != feed.ID() || found.Name() != "Feed" || found.URL() != "https://example.com/feed.xml" ||
+		found.Category() != "Tech" || found.Description() != "Description" || found.FetchInterval() != "3600" ||
+		found.Status() != feedstore.FEED_STATUS_ACTIVE {
+		t.Errorf("Found feed does not match the created feed: %v", found.Data())
+	}
+	if !sameTime(feed.CreatedAt(), found.CreatedAt()) {
+		t.Errorf("Expected created at '%s', got '%s'", feed.CreatedAt(), found.CreatedAt())
+	}
+	if len(found.DataChanged()) != 0 {
+		t.Errorf("Found feed should not be dirty, got changes %v", found.DataChanged())
+	}
+
+	if _, err := store.FeedFindByID(ctx, "missing"); !errors.Is(err, feedstore.ErrNotFound) {
+		t.Errorf("FeedFindByID of a missing feed should return ErrNotFound, but got: %v", err)
+	}
+	if _, err := store.FeedFindByID(ctx, ""); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("FeedFindByID with an empty ID should return ErrValidation, but got: %v", err)
+	}
+}
+
+func testFeedUpdate(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	feed := newFeed("Before", "https://example.com/feed.xml")
+	other := newFeed("Other", "https://example.com/other.xml")
+	for _, f := range []feedstore.FeedInterface{feed, other} {
+		if err := store.FeedCreate(ctx, f); err != nil {
+			t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	feed.SetName("After").SetMemo("Memo")
+	if err := store.FeedUpdate(ctx, feed); err != nil {
+		t.Fatalf("FeedUpdate should succeed, but got error: %v", err)
+	}
+	if len(feed.DataChanged()) != 0 {
+		t.Errorf("Feed should not be dirty after FeedUpdate, got changes %v", feed.DataChanged())
+	}
+
+	found, err := store.FeedFindByID(ctx, feed.ID())
+	if err != nil {
+		t.Fatalf("FeedFindByID should succeed, but got error: %v", err)
+	}
+	if found.Name() != "After" || found.Memo() != "Memo" {
+		t.Errorf("Expected the updated name and memo, got '%s' and '%s'", found.Name(), found.Memo())
+	}
+
+	found, err = store.FeedFindByID(ctx, other.ID())
+	if err != nil {
+		t.Fatalf("FeedFindByID should succeed, but got error: %v", err)
+	}
+	if found.Name() != "Other" {
+		t.Errorf("FeedUpdate should only change its own feed, got name '%s'", found.Name())
+	}
+
+	if err := store.FeedUpdate(ctx, nil); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("FeedUpdate with a nil feed should return ErrValidation, but got: %v", err)
+	}
+
+	missing := newFeed("Missing", "https://example.com/missing.xml")
+	missing.MarkAsNotDirty()
+	missing.SetName("Changed")
+	if err := store.FeedUpdate(ctx, missing); !errors.Is(err, feedstore.ErrNotFound) {
+		t.Errorf("FeedUpdate of a missing feed should return ErrNotFound, but got: %v", err)
+	}
+}
+
+func testFeedDelete(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	byFeed := newFeed("By feed", "https://example.com/1.xml")
+	byID := newFeed("By ID", "https://example.com/2.xml")
+	kept := newFeed("Kept", "https://example.com/3.xml")
+	for _, feed := range []feedstore.FeedInterface{byFeed, byID, kept} {
+		if err := store.FeedCreate(ctx, feed); err != nil {
+			t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	if err := store.FeedDelete(ctx, byFeed); err != nil {
+		t.Fatalf("FeedDelete should succeed, but got error: %v", err)
+	}
+	if err := store.FeedDeleteByID(ctx, byID.ID()); err != nil {
+		t.Fatalf("FeedDeleteByID should succeed, but got error: %v", err)
+	}
+
+	for _, feed := range []feedstore.FeedInterface{byFeed, byID} {
+		_, err := store.FeedFindByID(ctx, feed.ID(), feedstore.FindByIDOptions{WithSoftDeleted: true})
+		if !errors.Is(err, feedstore.ErrNotFound) {
+			t.Errorf("Deleted feed %s should not be found, but got: %v", feed.Name(), err)
+		}
+	}
+
+	list, err := store.FeedList(ctx, feedstore.FeedQuery().SetWithSoftDeleted(true))
+	if err != nil {
+		t.Fatalf("FeedList should succeed, but got error: %v", err)
+	}
+	if !sameIDs([]string{kept.ID()}, ids(list)) {
+		t.Errorf("Expected only the kept feed to remain, got %v", ids(list))
+	}
+
+	if err := store.FeedDeleteByID(ctx, ""); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("FeedDeleteByID with an empty ID should return ErrValidation, but got: %v", err)
+	}
+	if err := store.FeedDeleteByID(ctx, "missing"); err != nil {
+		t.Errorf("FeedDeleteByID of a missing feed should succeed (idempotent), but got: %v", err)
+	}
+}
+
+func testFeedSoftDelete(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	byFeed := newFeed("By feed", "https://example.com/1.xml")
+	byID := newFeed("By ID", "https://example.com/2.xml")
+	kept := newFeed("Kept", "https://example.com/3.xml")
+	for _, feed := range []feedstore.FeedInterface{byFeed, byID, kept} {
+		if err := store.FeedCreate(ctx, feed); err != nil {
+			t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	if err := store.FeedSoftDelete(ctx, byFeed); err != nil {
+		t.Fatalf("FeedSoftDelete should succeed, but got error: %v", err)
+	}
+	if err := store.FeedSoftDeleteByID(ctx, byID.ID()); err != nil {
+		t.Fatalf("FeedSoftDeleteByID should succeed, but got error: %v", err)
+	}
+	if err := store.FeedSoftDeleteByID(ctx, "missing"); !errors.Is(err, feedstore.ErrNotFound) {
+		t.Errorf("FeedSoftDeleteByID of a missing feed should return ErrNotFound, but got: %v", err)
+	}
+
+	for _, feed := range []feedstore.FeedInterface{byFeed, byID} {
+		if _, err := store.FeedFindByID(ctx, feed.ID()); !errors.Is(err, feedstore.ErrNotFound) {
+			t.Errorf("Soft deleted feed %s should not be found by default, but got: %v", feed.Name(), err)
+		}
+
+		found, err := store.FeedFindByID(ctx, feed.ID(), feedstore.FindByIDOptions{WithSoftDeleted: true})
+		if err != nil {
+			t.Fatalf("FeedFindByID with soft deleted should succeed, but got error: %v", err)
+		}
+		if sameTime(sb.MAX_DATETIME, found.SoftDeletedAt()) {
+			t.Errorf("Soft deleted feed %s should have a soft deleted time", feed.Name())
+		}
+	}
+
+	visible, err := store.FeedCount(ctx, feedstore.FeedQuery())
+	if err != nil {
+		t.Fatalf("FeedCount should succeed, but got error: %v", err)
+	}
+	if visible != 1 {
+		t.Errorf("Expected 1 visible feed, got %d", visible)
+	}
+
+	softDeleted, err := store.FeedList(ctx, feedstore.FeedQuery().SetOnlySoftDeleted(true))
+	if err != nil {
+		t.Fatalf("FeedList should succeed, but got error: %v", err)
+	}
+	if !sameIDs([]string{byFeed.ID(), byID.ID()}, ids(softDeleted)) {
+		t.Errorf("Expected the soft deleted feeds, got %v", ids(softDeleted))
+	}
+}
+
+func testFeedListFilters(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	alpha := newFeed("Alpha", "https://example.com/a.xml").
+		SetCategory("Tech").
+		SetLastFetchedAt("2020-01-01 00:00:00")
+	beta := newFeed("Beta", "https://example.com/b.xml").
+		SetCategory("News").
+		SetStatus(feedstore.FEED_STATUS_INACTIVE).
+		SetLastFetchedAt("2021-01-01 00:00:00")
+	gamma := newFeed("Gamma", "https://example.com/c.xml").
+		SetCategory("Tech")
+	deleted := newFeed("Deleted", "https://example.com/d.xml").
+		SetCategory("Tech").
+		SetSoftDeletedAt("2020-06-01 00:00:00")
+
+	for _, feed := range []feedstore.FeedInterface{alpha, beta, gamma, deleted} {
+		if err := store.FeedCreate(ctx, feed); err != nil {
+			t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		query    feedstore.FeedQueryInterface
+		expected []feedstore.FeedInterface
+	}{
+		{"no filter", feedstore.FeedQuery(), []feedstore.FeedInterface{alpha, beta, gamma}},
+		{"category", feedstore.FeedQuery().SetCategory("Tech"), []feedstore.FeedInterface{alpha, gamma}},
+		{"id", feedstore.FeedQuery().SetID(beta.ID()), []feedstore.FeedInterface{beta}},
+		{"id in", feedstore.FeedQuery().SetIDIn([]string{alpha.ID(), deleted.ID()}), []feedstore.FeedInterface{alpha}},
+		{"status", feedstore.FeedQuery().SetStatus(feedstore.FEED_STATUS_ACTIVE), []feedstore.FeedInterface{alpha, gamma}},
+		{"status in", feedstore.FeedQuery().SetStatusIn([]string{feedstore.FEED_STATUS_INACTIVE}), []feedstore.FeedInterface{beta}},
+		{"url", feedstore.FeedQuery().SetURL("https://example.com/b.xml"), []feedstore.FeedInterface{beta}},
+		{"last fetched at gte", feedstore.FeedQuery().SetLastFetchedAtGte("2020-06-01 00:00:00"), []feedstore.FeedInterface{beta}},
+		{"last fetched at lte", feedstore.FeedQuery().SetLastFetchedAtLte("2020-06-01 00:00:00"), []feedstore.FeedInterface{alpha, gamma}},
+		{"next fetch at gte", feedstore.FeedQuery().SetNextFetchAtGte("2020-06-01 00:00:00"), []feedstore.FeedInterface{beta}},
+		{"next fetch at lte", feedstore.FeedQuery().SetNextFetchAtLte("2020-06-01 00:00:00"), []feedstore.FeedInterface{alpha, gamma}},
+		{"created at gte", feedstore.FeedQuery().SetCreatedAtGte("2000-01-01 00:00:00"), []feedstore.FeedInterface{alpha, beta, gamma}},
+		{"created at lte", feedstore.FeedQuery().SetCreatedAtLte("2000-01-01 00:00:00"), []feedstore.FeedInterface{}},
+		{"updated at gte", feedstore.FeedQuery().SetUpdatedAtGte(sb.MAX_DATETIME), []feedstore.FeedInterface{}},
+		{"updated at lte", feedstore.FeedQuery().SetUpdatedAtLte(sb.MAX_DATETIME), []feedstore.FeedInterface{alpha, beta, gamma}},
+		{"with soft deleted", feedstore.FeedQuery().SetWithSoftDeleted(true), []feedstore.FeedInterface{alpha, beta, gamma, deleted}},
+		{"only soft deleted", feedstore.FeedQuery().SetOnlySoftDeleted(true), []feedstore.FeedInterface{deleted}},
+		{"combined", feedstore.FeedQuery().SetCategory("Tech").SetStatus(feedstore.FEED_STATUS_ACTIVE).SetOnlySoftDeleted(true), []feedstore.FeedInterface{deleted}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			list, err := store.FeedList(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("FeedList should succeed, but got error: %v", err)
+			}
+			if !sameIDs(ids(tc.expected), ids(list)) {
+				t.Errorf("Expected feeds %v, got %v", ids(tc.expected), ids(list))
+			}
+
+			count, err := store.FeedCount(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("FeedCount should succeed, but got error: %v", err)
+			}
+			if count != int64(len(tc.expected)) {
+				t.Errorf("Expected count %d, got %d", len(tc.expected), count)
+			}
+		})
+	}
+
+	if _, err := store.FeedList(ctx, feedstore.FeedQuery().SetStatus("")); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("FeedList with an invalid query should return ErrValidation, but got: %v", err)
+	}
+	if _, err := store.FeedCount(ctx, feedstore.FeedQuery().SetLimit(-1)); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("FeedCount with an invalid query should return ErrValidation, but got: %v", err)
+	}
+}
+
+func testFeedListOrderAndPaging(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	charlie := newFeed("Charlie", "https://example.com/c.xml").SetFetchInterval("600")
+	alpha := newFeed("Alpha", "https://example.com/a.xml").SetFetchInterval("3600")
+	bravo := newFeed("Bravo", "https://example.com/b.xml").SetFetchInterval("60")
+
+	for _, feed := range []feedstore.FeedInterface{charlie, alpha, bravo} {
+		if err := store.FeedCreate(ctx, feed); err != nil {
+			t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		query    feedstore.FeedQueryInterface
+		expected []feedstore.FeedInterface
+	}{
+		{"ascending", feedstore.FeedQuery().SetOrderBy(feedstore.COLUMN_NAME).SetOrderDirection(sb.ASC), []feedstore.FeedInterface{alpha, bravo, charlie}},
+		{"descending", feedstore.FeedQuery().SetOrderBy(feedstore.COLUMN_NAME).SetOrderDirection(sb.DESC), []feedstore.FeedInterface{charlie, bravo, alpha}},
+		{"descending by default", feedstore.FeedQuery().SetOrderBy(feedstore.COLUMN_NAME), []feedstore.FeedInterface{charlie, bravo, alpha}},
+		{"numeric column", feedstore.FeedQuery().SetOrderBy(feedstore.COLUMN_FETCH_INTERVAL).SetOrderDirection(sb.ASC), []feedstore.FeedInterface{bravo, charlie, alpha}},
+		{"limit", feedstore.FeedQuery().SetOrderBy(feedstore.COLUMN_NAME).SetOrderDirection(sb.ASC).SetLimit(2), []feedstore.FeedInterface{alpha, bravo}},
+		{"limit and offset", feedstore.FeedQuery().SetOrderBy(feedstore.COLUMN_NAME).SetOrderDirection(sb.ASC).SetLimit(2).SetOffset(1), []feedstore.FeedInterface{bravo, charlie}},
+		{"offset beyond the end", feedstore.FeedQuery().SetOrderBy(feedstore.COLUMN_NAME).SetLimit(2).SetOffset(5), []feedstore.FeedInterface{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			list, err := store.FeedList(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("FeedList should succeed, but got error: %v", err)
+			}
+
+			if !slices.Equal(ids(tc.expected), ids(list)) {
+				t.Errorf("Expected feeds in order %v, got %v", ids(tc.expected), ids(list))
+			}
+		})
+	}
+
+	count, err := store.FeedCount(ctx, feedstore.FeedQuery().SetLimit(1).SetOffset(1))
+	if err != nil {
+		t.Fatalf("FeedCount should succeed, but got error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("FeedCount should ignore limit and offset, expected 3, got %d", count)
+	}
+}
+
+func testFeedCreateMany(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	first := newFeed("First", "https://example.com/1.xml")
+	second := newFeed("Second", "https://example.com/2.xml")
+	if err := store.FeedCreateMany(ctx, []feedstore.FeedInterface{first, second}); err != nil {
+		t.Fatalf("FeedCreateMany should succeed, but got error: %v", err)
+	}
+	for _, feed := range []feedstore.FeedInterface{first, second} {
+		if len(feed.DataChanged()) != 0 {
+			t.Errorf("Feed %s should not be dirty after FeedCreateMany, got changes %v", feed.Name(), feed.DataChanged())
+		}
+	}
+
+	if err := store.FeedCreateMany(ctx, nil); err != nil {
+		t.Errorf("FeedCreateMany without feeds should do nothing, but got error: %v", err)
+	}
+
+	testCases := []struct {
+		name  string
+		feeds []feedstore.FeedInterface
+		err   error
+	}{
+		{"nil feed", []feedstore.FeedInterface{newFeed("Third", "https://example.com/3.xml"), nil}, feedstore.ErrValidation},
+		{"existing ID", []feedstore.FeedInterface{newFeed("Third", "https://example.com/3.xml"), newFeed("Same ID", "https://example.com/4.xml").SetID(first.ID())}, feedstore.ErrDuplicate},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := store.FeedCreateMany(ctx, tc.feeds); !errors.Is(err, tc.err) {
+				t.Errorf("FeedCreateMany should return %v, but got: %v", tc.err, err)
+			}
+
+			list, err := store.FeedList(ctx, feedstore.FeedQuery())
+			if err != nil {
+				t.Fatalf("FeedList should succeed, but got error: %v", err)
+			}
+			if !sameIDs([]string{first.ID(), second.ID()}, ids(list)) {
+				t.Errorf("A failed FeedCreateMany should create no feeds, got %v", ids(list))
+			}
+		})
+	}
+}
+
+func testFeedRestore(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name    string
+		restore func(feed feedstore.FeedInterface) error
+	}{
+		{"restore", func(feed feedstore.FeedInterface) error {
+			return store.FeedRestore(ctx, feed)
+		}},
+		{"restore by ID", func(feed feedstore.FeedInterface) error {
+			return store.FeedRestoreByID(ctx, feed.ID())
+		}},
+		{"restore by query", func(feed feedstore.FeedInterface) error {
+			restored, err := store.FeedRestoreByQuery(ctx, feedstore.FeedQuery().SetID(feed.ID()))
+			if err == nil && restored != 1 {
+				t.Errorf("FeedRestoreByQuery should restore 1 feed, got %d", restored)
+			}
+			return err
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			feed := newFeed(tc.name, "https://example.com/feed.xml")
+			if err := store.FeedCreate(ctx, feed); err != nil {
+				t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+			}
+			if err := store.FeedSoftDelete(ctx, feed); err != nil {
+				t.Fatalf("FeedSoftDelete should succeed, but got error: %v", err)
+			}
+
+			if err := tc.restore(feed); err != nil {
+				t.Fatalf("Restoring the feed should succeed, but got error: %v", err)
+			}
+
+			found, err := store.FeedFindByID(ctx, feed.ID())
+			if err != nil {
+				t.Fatalf("Restored feed should be found, but got error: %v", err)
+			}
+			if !sameTime(sb.MAX_DATETIME, found.SoftDeletedAt()) {
+				t.Errorf("Restored feed should not have a soft deleted time, got '%s'", found.SoftDeletedAt())
+			}
+		})
+	}
+
+	if err := store.FeedRestoreByID(ctx, "missing"); !errors.Is(err, feedstore.ErrNotFound) {
+		t.Errorf("FeedRestoreByID of a missing feed should return ErrNotFound, but got: %v", err)
+	}
+	if err := store.FeedRestore(ctx, nil); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("FeedRestore with a nil feed should return ErrValidation, but got: %v", err)
+	}
+
+	query := feedstore.FeedQuery()
+
+	restored, err := store.FeedRestoreByQuery(ctx, query)
+	if err != nil {
+		t.Fatalf("FeedRestoreByQuery should succeed, but got error: %v", err)
+	}
+	if restored != 0 {
+		t.Errorf("FeedRestoreByQuery should only restore soft deleted feeds, got %d", restored)
+	}
+	if query.IsOnlySoftDeletedSet() {
+		t.Error("FeedRestoreByQuery should not change the query")
+	}
+}
@@ -0,0 +1,151 @@
+// Package feedstoretest provides conformance tests for implementations of
+// feedstore.StoreInterface, so alternative backends, decorators and wrappers
+// can prove they behave as the SQL store. The tests cover every method of
+// the interface and every query option.
+//
+// Run them from a test of the implementation:
+//
+//	func TestStoreConformance(t *testing.T) {
+//		feedstoretest.RunStoreTests(t, func(t *testing.T, options feedstoretest.StoreOptions) feedstore.StoreInterface {
+//			return newEmptyStore(t, options.CascadeMode, options.CascadeRestoreEnabled)
+//		})
+//	}
+//
+// The auto hiding of reported links depends on an option of the store, and
+// is checked by RunAutoHideTests.
+package feedstoretest
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/dracory/feedstore"
+	"github.com/dromara/carbon/v2"
+)
+
+// StoreOptions are the options a test needs the store to be created with.
+// The zero value stands for the default options.
+type StoreOptions struct {
+	// CascadeMode is one of the feedstore.CASCADE_* constants, or empty for
+	// the default
+	CascadeMode string
+
+	CascadeRestoreEnabled bool
+}
+
+// StoreFactory returns a new, empty store with its schema migrated and the
+// given options. It is called once per test.
+type StoreFactory func(t *testing.T, options StoreOptions) feedstore.StoreInterface
+
+// RunStoreTests runs the conformance tests against stores created by the
+// factory, each as a subtest
+func RunStoreTests(t *testing.T, factory StoreFactory) {
+	tests := []struct {
+		name    string
+		options StoreOptions
+		test    func(t *testing.T, store feedstore.StoreInterface)
+	}{
+		{"StoreInfo", StoreOptions{}, testStoreInfo},
+		{"Migrate", StoreOptions{}, testStoreMigrate},
+		{"WithTx", StoreOptions{}, testWithTx},
+		{"FeedCreate", StoreOptions{}, testFeedCreate},
+		{"FeedCreateMany", StoreOptions{}, testFeedCreateMany},
+		{"FeedFindByID", StoreOptions{}, testFeedFindByID},
+		{"FeedUpdate", StoreOptions{}, testFeedUpdate},
+		{"FeedDelete", StoreOptions{}, testFeedDelete},
+		{"FeedSoftDelete", StoreOptions{}, testFeedSoftDelete},
+		{"FeedRestore", StoreOptions{}, testFeedRestore},
+		{"FeedListFilters", StoreOptions{}, testFeedListFilters},
+		{"FeedListOrderAndPaging", StoreOptions{}, testFeedListOrderAndPaging},
+		{"FeedIterate", StoreOptions{}, testFeedIterate},
+		{"FeedQueryValidation", StoreOptions{}, testFeedQueryValidation},
+		{"FeedEnforceRetention", StoreOptions{}, testFeedEnforceRetention},
+		{"LinkCreate", StoreOptions{}, testLinkCreate},
+		{"LinkCreateMany", StoreOptions{}, testLinkCreateMany},
+		{"LinkFindByID", StoreOptions{}, testLinkFindByID},
+		{"LinkUpdate", StoreOptions{}, testLinkUpdate},
+		{"LinkDelete", StoreOptions{}, testLinkDelete},
+		{"LinkSoftDelete", StoreOptions{}, testLinkSoftDelete},
+		{"LinkRestore", StoreOptions{}, testLinkRestore},
+		{"LinkRestoreByQuery", StoreOptions{}, testLinkRestoreByQuery},
+		{"LinkUpsert", StoreOptions{}, testLinkUpsert},
+		{"LinkListFilters", StoreOptions{}, testLinkListFilters},
+		{"LinkListOrderAndPaging", StoreOptions{}, testLinkListOrderAndPaging},
+		{"LinkListPage", StoreOptions{}, testLinkListPage},
+		{"LinkIterate", StoreOptions{}, testLinkIterate},
+		{"LinkEngagement", StoreOptions{}, testLinkEngagement},
+		{"LinkCounters", StoreOptions{}, testLinkCounters},
+		{"LinkRanking", StoreOptions{}, testLinkRanking},
+		{"LinkReport", StoreOptions{}, testLinkReport},
+		{"LinkReportedList", StoreOptions{}, testLinkReportedList},
+		{"LinkReportResolve", StoreOptions{}, testLinkReportResolve},
+		{"LinkQueryValidation", StoreOptions{}, testLinkQueryValidation},
+		{"EnforceRetention", StoreOptions{}, testEnforceRetention},
+		{"PurgeSoftDeleted", StoreOptions{}, testPurgeSoftDeleted},
+		{"FeedCascadeNone", StoreOptions{CascadeMode: feedstore.CASCADE_NONE}, testFeedCascade(3, 3, 3)},
+		{"FeedCascadeSoft", StoreOptions{CascadeMode: feedstore.CASCADE_SOFT}, testFeedCascade(0, 0, 3)},
+		{"FeedCascadeHard", StoreOptions{CascadeMode: feedstore.CASCADE_HARD}, testFeedCascade(0, 0, 0)},
+		{"FeedCascadeRestore", StoreOptions{CascadeMode: feedstore.CASCADE_SOFT, CascadeRestoreEnabled: true}, testFeedCascadeRestore},
+		{"FeedCascadeRestoreByQuery", StoreOptions{CascadeMode: feedstore.CASCADE_SOFT, CascadeRestoreEnabled: true}, testFeedCascadeRestoreByQuery},
+		{"FeedCascadeRestoreDisabled", StoreOptions{CascadeMode: feedstore.CASCADE_SOFT}, testFeedCascadeRestoreDisabled},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := factory(t, tc.options)
+
+			if store == nil {
+				t.Fatal("StoreFactory returned a nil store")
+			}
+
+			tc.test(t, store)
+		})
+	}
+}
+
+// Helper function to create a valid active feed
+func newFeed(name string, url string) feedstore.FeedInterface {
+	return feedstore.NewFeed().
+		SetName(name).
+		SetURL(url).
+		SetStatus(feedstore.FEED_STATUS_ACTIVE)
+}
+
+// Helper function to create a valid active link of the feed
+func newLink(feedID string, title string, url string) feedstore.LinkInterface {
+	return feedstore.NewLink().
+		SetFeedID(feedID).
+		SetTitle(title).
+		SetURL(url).
+		SetStatus(feedstore.LINK_STATUS_ACTIVE)
+}
+
+// Helper function to return the IDs of feeds or links
+func ids[T interface{ ID() string }](list []T) []string {
+	result := make([]string, 0, len(list))
+
+	for _, item := range list {
+		result = append(result, item.ID())
+	}
+
+	return result
+}
+
+// Helper function to check that two lists of IDs hold the same IDs,
+// regardless of order
+func sameIDs(expected []string, actual []string) bool {
+	expected = slices.Clone(expected)
+	actual = slices.Clone(actual)
+
+	slices.Sort(expected)
+	slices.Sort(actual)
+
+	return slices.Equal(expected, actual)
+}
+
+// Helper function to compare datetimes, which drivers may return in
+// different formats
+func sameTime(expected string, actual string) bool {
+	return carbon.Parse(expected, carbon.UTC).ToDateTimeString(carbon.UTC) ==
+		carbon.Parse(actual, carbon.UTC).ToDateTimeString(carbon.UTC)
+}
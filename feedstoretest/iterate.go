@@ -0,0 +1,222 @@
+package feedstoretest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/dracory/feedstore"
+	"github.com/dracory/sb"
+)
+
+func testFeedIterate(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	feeds := []feedstore.FeedInterface{}
+	for i := range 5 {
+		feeds = append(feeds, newFeed(fmt.Sprintf("Feed %d", i), fmt.Sprintf("https://example.com/%d.xml", i)))
+	}
+	feeds[4].SetSoftDeletedAt("2020-01-01 00:00:00")
+	if err := store.FeedCreateMany(ctx, feeds); err != nil {
+		t.Fatalf("FeedCreateMany should succeed, but got error: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		query    feedstore.FeedQueryInterface
+		expected []feedstore.FeedInterface
+	}{
+		{"all", feedstore.FeedQuery().SetOrderBy(feedstore.COLUMN_NAME).SetOrderDirection(sb.ASC), feeds[:4]},
+		{"nil query", nil, feeds[:4]},
+		{"filtered", feedstore.FeedQuery().SetIDIn([]string{feeds[1].ID(), feeds[4].ID()}), feeds[1:2]},
+		{"with soft deleted", feedstore.FeedQuery().SetOrderBy(feedstore.COLUMN_NAME).SetOrderDirection(sb.ASC).SetWithSoftDeleted(true), feeds},
+		{"paged", feedstore.FeedQuery().SetOrderBy(feedstore.COLUMN_NAME).SetOrderDirection(sb.DESC).SetLimit(2).SetOffset(1), []feedstore.FeedInterface{feeds[2], feeds[1]}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := []feedstore.FeedInterface{}
+			for feed, err := range store.FeedIterate(ctx, tc.query) {
+				if err != nil {
+					t.Fatalf("FeedIterate should succeed, but got error: %v", err)
+				}
+				actual = append(actual, feed)
+			}
+
+			if tc.query != nil && tc.query.IsOrderBySet() {
+				if !slices.Equal(ids(tc.expected), ids(actual)) {
+					t.Errorf("Expected feeds in order %v, got %v", ids(tc.expected), ids(actual))
+				}
+			} else if !sameIDs(ids(tc.expected), ids(actual)) {
+				t.Errorf("Expected feeds %v, got %v", ids(tc.expected), ids(actual))
+			}
+		})
+	}
+
+	t.Run("break", func(t *testing.T) {
+		count := 0
+		for _, err := range store.FeedIterate(ctx, feedstore.FeedQuery()) {
+			if err != nil {
+				t.Fatalf("FeedIterate should succeed, but got error: %v", err)
+			}
+			count++
+			if count == 2 {
+				break
+			}
+		}
+		if count != 2 {
+			t.Errorf("Expected the iteration to stop after 2 feeds, got %d", count)
+		}
+
+		if _, err := store.FeedCount(ctx, feedstore.FeedQuery()); err != nil {
+			t.Errorf("The store should be usable after a stopped iteration, but got error: %v", err)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		count := 0
+		var iterErr error
+		for feed, err := range store.FeedIterate(ctx, feedstore.FeedQuery()) {
+			if err != nil {
+				if feed != nil {
+					t.Error("The feed yielded with an error should be nil")
+				}
+				iterErr = err
+				continue
+			}
+			count++
+			cancel()
+		}
+
+		if !errors.Is(iterErr, context.Canceled) {
+			t.Errorf("FeedIterate should yield context.Canceled, but got: %v", iterErr)
+		}
+		if count != 1 {
+			t.Errorf("Expected 1 feed before the cancellation, got %d", count)
+		}
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		errs := 0
+		for feed, err := range store.FeedIterate(ctx, feedstore.FeedQuery().SetID("")) {
+			if feed != nil || !errors.Is(err, feedstore.ErrValidation) {
+				t.Errorf("FeedIterate with an invalid query should yield ErrValidation, but got: %v", err)
+			}
+			errs++
+		}
+		if errs != 1 {
+			t.Errorf("Expected a single error, got %d", errs)
+		}
+	})
+}
+
+func testLinkIterate(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	// More links than LinkList lists without a limit
+	links := []feedstore.LinkInterface{}
+	for i := range 1005 {
+		links = append(links, newLink("feed1", fmt.Sprintf("Link %04d", i), fmt.Sprintf("https://example.com/%d", i)))
+	}
+	links[0].SetFeedID("feed2")
+	links[1].SetSoftDeletedAt("2020-01-01 00:00:00")
+	if err := store.LinkCreateMany(ctx, links); err != nil {
+		t.Fatalf("LinkCreateMany should succeed, but got error: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		query    feedstore.LinkQueryInterface
+		expected []feedstore.LinkInterface
+	}{
+		{"all without limit", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_TITLE).SetOrderDirection(sb.ASC), append([]feedstore.LinkInterface{links[0]}, links[2:]...)},
+		{"nil query", nil, append([]feedstore.LinkInterface{links[0]}, links[2:]...)},
+		{"filtered", feedstore.LinkQuery().SetFeedID("feed2"), links[:1]},
+		{"only soft deleted", feedstore.LinkQuery().SetOnlySoftDeleted(true), links[1:2]},
+		{"paged", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_TITLE).SetOrderDirection(sb.ASC).SetLimit(3).SetOffset(10), links[11:14]},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := []feedstore.LinkInterface{}
+			for link, err := range store.LinkIterate(ctx, tc.query) {
+				if err != nil {
+					t.Fatalf("LinkIterate should succeed, but got error: %v", err)
+				}
+				actual = append(actual, link)
+			}
+
+			if tc.query != nil && tc.query.IsOrderBySet() {
+				if !slices.Equal(ids(tc.expected), ids(actual)) {
+					t.Errorf("Expected %d links in order, got %d links", len(tc.expected), len(actual))
+				}
+			} else if !sameIDs(ids(tc.expected), ids(actual)) {
+				t.Errorf("Expected %d links, got %d links", len(tc.expected), len(actual))
+			}
+		})
+	}
+
+	t.Run("break", func(t *testing.T) {
+		count := 0
+		for _, err := range store.LinkIterate(ctx, feedstore.LinkQuery()) {
+			if err != nil {
+				t.Fatalf("LinkIterate should succeed, but got error: %v", err)
+			}
+			count++
+			if count == 2 {
+				break
+			}
+		}
+		if count != 2 {
+			t.Errorf("Expected the iteration to stop after 2 links, got %d", count)
+		}
+
+		if _, err := store.LinkCount(ctx, feedstore.LinkQuery()); err != nil {
+			t.Errorf("The store should be usable after a stopped iteration, but got error: %v", err)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		count := 0
+		var iterErr error
+		for link, err := range store.LinkIterate(ctx, feedstore.LinkQuery()) {
+			if err != nil {
+				if link != nil {
+					t.Error("The link yielded with an error should be nil")
+				}
+				iterErr = err
+				continue
+			}
+			count++
+			cancel()
+		}
+
+		if !errors.Is(iterErr, context.Canceled) {
+			t.Errorf("LinkIterate should yield context.Canceled, but got: %v", iterErr)
+		}
+		if count != 1 {
+			t.Errorf("Expected 1 link before the cancellation, got %d", count)
+		}
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		errs := 0
+		for link, err := range store.LinkIterate(ctx, feedstore.LinkQuery().SetLimit(-1)) {
+			if link != nil || !errors.Is(err, feedstore.ErrValidation) {
+				t.Errorf("LinkIterate with an invalid query should yield ErrValidation, but got: %v", err)
+			}
+			errs++
+		}
+		if errs != 1 {
+			t.Errorf("Expected a single error, got %d", errs)
+		}
+	})
+}
@@ -0,0 +1,1092 @@
+package feedstoretest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dracory/feedstore"
+	"github.com/dracory/sb"
+	"github.com/dromara/carbon/v2"
+)
+
+func testLinkCreate(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	link := newLink("feed1", "Link", "https://example.com/1").SetGUID("guid1")
+	if err := store.LinkCreate(ctx, link); err != nil {
+		t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+	}
+	if len(link.DataChanged()) != 0 {
+		t.Errorf("Link should not be dirty after LinkCreate, got changes %v", link.DataChanged())
+	}
+
+	if err := store.LinkCreate(ctx, nil); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("LinkCreate with a nil link should return ErrValidation, but got: %v", err)
+	}
+
+	sameID := newLink("feed1", "Same ID", "https://example.com/2").SetID(link.ID())
+	if err := store.LinkCreate(ctx, sameID); !errors.Is(err, feedstore.ErrDuplicate) {
+		t.Errorf("LinkCreate with an existing ID should return ErrDuplicate, but got: %v", err)
+	}
+
+	sameGUID := newLink("feed1", "Same GUID", "https://example.com/3").SetGUID("guid1")
+	if err := store.LinkCreate(ctx, sameGUID); !errors.Is(err, feedstore.ErrDuplicate) {
+		t.Errorf("LinkCreate with a GUID existing in the feed should return ErrDuplicate, but got: %v", err)
+	}
+
+	otherFeed := newLink("feed2", "Other feed", "https://example.com/4").SetGUID("guid1")
+	if err := store.LinkCreate(ctx, otherFeed); err != nil {
+		t.Errorf("LinkCreate with a GUID existing in another feed should succeed, but got error: %v", err)
+	}
+
+	count, err := store.LinkCount(ctx, feedstore.LinkQuery())
+	if err != nil {
+		t.Fatalf("LinkCount should succeed, but got error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 links, got %d", count)
+	}
+}
+
+func testLinkFindByID(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	link := newLink("feed1", "Link", "https://example.com/1").
+		SetGUID("guid1").
+		SetDescription("Description").
+		SetTime("2020-01-02 03:04:05")
+	if err := store.LinkCreate(ctx, link); err != nil {
+		t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+	}
+
+	found, err := store.LinkFindByID(ctx, link.ID())
+	if err != nil {
+		t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+	}
+
+	if found.ID() != link.ID() || found.FeedID() != "feed1" || found.GUID() != "guid1" || found.Title() != "Link" ||
+		found.URL() != "https://example.com/1" || found.Description() != "Description" ||
+		found.Status() != feedstore.LINK_STATUS_ACTIVE {
+		t.Errorf("Found link does not match the created link: %v", found.Data())
+	}
+	if !sameTime("2020-01-02 03:04:05", found.Time()) {
+		t.Errorf("Expected time '2020-01-02 03:04:05', got '%s'", found.Time())
+	}
+	if len(found.DataChanged()) != 0 {
+		t.Errorf("Found link should not be dirty, got changes %v", found.DataChanged())
+	}
+
+	if _, err := store.LinkFindByID(ctx, "missing"); !errors.Is(err, feedstore.ErrNotFound) {
+		t.Errorf("LinkFindByID of a missing link should return ErrNotFound, but got: %v", err)
+	}
+	if _, err := store.LinkFindByID(ctx, ""); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("LinkFindByID with an empty ID should return ErrValidation, but got: %v", err)
+	}
+}
+
+func testLinkUpdate(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	link := newLink("feed1", "Before", "https://example.com/1").SetGUID("guid1")
+	other := newLink("feed1", "Other", "https://example.com/2").SetGUID("guid2")
+	for _, l := range []feedstore.LinkInterface{link, other} {
+		if err := store.LinkCreate(ctx, l); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	link.SetTitle("After").SetStatus(feedstore.LINK_STATUS_INACTIVE)
+	if err := store.LinkUpdate(ctx, link); err != nil {
+		t.Fatalf("LinkUpdate should succeed, but got error: %v", err)
+	}
+	if len(link.DataChanged()) != 0 {
+		t.Errorf("Link should not be dirty after LinkUpdate, got changes %v", link.DataChanged())
+	}
+
+	found, err := store.LinkFindByID(ctx, link.ID())
+	if err != nil {
+		t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+	}
+	if found.Title() != "After" || found.Status() != feedstore.LINK_STATUS_INACTIVE {
+		t.Errorf("Expected the updated title and status, got '%s' and '%s'", found.Title(), found.Status())
+	}
+
+	found, err = store.LinkFindByID(ctx, other.ID())
+	if err != nil {
+		t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+	}
+	if found.Title() != "Other" {
+		t.Errorf("LinkUpdate should only change its own link, got title '%s'", found.Title())
+	}
+
+	other.SetGUID("guid1")
+	if err := store.LinkUpdate(ctx, other); !errors.Is(err, feedstore.ErrDuplicate) {
+		t.Errorf("LinkUpdate to a GUID existing in the feed should return ErrDuplicate, but got: %v", err)
+	}
+
+	if err := store.LinkUpdate(ctx, nil); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("LinkUpdate with a nil link should return ErrValidation, but got: %v", err)
+	}
+
+	missing := newLink("feed1", "Missing", "https://example.com/missing")
+	missing.MarkAsNotDirty()
+	missing.SetTitle("Changed")
+	if err := store.LinkUpdate(ctx, missing); !errors.Is(err, feedstore.ErrNotFound) {
+		t.Errorf("LinkUpdate of a missing link should return ErrNotFound, but got: %v", err)
+	}
+}
+
+func testLinkDelete(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	byLink := newLink("feed1", "By link", "https://example.com/1")
+	byID := newLink("feed1", "By ID", "https://example.com/2")
+	kept := newLink("feed1", "Kept", "https://example.com/3")
+	for _, link := range []feedstore.LinkInterface{byLink, byID, kept} {
+		if err := store.LinkCreate(ctx, link); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	if err := store.LinkDelete(ctx, byLink); err != nil {
+		t.Fatalf("LinkDelete should succeed, but got error: %v", err)
+	}
+	if err := store.LinkDeleteByID(ctx, byID.ID()); err != nil {
+		t.Fatalf("LinkDeleteByID should succeed, but got error: %v", err)
+	}
+
+	list, err := store.LinkList(ctx, feedstore.LinkQuery().SetWithSoftDeleted(true))
+	if err != nil {
+		t.Fatalf("LinkList should succeed, but got error: %v", err)
+	}
+	if !sameIDs([]string{kept.ID()}, ids(list)) {
+		t.Errorf("Expected only the kept link to remain, got %v", ids(list))
+	}
+
+	if err := store.LinkDeleteByID(ctx, ""); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("LinkDeleteByID with an empty ID should return ErrValidation, but got: %v", err)
+	}
+	if err := store.LinkDeleteByID(ctx, "missing"); err != nil {
+		t.Errorf("LinkDeleteByID of a missing link should succeed (idempotent), but got: %v", err)
+	}
+}
+
+func testLinkSoftDelete(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	byLink := newLink("feed1", "By link", "https://example.com/1")
+	byID := newLink("feed1", "By ID", "https://example.com/2")
+	kept := newLink("feed1", "Kept", "https://example.com/3")
+	for _, link := range []feedstore.LinkInterface{byLink, byID, kept} {
+		if err := store.LinkCreate(ctx, link); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	if err := store.LinkSoftDelete(ctx, byLink); err != nil {
+		t.Fatalf("LinkSoftDelete should succeed, but got error: %v", err)
+	}
+	if err := store.LinkSoftDeleteByID(ctx, byID.ID()); err != nil {
+		t.Fatalf("LinkSoftDeleteByID should succeed, but got error: %v", err)
+	}
+	if err := store.LinkSoftDeleteByID(ctx, "missing"); !errors.Is(err, feedstore.ErrNotFound) {
+		t.Errorf("LinkSoftDeleteByID of a missing link should return ErrNotFound, but got: %v", err)
+	}
+
+	for _, link := range []feedstore.LinkInterface{byLink, byID} {
+		if _, err := store.LinkFindByID(ctx, link.ID()); !errors.Is(err, feedstore.ErrNotFound) {
+			t.Errorf("Soft deleted link %s should not be found by default, but got: %v", link.Title(), err)
+		}
+
+		found, err := store.LinkFindByID(ctx, link.ID(), feedstore.FindByIDOptions{WithSoftDeleted: true})
+		if err != nil {
+			t.Fatalf("LinkFindByID with soft deleted should succeed, but got error: %v", err)
+		}
+		if sameTime(sb.MAX_DATETIME, found.SoftDeletedAt()) {
+			t.Errorf("Soft deleted link %s should have a soft deleted time", link.Title())
+		}
+	}
+
+	softDeleted, err := store.LinkCount(ctx, feedstore.LinkQuery().SetOnlySoftDeleted(true))
+	if err != nil {
+		t.Fatalf("LinkCount should succeed, but got error: %v", err)
+	}
+	if softDeleted != 2 {
+		t.Errorf("Expected 2 soft deleted links, got %d", softDeleted)
+	}
+}
+
+func testLinkUpsert(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	original := newLink("feed1", "Original", "https://example.com/1").
+		SetGUID("guid1").
+		SetDescription("Original description")
+	if err := store.LinkUpsert(ctx, original); err != nil {
+		t.Fatalf("LinkUpsert of a new link should succeed, but got error: %v", err)
+	}
+
+	updated := newLink("feed1", "Updated", "https://example.com/changed").
+		SetGUID("guid1").
+		SetDescription("Updated description")
+	if err := store.LinkUpsert(ctx, updated); err != nil {
+		t.Fatalf("LinkUpsert of an existing link should succeed, but got error: %v", err)
+	}
+
+	if updated.ID() != original.ID() {
+		t.Errorf("LinkUpsert should set the ID of the stored link, expected '%s', got '%s'", original.ID(), updated.ID())
+	}
+
+	list, err := store.LinkList(ctx, feedstore.LinkQuery().SetFeedID("feed1"))
+	if err != nil {
+		t.Fatalf("LinkList should succeed, but got error: %v", err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("Expected 1 link after the upserts, got %d", len(list))
+	}
+	if list[0].Title() != "Updated" || list[0].Description() != "Updated description" {
+		t.Errorf("Expected the title and description to be updated, got '%s' and '%s'", list[0].Title(), list[0].Description())
+	}
+	if list[0].URL() != "https://example.com/1" {
+		t.Errorf("LinkUpsert should keep the URL of the stored link, got '%s'", list[0].URL())
+	}
+
+	otherFeed := newLink("feed2", "Other feed", "https://example.com/2").SetGUID("guid1")
+	if err := store.LinkUpsert(ctx, otherFeed); err != nil {
+		t.Fatalf("LinkUpsert in another feed should succeed, but got error: %v", err)
+	}
+	if otherFeed.ID() == original.ID() {
+		t.Error("LinkUpsert in another feed should create a new link")
+	}
+
+	if err := store.LinkUpsert(ctx, newLink("", "No feed", "https://example.com/3")); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("LinkUpsert without feed ID should return ErrValidation, but got: %v", err)
+	}
+}
+
+func testLinkListFilters(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	first := newLink("feed1", "First", "https://example.com/1").SetGUID("guid1")
+	second := newLink("feed1", "Second", "https://example.com/2").
+		SetGUID("guid2").
+		SetStatus(feedstore.LINK_STATUS_INACTIVE)
+	third := newLink("feed2", "Third", "https://example.com/3").SetGUID("guid1")
+	deleted := newLink("feed1", "Deleted", "https://example.com/4").
+		SetGUID("guid4").
+		SetSoftDeletedAt("2020-06-01 00:00:00")
+
+	for _, link := range []feedstore.LinkInterface{first, second, third, deleted} {
+		if err := store.LinkCreate(ctx, link); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		query    feedstore.LinkQueryInterface
+		expected []feedstore.LinkInterface
+	}{
+		{"no filter", feedstore.LinkQuery(), []feedstore.LinkInterface{first, second, third}},
+		{"feed id", feedstore.LinkQuery().SetFeedID("feed1"), []feedstore.LinkInterface{first, second}},
+		{"guid", feedstore.LinkQuery().SetGUID("guid1"), []feedstore.LinkInterface{first, third}},
+		{"id", feedstore.LinkQuery().SetID(second.ID()), []feedstore.LinkInterface{second}},
+		{"id in", feedstore.LinkQuery().SetIDIn([]string{first.ID(), deleted.ID()}), []feedstore.LinkInterface{first}},
+		{"status", feedstore.LinkQuery().SetStatus(feedstore.LINK_STATUS_ACTIVE), []feedstore.LinkInterface{first, third}},
+		{"status in", feedstore.LinkQuery().SetStatusIn([]string{feedstore.LINK_STATUS_INACTIVE}), []feedstore.LinkInterface{second}},
+		{"url", feedstore.LinkQuery().SetURL("https://example.com/3"), []feedstore.LinkInterface{third}},
+		{"created at gte", feedstore.LinkQuery().SetCreatedAtGte("2000-01-01 00:00:00"), []feedstore.LinkInterface{first, second, third}},
+		{"created at lte", feedstore.LinkQuery().SetCreatedAtLte("2000-01-01 00:00:00"), []feedstore.LinkInterface{}},
+		{"updated at gte", feedstore.LinkQuery().SetUpdatedAtGte(sb.MAX_DATETIME), []feedstore.LinkInterface{}},
+		{"updated at lte", feedstore.LinkQuery().SetUpdatedAtLte(sb.MAX_DATETIME), []feedstore.LinkInterface{first, second, third}},
+		{"with soft deleted", feedstore.LinkQuery().SetWithSoftDeleted(true), []feedstore.LinkInterface{first, second, third, deleted}},
+		{"only soft deleted", feedstore.LinkQuery().SetOnlySoftDeleted(true), []feedstore.LinkInterface{deleted}},
+		{"combined", feedstore.LinkQuery().SetFeedID("feed1").SetStatus(feedstore.LINK_STATUS_ACTIVE).SetWithSoftDeleted(true), []feedstore.LinkInterface{first, deleted}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			list, err := store.LinkList(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("LinkList should succeed, but got error: %v", err)
+			}
+			if !sameIDs(ids(tc.expected), ids(list)) {
+				t.Errorf("Expected links %v, got %v", ids(tc.expected), ids(list))
+			}
+
+			count, err := store.LinkCount(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("LinkCount should succeed, but got error: %v", err)
+			}
+			if count != int64(len(tc.expected)) {
+				t.Errorf("Expected count %d, got %d", len(tc.expected), count)
+			}
+		})
+	}
+
+	if _, err := store.LinkList(ctx, feedstore.LinkQuery().SetGUID("")); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("LinkList with an invalid query should return ErrValidation, but got: %v", err)
+	}
+	if _, err := store.LinkCount(ctx, feedstore.LinkQuery().SetIDIn([]string{})); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("LinkCount with an invalid query should return ErrValidation, but got: %v", err)
+	}
+}
+
+func testLinkListOrderAndPaging(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	middle := newLink("feed1", "Bravo", "https://example.com/2").SetTime("2020-02-01 00:00:00")
+	oldest := newLink("feed1", "Charlie", "https://example.com/3").SetTime("2020-01-01 00:00:00")
+	newest := newLink("feed1", "Alpha", "https://example.com/1").SetTime("2020-03-01 00:00:00")
+
+	for _, link := range []feedstore.LinkInterface{middle, oldest, newest} {
+		if err := store.LinkCreate(ctx, link); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		query    feedstore.LinkQueryInterface
+		expected []feedstore.LinkInterface
+	}{
+		{"time ascending", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_TIME).SetOrderDirection(sb.ASC), []feedstore.LinkInterface{oldest, middle, newest}},
+		{"time descending", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_TIME).SetOrderDirection(sb.DESC), []feedstore.LinkInterface{newest, middle, oldest}},
+		{"descending by default", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_TITLE), []feedstore.LinkInterface{oldest, middle, newest}},
+		{"limit", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_TIME).SetLimit(2), []feedstore.LinkInterface{newest, middle}},
+		{"limit and offset", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_TIME).SetLimit(1).SetOffset(2), []feedstore.LinkInterface{oldest}},
+		{"offset beyond the end", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_TIME).SetOffset(5), []feedstore.LinkInterface{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			list, err := store.LinkList(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("LinkList should succeed, but got error: %v", err)
+			}
+			if !slices.Equal(ids(tc.expected), ids(list)) {
+				t.Errorf("Expected links in order %v, got %v", ids(tc.expected), ids(list))
+			}
+		})
+	}
+
+	count, err := store.LinkCount(ctx, feedstore.LinkQuery().SetLimit(1).SetOffset(1))
+	if err != nil {
+		t.Fatalf("LinkCount should succeed, but got error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("LinkCount should ignore limit and offset, expected 3, got %d", count)
+	}
+}
+
+func testLinkCreateMany(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	first := newLink("feed1", "First", "https://example.com/1").SetGUID("guid1")
+	second := newLink("feed1", "Second", "https://example.com/2").SetGUID("guid2")
+	if err := store.LinkCreateMany(ctx, []feedstore.LinkInterface{first, second}); err != nil {
+		t.Fatalf("LinkCreateMany should succeed, but got error: %v", err)
+	}
+	for _, link := range []feedstore.LinkInterface{first, second} {
+		if len(link.DataChanged()) != 0 {
+			t.Errorf("Link %s should not be dirty after LinkCreateMany, got changes %v", link.Title(), link.DataChanged())
+		}
+	}
+
+	if err := store.LinkCreateMany(ctx, nil); err != nil {
+		t.Errorf("LinkCreateMany without links should do nothing, but got error: %v", err)
+	}
+
+	testCases := []struct {
+		name  string
+		links []feedstore.LinkInterface
+		err   error
+	}{
+		{"nil link", []feedstore.LinkInterface{newLink("feed1", "Third", "https://example.com/3"), nil}, feedstore.ErrValidation},
+		{"existing ID", []feedstore.LinkInterface{newLink("feed1", "Third", "https://example.com/3"), newLink("feed1", "Same ID", "https://example.com/4").SetID(first.ID())}, feedstore.ErrDuplicate},
+		{"existing GUID", []feedstore.LinkInterface{newLink("feed1", "Third", "https://example.com/3"), newLink("feed1", "Same GUID", "https://example.com/4").SetGUID("guid2")}, feedstore.ErrDuplicate},
+		{"GUID repeated in the batch", []feedstore.LinkInterface{newLink("feed1", "Third", "https://example.com/3").SetGUID("guid3"), newLink("feed1", "Fourth", "https://example.com/4").SetGUID("guid3")}, feedstore.ErrDuplicate},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := store.LinkCreateMany(ctx, tc.links); !errors.Is(err, tc.err) {
+				t.Errorf("LinkCreateMany should return %v, but got: %v", tc.err, err)
+			}
+
+			list, err := store.LinkList(ctx, feedstore.LinkQuery())
+			if err != nil {
+				t.Fatalf("LinkList should succeed, but got error: %v", err)
+			}
+			if !sameIDs([]string{first.ID(), second.ID()}, ids(list)) {
+				t.Errorf("A failed LinkCreateMany should create no links, got %v", ids(list))
+			}
+		})
+	}
+}
+
+func testLinkRestore(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name    string
+		restore func(link feedstore.LinkInterface) error
+	}{
+		{"restore", func(link feedstore.LinkInterface) error {
+			return store.LinkRestore(ctx, link)
+		}},
+		{"restore by ID", func(link feedstore.LinkInterface) error {
+			return store.LinkRestoreByID(ctx, link.ID())
+		}},
+		{"restore by query", func(link feedstore.LinkInterface) error {
+			restored, err := store.LinkRestoreByQuery(ctx, feedstore.LinkQuery().SetID(link.ID()))
+			if err == nil && restored != 1 {
+				t.Errorf("LinkRestoreByQuery should restore 1 link, got %d", restored)
+			}
+			return err
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			link := newLink("feed1", tc.name, "https://example.com/1")
+			if err := store.LinkCreate(ctx, link); err != nil {
+				t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+			}
+			if err := store.LinkSoftDelete(ctx, link); err != nil {
+				t.Fatalf("LinkSoftDelete should succeed, but got error: %v", err)
+			}
+
+			if err := tc.restore(link); err != nil {
+				t.Fatalf("Restoring the link should succeed, but got error: %v", err)
+			}
+
+			found, err := store.LinkFindByID(ctx, link.ID())
+			if err != nil {
+				t.Fatalf("Restored link should be found, but got error: %v", err)
+			}
+			if !sameTime(sb.MAX_DATETIME, found.SoftDeletedAt()) {
+				t.Errorf("Restored link should not have a soft deleted time, got '%s'", found.SoftDeletedAt())
+			}
+		})
+	}
+
+	if err := store.LinkRestoreByID(ctx, "missing"); !errors.Is(err, feedstore.ErrNotFound) {
+		t.Errorf("LinkRestoreByID of a missing link should return ErrNotFound, but got: %v", err)
+	}
+	if err := store.LinkRestore(ctx, nil); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("LinkRestore with a nil link should return ErrValidation, but got: %v", err)
+	}
+
+	restored, err := store.LinkRestoreByQuery(ctx, feedstore.LinkQuery())
+	if err != nil {
+		t.Fatalf("LinkRestoreByQuery should succeed, but got error: %v", err)
+	}
+	if restored != 0 {
+		t.Errorf("LinkRestoreByQuery should only restore soft deleted links, got %d", restored)
+	}
+}
+
+func testLinkRestoreByQuery(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	// More than the default limit of a link list
+	const softDeletedCount = 1100
+
+	softDeletedAt := carbon.Now(carbon.UTC).SubHour().ToDateTimeString(carbon.UTC)
+
+	links := []feedstore.LinkInterface{}
+	for i := range softDeletedCount {
+		links = append(links, newLink("feed1", fmt.Sprintf("Link %d", i), fmt.Sprintf("https://example.com/%d", i)).SetSoftDeletedAt(softDeletedAt))
+	}
+	links = append(links, newLink("feed2", "Other feed", "https://example.com/other").SetSoftDeletedAt(softDeletedAt))
+
+	if err := store.LinkCreateMany(ctx, links); err != nil {
+		t.Fatalf("LinkCreateMany should succeed, but got error: %v", err)
+	}
+
+	query := feedstore.LinkQuery().SetFeedID("feed1").SetLimit(10)
+
+	restored, err := store.LinkRestoreByQuery(ctx, query)
+	if err != nil {
+		t.Fatalf("LinkRestoreByQuery should succeed, but got error: %v", err)
+	}
+	if restored != softDeletedCount {
+		t.Errorf("LinkRestoreByQuery should restore all %d matching links, ignoring the limit, got %d", softDeletedCount, restored)
+	}
+	if query.IsOnlySoftDeletedSet() {
+		t.Error("LinkRestoreByQuery should not change the query")
+	}
+
+	count, err := store.LinkCount(ctx, feedstore.LinkQuery().SetFeedID("feed1"))
+	if err != nil {
+		t.Fatalf("LinkCount should succeed, but got error: %v", err)
+	}
+	if count != softDeletedCount {
+		t.Errorf("All %d links of the feed should be restored, got %d", softDeletedCount, count)
+	}
+
+	softDeleted, err := store.LinkCount(ctx, feedstore.LinkQuery().SetOnlySoftDeleted(true))
+	if err != nil {
+		t.Fatalf("LinkCount should succeed, but got error: %v", err)
+	}
+	if softDeleted != 1 {
+		t.Errorf("The link of the other feed should stay soft deleted, got %d soft deleted links", softDeleted)
+	}
+}
+
+func testLinkListPage(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	times := []string{
+		"2020-01-01 00:00:00",
+		"2020-01-02 00:00:00",
+		"2020-01-02 00:00:00",
+		"2020-01-02 00:00:00",
+		"2020-01-03 00:00:00",
+		"2020-01-04 00:00:00",
+		"2020-01-04 00:00:00",
+	}
+
+	links := []feedstore.LinkInterface{}
+	for i, linkTime := range times {
+		links = append(links, newLink("feed1", fmt.Sprintf("Link %d", i), fmt.Sprintf("https://example.com/%d", i)).SetTime(linkTime))
+	}
+	if err := store.LinkCreateMany(ctx, links); err != nil {
+		t.Fatalf("LinkCreateMany should succeed, but got error: %v", err)
+	}
+
+	byID := func(a, b feedstore.LinkInterface) int {
+		return strings.Compare(a.ID(), b.ID())
+	}
+	byTime := func(a, b feedstore.LinkInterface) int {
+		if c := strings.Compare(a.Time(), b.Time()); c != 0 {
+			return c
+		}
+		return byID(a, b)
+	}
+	reversed := func(compare func(a, b feedstore.LinkInterface) int) func(a, b feedstore.LinkInterface) int {
+		return func(a, b feedstore.LinkInterface) int {
+			return compare(b, a)
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		query   func() feedstore.LinkQueryInterface
+		compare func(a, b feedstore.LinkInterface) int
+		pages   int
+	}{
+		{"ID descending by default", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetLimit(3) }, reversed(byID), 3},
+		{"ID ascending", func() feedstore.LinkQueryInterface {
+			return feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_ID).SetOrderDirection(sb.ASC).SetLimit(3)
+		}, byID, 3},
+		{"time ascending with ties", func() feedstore.LinkQueryInterface {
+			return feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_TIME).SetOrderDirection(sb.ASC).SetLimit(2)
+		}, byTime, 4},
+		{"time descending with ties", func() feedstore.LinkQueryInterface {
+			return feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_TIME).SetOrderDirection(sb.DESC).SetLimit(2)
+		}, reversed(byTime), 4},
+		{"page size of the link count", func() feedstore.LinkQueryInterface {
+			return feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_TIME).SetLimit(len(times))
+		}, reversed(byTime), 1},
+		{"single page by default", func() feedstore.LinkQueryInterface {
+			return feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_TIME)
+		}, reversed(byTime), 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expected := slices.Clone(links)
+			slices.SortFunc(expected, tc.compare)
+
+			actual := []feedstore.LinkInterface{}
+			cursor := ""
+			pages := 0
+
+			for {
+				query := tc.query()
+				if cursor != "" {
+					query.SetCursor(cursor)
+				}
+
+				page, next, err := store.LinkListPage(ctx, query)
+				if err != nil {
+					t.Fatalf("LinkListPage should succeed, but got error: %v", err)
+				}
+
+				actual = append(actual, page...)
+				pages++
+
+				if next == "" {
+					break
+				}
+				if pages > len(times) {
+					t.Fatal("LinkListPage should stop returning cursors after the last page")
+				}
+				cursor = next
+			}
+
+			if !slices.Equal(ids(expected), ids(actual)) {
+				t.Errorf("Expected links in order %v, got %v", ids(expected), ids(actual))
+			}
+			if pages != tc.pages {
+				t.Errorf("Expected %d pages, got %d", tc.pages, pages)
+			}
+		})
+	}
+
+	t.Run("links added between pages", func(t *testing.T) {
+		query := func() feedstore.LinkQueryInterface {
+			return feedstore.LinkQuery().SetFeedID("feed1").SetOrderBy(feedstore.COLUMN_TIME).SetLimit(3)
+		}
+
+		first, cursor, err := store.LinkListPage(ctx, query())
+		if err != nil {
+			t.Fatalf("LinkListPage should succeed, but got error: %v", err)
+		}
+
+		newer := newLink("feed1", "Newer", "https://example.com/newer").SetTime("2020-02-01 00:00:00")
+		if err := store.LinkCreate(ctx, newer); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+
+		second, _, err := store.LinkListPage(ctx, query().SetCursor(cursor))
+		if err != nil {
+			t.Fatalf("LinkListPage should succeed, but got error: %v", err)
+		}
+
+		expected := slices.Clone(links)
+		slices.SortFunc(expected, reversed(byTime))
+
+		if !slices.Equal(ids(expected[3:6]), ids(second)) {
+			t.Errorf("The next page should not shift when links are added, expected %v, got %v (first page %v)", ids(expected[3:6]), ids(second), ids(first))
+		}
+
+		list, err := store.LinkList(ctx, query().SetCursor(cursor))
+		if err != nil {
+			t.Fatalf("LinkList with a cursor should succeed, but got error: %v", err)
+		}
+		if !slices.Equal(ids(second), ids(list)) {
+			t.Errorf("LinkList with a cursor should list the same links as LinkListPage, expected %v, got %v", ids(second), ids(list))
+		}
+	})
+
+	_, cursor, err := store.LinkListPage(ctx, feedstore.LinkQuery().SetLimit(1))
+	if err != nil {
+		t.Fatalf("LinkListPage should succeed, but got error: %v", err)
+	}
+
+	invalid := []struct {
+		name  string
+		query feedstore.LinkQueryInterface
+	}{
+		{"malformed cursor", feedstore.LinkQuery().SetCursor("not a cursor")},
+		{"empty cursor", feedstore.LinkQuery().SetCursor("")},
+		{"cursor of another order", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_TIME).SetCursor(cursor)},
+		{"cursor and offset", feedstore.LinkQuery().SetCursor(cursor).SetOffset(1)},
+	}
+
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := store.LinkListPage(ctx, tc.query)
+			checkValidationError(t, "LinkListPage", "cursor", err)
+		})
+	}
+}
+
+func testLinkEngagement(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	popular := newLink("feed1", "Popular", "https://example.com/1").
+		SetViews("10").
+		SetVotesUp("25").
+		SetVotesDown("1").
+		SetCheckedAt("2020-03-01 00:00:00")
+	reported := newLink("feed1", "Reported", "https://example.com/2").
+		SetViewsInt64(9).
+		SetVotesUpInt64(2).
+		SetVotesDownInt64(12).
+		SetReport("spam").
+		SetReportCountInt64(1).
+		SetReportedAt("2020-02-01 00:00:00").
+		SetCheckedAt("2020-01-01 00:00:00")
+	unseen := newLink("feed1", "Unseen", "https://example.com/3")
+
+	for _, link := range []feedstore.LinkInterface{popular, reported, unseen} {
+		if err := store.LinkCreate(ctx, link); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	found, err := store.LinkFindByID(ctx, reported.ID())
+	if err != nil {
+		t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+	}
+
+	counts := []struct {
+		name     string
+		get      func() (int64, error)
+		expected int64
+	}{
+		{"views", found.ViewsInt64, 9},
+		{"votes up", found.VotesUpInt64, 2},
+		{"votes down", found.VotesDownInt64, 12},
+		{"report count", found.ReportCountInt64, 1},
+	}
+	for _, tc := range counts {
+		value, err := tc.get()
+		if err != nil {
+			t.Errorf("Reading %s should succeed, but got error: %v", tc.name, err)
+		}
+		if value != tc.expected {
+			t.Errorf("Expected %d %s, got %d", tc.expected, tc.name, value)
+		}
+	}
+	if found.Report() != "spam" {
+		t.Errorf("Expected report 'spam', got '%s'", found.Report())
+	}
+	if !sameTime("2020-02-01 00:00:00", found.ReportedAt()) {
+		t.Errorf("Expected reported at '2020-02-01 00:00:00', got '%s'", found.ReportedAt())
+	}
+	if !sameTime("2020-01-01 00:00:00", found.CheckedAt()) {
+		t.Errorf("Expected checked at '2020-01-01 00:00:00', got '%s'", found.CheckedAt())
+	}
+
+	created, err := store.LinkFindByID(ctx, unseen.ID())
+	if err != nil {
+		t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+	}
+	if views, err := created.ViewsInt64(); err != nil || views != 0 {
+		t.Errorf("A new link should have 0 views, got %d (error: %v)", views, err)
+	}
+
+	filters := []struct {
+		name     string
+		query    feedstore.LinkQueryInterface
+		expected []feedstore.LinkInterface
+	}{
+		{"views gte", feedstore.LinkQuery().SetViewsGte(9), []feedstore.LinkInterface{popular, reported}},
+		{"views lte", feedstore.LinkQuery().SetViewsLte(9), []feedstore.LinkInterface{reported, unseen}},
+		{"votes up gte", feedstore.LinkQuery().SetVotesUpGte(10), []feedstore.LinkInterface{popular}},
+		{"votes up lte", feedstore.LinkQuery().SetVotesUpLte(2), []feedstore.LinkInterface{reported, unseen}},
+		{"votes down gte", feedstore.LinkQuery().SetVotesDownGte(1), []feedstore.LinkInterface{popular, reported}},
+		{"votes down lte", feedstore.LinkQuery().SetVotesDownLte(1), []feedstore.LinkInterface{popular, unseen}},
+		{"reported at gte", feedstore.LinkQuery().SetReportedAtGte("2020-01-01 00:00:00"), []feedstore.LinkInterface{reported}},
+		{"reported at lte", feedstore.LinkQuery().SetReportedAtLte("2020-01-01 00:00:00"), []feedstore.LinkInterface{popular, unseen}},
+		{"checked at gte", feedstore.LinkQuery().SetCheckedAtGte("2020-02-01 00:00:00"), []feedstore.LinkInterface{popular}},
+		{"checked at lte", feedstore.LinkQuery().SetCheckedAtLte("2020-02-01 00:00:00"), []feedstore.LinkInterface{reported, unseen}},
+		{"combined", feedstore.LinkQuery().SetViewsGte(1).SetVotesDownGte(10), []feedstore.LinkInterface{reported}},
+	}
+
+	for _, tc := range filters {
+		t.Run(tc.name, func(t *testing.T) {
+			list, err := store.LinkList(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("LinkList should succeed, but got error: %v", err)
+			}
+			if !sameIDs(ids(tc.expected), ids(list)) {
+				t.Errorf("Expected links %v, got %v", ids(tc.expected), ids(list))
+			}
+
+			count, err := store.LinkCount(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("LinkCount should succeed, but got error: %v", err)
+			}
+			if count != int64(len(tc.expected)) {
+				t.Errorf("Expected count %d, got %d", len(tc.expected), count)
+			}
+		})
+	}
+
+	orders := []struct {
+		name     string
+		query    feedstore.LinkQueryInterface
+		expected []feedstore.LinkInterface
+	}{
+		{"views descending", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_VIEWS).SetOrderDirection(sb.DESC), []feedstore.LinkInterface{popular, reported, unseen}},
+		{"votes up ascending", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_VOTES_UP).SetOrderDirection(sb.ASC), []feedstore.LinkInterface{unseen, reported, popular}},
+		{"votes down descending", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_VOTES_DOWN).SetOrderDirection(sb.DESC), []feedstore.LinkInterface{reported, popular, unseen}},
+		{"reported at descending", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_REPORTED_AT).SetOrderDirection(sb.DESC).SetLimit(1), []feedstore.LinkInterface{reported}},
+		{"checked at ascending", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_CHECKED_AT).SetOrderDirection(sb.ASC), []feedstore.LinkInterface{unseen, reported, popular}},
+	}
+
+	for _, tc := range orders {
+		t.Run(tc.name, func(t *testing.T) {
+			list, err := store.LinkList(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("LinkList should succeed, but got error: %v", err)
+			}
+			if !slices.Equal(ids(tc.expected), ids(list)) {
+				t.Errorf("Expected links in order %v, got %v", ids(tc.expected), ids(list))
+			}
+		})
+	}
+}
+
+func testLinkCounters(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	link := newLink("feed1", "Link", "https://example.com/1")
+	deleted := newLink("feed1", "Deleted", "https://example.com/2").SetSoftDeletedAt("2020-01-01 00:00:00")
+	for _, l := range []feedstore.LinkInterface{link, deleted} {
+		if err := store.LinkCreate(ctx, l); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	steps := []struct {
+		name      string
+		run       func() (views int64, votesUp int64, votesDown int64, err error)
+		views     int64
+		votesUp   int64
+		votesDown int64
+	}{
+		{"increment views", func() (int64, int64, int64, error) {
+			views, err := store.LinkIncrementViews(ctx, link.ID(), 1)
+			return views, 0, 0, err
+		}, 1, 0, 0},
+		{"increment views by many", func() (int64, int64, int64, error) {
+			views, err := store.LinkIncrementViews(ctx, link.ID(), 5)
+			return views, 0, 0, err
+		}, 6, 0, 0},
+		{"increment views by zero", func() (int64, int64, int64, error) {
+			views, err := store.LinkIncrementViews(ctx, link.ID(), 0)
+			return views, 0, 0, err
+		}, 6, 0, 0},
+		{"vote up", func() (int64, int64, int64, error) {
+			votesUp, votesDown, err := store.LinkVote(ctx, link.ID(), true)
+			return 6, votesUp, votesDown, err
+		}, 6, 1, 0},
+		{"vote up again", func() (int64, int64, int64, error) {
+			votesUp, votesDown, err := store.LinkVote(ctx, link.ID(), true)
+			return 6, votesUp, votesDown, err
+		}, 6, 2, 0},
+		{"vote down", func() (int64, int64, int64, error) {
+			votesUp, votesDown, err := store.LinkVote(ctx, link.ID(), false)
+			return 6, votesUp, votesDown, err
+		}, 6, 2, 1},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			views, votesUp, votesDown, err := step.run()
+			if err != nil {
+				t.Fatalf("Counting should succeed, but got error: %v", err)
+			}
+			if views != step.views || votesUp != step.votesUp || votesDown != step.votesDown {
+				t.Errorf("Expected %d views, %d up and %d down votes, got %d, %d and %d",
+					step.views, step.votesUp, step.votesDown, views, votesUp, votesDown)
+			}
+
+			found, err := store.LinkFindByID(ctx, link.ID())
+			if err != nil {
+				t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+			}
+			storedViews, _ := found.ViewsInt64()
+			storedVotesUp, _ := found.VotesUpInt64()
+			storedVotesDown, _ := found.VotesDownInt64()
+			if storedViews != step.views || storedVotesUp != step.votesUp || storedVotesDown != step.votesDown {
+				t.Errorf("Expected %d views, %d up and %d down votes stored, got %d, %d and %d",
+					step.views, step.votesUp, step.votesDown, storedViews, storedVotesUp, storedVotesDown)
+			}
+			if !sameTime(link.UpdatedAt(), found.UpdatedAt()) {
+				t.Errorf("Counting should not change the updated at time, got '%s'", found.UpdatedAt())
+			}
+		})
+	}
+
+	errorCases := []struct {
+		name string
+		run  func() error
+		err  error
+	}{
+		{"views of a missing link", func() error {
+			_, err := store.LinkIncrementViews(ctx, "missing", 1)
+			return err
+		}, feedstore.ErrNotFound},
+		{"views of a soft deleted link", func() error {
+			_, err := store.LinkIncrementViews(ctx, deleted.ID(), 1)
+			return err
+		}, feedstore.ErrNotFound},
+		{"views with an empty ID", func() error {
+			_, err := store.LinkIncrementViews(ctx, "", 1)
+			return err
+		}, feedstore.ErrValidation},
+		{"views with a negative delta", func() error {
+			_, err := store.LinkIncrementViews(ctx, link.ID(), -1)
+			return err
+		}, feedstore.ErrValidation},
+		{"vote of a missing link", func() error {
+			_, _, err := store.LinkVote(ctx, "missing", true)
+			return err
+		}, feedstore.ErrNotFound},
+		{"vote of a soft deleted link", func() error {
+			_, _, err := store.LinkVote(ctx, deleted.ID(), false)
+			return err
+		}, feedstore.ErrNotFound},
+		{"vote with an empty ID", func() error {
+			_, _, err := store.LinkVote(ctx, "", true)
+			return err
+		}, feedstore.ErrValidation},
+	}
+
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.run(); !errors.Is(err, tc.err) {
+				t.Errorf("Expected %v, but got: %v", tc.err, err)
+			}
+		})
+	}
+
+	t.Run("concurrent", func(t *testing.T) {
+		concurrent := newLink("feed1", "Concurrent", "https://example.com/3")
+		if err := store.LinkCreate(ctx, concurrent); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+
+		var wg sync.WaitGroup
+		for i := range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := store.LinkIncrementViews(ctx, concurrent.ID(), 1); err != nil {
+					t.Errorf("LinkIncrementViews should succeed, but got error: %v", err)
+				}
+				if _, _, err := store.LinkVote(ctx, concurrent.ID(), i%2 == 0); err != nil {
+					t.Errorf("LinkVote should succeed, but got error: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		found, err := store.LinkFindByID(ctx, concurrent.ID())
+		if err != nil {
+			t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+		}
+		views, _ := found.ViewsInt64()
+		votesUp, _ := found.VotesUpInt64()
+		votesDown, _ := found.VotesDownInt64()
+		if views != 20 || votesUp != 10 || votesDown != 10 {
+			t.Errorf("Expected 20 views, 10 up and 10 down votes, got %d, %d and %d", views, votesUp, votesDown)
+		}
+	})
+}
+
+func testLinkRanking(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+	hoursAgo := func(hours int) string {
+		return carbon.Now(carbon.UTC).SubHours(hours).ToDateTimeString(carbon.UTC)
+	}
+
+	// Scores with the default ranking, at an age offset of 2 hours:
+	// undated 5/2^1.8, fresh 3/3^1.8, recent 10/7^1.8, old 100/50^1.8,
+	// buried -4/3^1.8
+	undated := newLink("feed1", "Undated", "https://example.com/1").SetVotesUp("5").SetViews("30")
+	fresh := newLink("feed1", "Fresh", "https://example.com/2").SetTime(hoursAgo(1)).SetVotesUp("3").SetViews("10")
+	recent := newLink("feed1", "Recent", "https://example.com/3").SetTime(hoursAgo(5)).SetVotesUp("10").SetViews("40")
+	old := newLink("feed2", "Old", "https://example.com/4").SetTime(hoursAgo(48)).SetVotesUp("100").SetViews("20")
+	buried := newLink("feed1", "Buried", "https://example.com/5").SetTime(hoursAgo(1)).SetVotesUp("1").SetVotesDown("5").SetViews("50")
+
+	for _, link := range []feedstore.LinkInterface{undated, fresh, recent, old, buried} {
+		if err := store.LinkCreate(ctx, link); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	points := feedstore.DefaultLinkRanking()
+	points.Gravity = 0
+
+	byViews := feedstore.LinkRanking{ViewsWeight: 1, AgeOffsetHours: 1}
+
+	weighted := feedstore.DefaultLinkRanking()
+	weighted.ViewsWeight = 0.5
+
+	testCases := []struct {
+		name     string
+		query    feedstore.LinkQueryInterface
+		expected []feedstore.LinkInterface
+	}{
+		{"default ranking", feedstore.LinkQuery().SetRanking(feedstore.DefaultLinkRanking()), []feedstore.LinkInterface{undated, fresh, recent, old, buried}},
+		{"without gravity", feedstore.LinkQuery().SetRanking(points), []feedstore.LinkInterface{old, recent, undated, fresh, buried}},
+		{"by views", feedstore.LinkQuery().SetRanking(byViews), []feedstore.LinkInterface{buried, recent, undated, old, fresh}},
+		{"with views weight", feedstore.LinkQuery().SetRanking(weighted), []feedstore.LinkInterface{undated, buried, fresh, recent, old}},
+		{"limit and offset", feedstore.LinkQuery().SetRanking(feedstore.DefaultLinkRanking()).SetLimit(2).SetOffset(1), []feedstore.LinkInterface{fresh, recent}},
+		{"filtered", feedstore.LinkQuery().SetRanking(points).SetFeedID("feed1").SetVotesUpGte(2), []feedstore.LinkInterface{recent, undated, fresh}},
+		{"measured later", feedstore.LinkQuery().SetRanking(feedstore.LinkRanking{
+			VotesUpWeight:  1,
+			Gravity:        1.8,
+			AgeOffsetHours: 2,
+			Now:            carbon.Now(carbon.UTC).AddDays(30).ToDateTimeString(carbon.UTC),
+		}), []feedstore.LinkInterface{old, recent, undated, fresh, buried}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			list, err := store.LinkList(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("LinkList should succeed, but got error: %v", err)
+			}
+			if !slices.Equal(ids(tc.expected), ids(list)) {
+				t.Errorf("Expected links in order %v, got %v", titles(tc.expected), titles(list))
+			}
+		})
+	}
+
+	count, err := store.LinkCount(ctx, feedstore.LinkQuery().SetRanking(feedstore.DefaultLinkRanking()).SetLimit(1))
+	if err != nil {
+		t.Fatalf("LinkCount with a ranking should succeed, but got error: %v", err)
+	}
+	if count != 5 {
+		t.Errorf("Expected 5 links, got %d", count)
+	}
+
+	negativeGravity := feedstore.DefaultLinkRanking()
+	negativeGravity.Gravity = -1
+	noOffset := feedstore.DefaultLinkRanking()
+	noOffset.AgeOffsetHours = 0
+	invalidNow := feedstore.DefaultLinkRanking()
+	invalidNow.Now = "not a datetime"
+
+	_, cursor, err := store.LinkListPage(ctx, feedstore.LinkQuery().SetLimit(1))
+	if err != nil {
+		t.Fatalf("LinkListPage should succeed, but got error: %v", err)
+	}
+
+	invalid := []struct {
+		name  string
+		query feedstore.LinkQueryInterface
+	}{
+		{"negative gravity", feedstore.LinkQuery().SetRanking(negativeGravity)},
+		{"no age offset", feedstore.LinkQuery().SetRanking(noOffset)},
+		{"invalid now", feedstore.LinkQuery().SetRanking(invalidNow)},
+		{"with order by", feedstore.LinkQuery().SetRanking(feedstore.DefaultLinkRanking()).SetOrderBy(feedstore.COLUMN_TIME)},
+		{"with cursor", feedstore.LinkQuery().SetRanking(feedstore.DefaultLinkRanking()).SetCursor(cursor)},
+	}
+
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := store.LinkList(ctx, tc.query)
+			checkValidationError(t, "LinkList", "ranking", err)
+		})
+	}
+
+	_, _, err = store.LinkListPage(ctx, feedstore.LinkQuery().SetRanking(feedstore.DefaultLinkRanking()))
+	checkValidationError(t, "LinkListPage", "ranking", err)
+}
+
+// Helper function to return the titles of links, for readable failures
+func titles(list []feedstore.LinkInterface) []string {
+	result := make([]string, 0, len(list))
+
+	for _, link := range list {
+		result = append(result, link.Title())
+	}
+
+	return result
+}
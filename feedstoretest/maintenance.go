@@ -0,0 +1,214 @@
+package feedstoretest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dracory/feedstore"
+	"github.com/dromara/carbon/v2"
+)
+
+func testPurgeSoftDeleted(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+	recently := carbon.Now(carbon.UTC).SubHours(1).ToDateTimeString(carbon.UTC)
+
+	oldFeed := newFeed("Old", "https://example.com/old.xml").SetSoftDeletedAt("2020-01-01 00:00:00")
+	recentFeed := newFeed("Recent", "https://example.com/recent.xml").SetSoftDeletedAt(recently)
+	activeFeed := newFeed("Active", "https://example.com/active.xml")
+	oldLink := newLink("feed1", "Old", "https://example.com/old").SetSoftDeletedAt("2020-01-01 00:00:00")
+	recentLink := newLink("feed1", "Recent", "https://example.com/recent").SetSoftDeletedAt(recently)
+	activeLink := newLink("feed1", "Active", "https://example.com/active")
+
+	if err := store.FeedCreateMany(ctx, []feedstore.FeedInterface{oldFeed, recentFeed, activeFeed}); err != nil {
+		t.Fatalf("FeedCreateMany should succeed, but got error: %v", err)
+	}
+	if err := store.LinkCreateMany(ctx, []feedstore.LinkInterface{oldLink, recentLink, activeLink}); err != nil {
+		t.Fatalf("LinkCreateMany should succeed, but got error: %v", err)
+	}
+
+	testCases := []struct {
+		name      string
+		olderThan time.Duration
+		expected  feedstore.PurgeResult
+		feeds     []feedstore.FeedInterface
+		links     []feedstore.LinkInterface
+	}{
+		{"none old enough", 100 * 365 * 24 * time.Hour, feedstore.PurgeResult{}, []feedstore.FeedInterface{oldFeed, recentFeed, activeFeed}, []feedstore.LinkInterface{oldLink, recentLink, activeLink}},
+		{"older than a day", 24 * time.Hour, feedstore.PurgeResult{Feeds: 1, Links: 1}, []feedstore.FeedInterface{recentFeed, activeFeed}, []feedstore.LinkInterface{recentLink, activeLink}},
+		{"older than a minute", time.Minute, feedstore.PurgeResult{Feeds: 1, Links: 1}, []feedstore.FeedInterface{activeFeed}, []feedstore.LinkInterface{activeLink}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := store.PurgeSoftDeleted(ctx, tc.olderThan)
+			if err != nil {
+				t.Fatalf("PurgeSoftDeleted should succeed, but got error: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("Expected %+v to be purged, got %+v", tc.expected, result)
+			}
+
+			feeds, err := store.FeedList(ctx, feedstore.FeedQuery().SetWithSoftDeleted(true))
+			if err != nil {
+				t.Fatalf("FeedList should succeed, but got error: %v", err)
+			}
+			if !sameIDs(ids(tc.feeds), ids(feeds)) {
+				t.Errorf("Expected feeds %v to remain, got %v", ids(tc.feeds), ids(feeds))
+			}
+
+			links, err := store.LinkList(ctx, feedstore.LinkQuery().SetWithSoftDeleted(true))
+			if err != nil {
+				t.Fatalf("LinkList should succeed, but got error: %v", err)
+			}
+			if !sameIDs(ids(tc.links), ids(links)) {
+				t.Errorf("Expected links %v to remain, got %v", ids(tc.links), ids(links))
+			}
+		})
+	}
+
+	if _, err := store.PurgeSoftDeleted(ctx, -time.Hour); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("PurgeSoftDeleted with a negative duration should return ErrValidation, but got: %v", err)
+	}
+}
+
+func testFeedEnforceRetention(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+	day := 24 * 60 * 60
+	daysAgo := func(days int) string {
+		return carbon.Now(carbon.UTC).SubDays(days).ToDateTimeString(carbon.UTC)
+	}
+
+	testCases := []struct {
+		name       string
+		maxItems   int
+		maxAge     int
+		hardDelete bool
+		times      []string
+		kept       []int
+	}{
+		{"no retention", 0, 0, false, []string{daysAgo(3), daysAgo(2), daysAgo(1)}, []int{0, 1, 2}},
+		{"max items", 2, 0, false, []string{daysAgo(3), daysAgo(1), daysAgo(2)}, []int{1, 2}},
+		{"max items above the link count", 5, 0, false, []string{daysAgo(3), daysAgo(2)}, []int{0, 1}},
+		{"max age", 0, 2*day + 60, false, []string{daysAgo(3), daysAgo(2), daysAgo(1)}, []int{1, 2}},
+		{"max age of links without time", 0, day, false, []string{"", daysAgo(2)}, []int{0}},
+		{"max items and max age", 2, 4 * day, false, []string{daysAgo(5), daysAgo(3), daysAgo(2), daysAgo(1)}, []int{2, 3}},
+		{"hard delete", 1, 0, true, []string{daysAgo(2), daysAgo(1)}, []int{1}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			feed := newFeed(tc.name, fmt.Sprintf("https://example.com/%d.xml", i)).
+				SetRetentionMaxItems(fmt.Sprint(tc.maxItems)).
+				SetRetentionMaxAge(fmt.Sprint(tc.maxAge))
+			if err := store.FeedCreate(ctx, feed); err != nil {
+				t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+			}
+
+			links := []feedstore.LinkInterface{}
+			for j, linkTime := range tc.times {
+				link := newLink(feed.ID(), fmt.Sprintf("Link %d", j), fmt.Sprintf("https://example.com/%d/%d", i, j))
+				if linkTime != "" {
+					link.SetTime(linkTime)
+				}
+				links = append(links, link)
+			}
+			if err := store.LinkCreateMany(ctx, links); err != nil {
+				t.Fatalf("LinkCreateMany should succeed, but got error: %v", err)
+			}
+
+			removed, err := store.FeedEnforceRetention(ctx, feed, feedstore.RetentionOptions{HardDelete: tc.hardDelete})
+			if err != nil {
+				t.Fatalf("FeedEnforceRetention should succeed, but got error: %v", err)
+			}
+			if removed != int64(len(tc.times)-len(tc.kept)) {
+				t.Errorf("Expected %d links removed, got %d", len(tc.times)-len(tc.kept), removed)
+			}
+
+			expected := []string{}
+			for _, index := range tc.kept {
+				expected = append(expected, links[index].ID())
+			}
+
+			list, err := store.LinkList(ctx, feedstore.LinkQuery().SetFeedID(feed.ID()))
+			if err != nil {
+				t.Fatalf("LinkList should succeed, but got error: %v", err)
+			}
+			if !sameIDs(expected, ids(list)) {
+				t.Errorf("Expected links %v to be kept, got %v", expected, ids(list))
+			}
+
+			all, err := store.LinkCount(ctx, feedstore.LinkQuery().SetFeedID(feed.ID()).SetWithSoftDeleted(true))
+			if err != nil {
+				t.Fatalf("LinkCount should succeed, but got error: %v", err)
+			}
+			if tc.hardDelete && all != int64(len(tc.kept)) {
+				t.Errorf("Hard deleted links should be gone, expected %d links, got %d", len(tc.kept), all)
+			}
+			if !tc.hardDelete && all != int64(len(tc.times)) {
+				t.Errorf("Soft deleted links should remain, expected %d links, got %d", len(tc.times), all)
+			}
+		})
+	}
+
+	if _, err := store.FeedEnforceRetention(ctx, nil, feedstore.RetentionOptions{}); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("FeedEnforceRetention with a nil feed should return ErrValidation, but got: %v", err)
+	}
+}
+
+func testEnforceRetention(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	limited := newFeed("Limited", "https://example.com/limited.xml").SetRetentionMaxItems("1")
+	unlimited := newFeed("Unlimited", "https://example.com/unlimited.xml")
+	if err := store.FeedCreateMany(ctx, []feedstore.FeedInterface{limited, unlimited}); err != nil {
+		t.Fatalf("FeedCreateMany should succeed, but got error: %v", err)
+	}
+
+	links := []feedstore.LinkInterface{}
+	for _, feed := range []feedstore.FeedInterface{limited, unlimited} {
+		for day := 1; day <= 3; day++ {
+			links = append(links, newLink(feed.ID(), fmt.Sprintf("%s %d", feed.Name(), day), fmt.Sprintf("https://example.com/%s/%d", feed.ID(), day)).
+				SetTime(carbon.Now(carbon.UTC).SubDays(day).ToDateTimeString(carbon.UTC)))
+		}
+	}
+	if err := store.LinkCreateMany(ctx, links); err != nil {
+		t.Fatalf("LinkCreateMany should succeed, but got error: %v", err)
+	}
+
+	removed, err := store.EnforceRetention(ctx, feedstore.RetentionOptions{})
+	if err != nil {
+		t.Fatalf("EnforceRetention should succeed, but got error: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Expected 2 links removed, got %d", removed)
+	}
+
+	testCases := []struct {
+		feed     feedstore.FeedInterface
+		expected []string
+	}{
+		{limited, []string{links[0].ID()}},
+		{unlimited, []string{links[3].ID(), links[4].ID(), links[5].ID()}},
+	}
+
+	for _, tc := range testCases {
+		list, err := store.LinkList(ctx, feedstore.LinkQuery().SetFeedID(tc.feed.ID()))
+		if err != nil {
+			t.Fatalf("LinkList should succeed, but got error: %v", err)
+		}
+		if !sameIDs(tc.expected, ids(list)) {
+			t.Errorf("Expected links %v of feed %s to be kept, got %v", tc.expected, tc.feed.Name(), ids(list))
+		}
+	}
+
+	removed, err = store.EnforceRetention(ctx, feedstore.RetentionOptions{})
+	if err != nil {
+		t.Fatalf("EnforceRetention should succeed, but got error: %v", err)
+	}
+	if removed != 0 {
+		t.Errorf("EnforceRetention should remove nothing when enforced already, got %d", removed)
+	}
+}
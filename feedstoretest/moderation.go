@@ -0,0 +1,360 @@
+package feedstoretest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/dracory/feedstore"
+	"github.com/dracory/sb"
+)
+
+// Helper function to return the reasons of the reports of a link
+func reasons(link feedstore.LinkInterface) []string {
+	result := []string{}
+
+	for _, report := range feedstore.LinkReports(link) {
+		result = append(result, report.Reason)
+	}
+
+	return result
+}
+
+func testLinkReport(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	link := newLink("feed1", "Link", "https://example.com/1")
+	legacy := newLink("feed1", "Legacy", "https://example.com/2").
+		SetReport("broken link").
+		SetReportCount("1").
+		SetReportedAt("2020-01-01 00:00:00")
+	deleted := newLink("feed1", "Deleted", "https://example.com/3").SetSoftDeletedAt("2020-01-01 00:00:00")
+
+	for _, l := range []feedstore.LinkInterface{link, legacy, deleted} {
+		if err := store.LinkCreate(ctx, l); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	if count, _ := link.ReportCountInt64(); count != 0 {
+		t.Errorf("Expected a new link to have no reports, got %d", count)
+	}
+
+	steps := []struct {
+		name    string
+		link    feedstore.LinkInterface
+		reason  string
+		count   int64
+		reasons []string
+	}{
+		{"first report", link, "spam", 1, []string{"spam"}},
+		{"second report", link, " offensive ", 2, []string{"spam", "offensive"}},
+		{"report of a plain text report", legacy, "spam", 2, []string{"broken link", "spam"}},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			count, err := store.LinkReport(ctx, step.link.ID(), step.reason)
+			if err != nil {
+				t.Fatalf("LinkReport should succeed, but got error: %v", err)
+			}
+			if count != step.count {
+				t.Errorf("Expected %d reports, got %d", step.count, count)
+			}
+
+			found, err := store.LinkFindByID(ctx, step.link.ID())
+			if err != nil {
+				t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+			}
+			if storedCount, _ := found.ReportCountInt64(); storedCount != step.count {
+				t.Errorf("Expected %d reports stored, got %d", step.count, storedCount)
+			}
+			if !slices.Equal(step.reasons, reasons(found)) {
+				t.Errorf("Expected the reasons %v, got %v", step.reasons, reasons(found))
+			}
+			if sameTime(sb.NULL_DATETIME, found.ReportedAt()) {
+				t.Error("Expected the reported at time to be set")
+			}
+			if found.Status() != feedstore.LINK_STATUS_ACTIVE {
+				t.Errorf("Expected the link to stay active without an auto hide threshold, got '%s'", found.Status())
+			}
+			if !sameTime(step.link.UpdatedAt(), found.UpdatedAt()) {
+				t.Errorf("Reporting should not change the updated at time, got '%s'", found.UpdatedAt())
+			}
+		})
+	}
+
+	errorCases := []struct {
+		name   string
+		id     string
+		reason string
+		err    error
+	}{
+		{"missing link", "missing", "spam", feedstore.ErrNotFound},
+		{"soft deleted link", deleted.ID(), "spam", feedstore.ErrNotFound},
+		{"empty ID", "", "spam", feedstore.ErrValidation},
+		{"empty reason", link.ID(), " ", feedstore.ErrValidation},
+	}
+
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := store.LinkReport(ctx, tc.id, tc.reason); !errors.Is(err, tc.err) {
+				t.Errorf("Expected %v, but got: %v", tc.err, err)
+			}
+		})
+	}
+
+	t.Run("concurrent", func(t *testing.T) {
+		concurrent := newLink("feed1", "Concurrent", "https://example.com/4")
+		if err := store.LinkCreate(ctx, concurrent); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := store.LinkReport(ctx, concurrent.ID(), "spam"); err != nil {
+					t.Errorf("LinkReport should succeed, but got error: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		found, err := store.LinkFindByID(ctx, concurrent.ID())
+		if err != nil {
+			t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+		}
+		if count, _ := found.ReportCountInt64(); count != 20 || len(feedstore.LinkReports(found)) != 20 {
+			t.Errorf("Expected 20 reports, got %d with %d reasons", count, len(feedstore.LinkReports(found)))
+		}
+	})
+}
+
+func testLinkReportedList(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	reported := func(title string, url string, count string, reportedAt string) feedstore.LinkInterface {
+		return newLink("feed1", title, url).SetReportCount(count).SetReportedAt(reportedAt)
+	}
+
+	most := reported("Most", "https://example.com/1", "5", "2020-01-01 00:00:00")
+	recent := reported("Recent", "https://example.com/2", "2", "2020-03-01 00:00:00")
+	older := reported("Older", "https://example.com/3", "2", "2020-02-01 00:00:00")
+	hidden := reported("Hidden", "https://example.com/4", "1", "2020-01-01 00:00:00").SetStatus(feedstore.LINK_STATUS_HIDDEN)
+	other := reported("Other", "https://example.com/5", "3", "2020-01-01 00:00:00").SetFeedID("feed2")
+	deleted := reported("Deleted", "https://example.com/6", "9", "2020-01-01 00:00:00").SetSoftDeletedAt("2020-01-01 00:00:00")
+	clean := newLink("feed1", "Clean", "https://example.com/7")
+
+	for _, link := range []feedstore.LinkInterface{most, recent, older, hidden, other, deleted, clean} {
+		if err := store.LinkCreate(ctx, link); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		query    feedstore.LinkQueryInterface
+		expected []feedstore.LinkInterface
+	}{
+		{"nil query", nil, []feedstore.LinkInterface{most, other, recent, older, hidden}},
+		{"by feed", feedstore.LinkQuery().SetFeedID("feed1"), []feedstore.LinkInterface{most, recent, older, hidden}},
+		{"by status", feedstore.LinkQuery().SetStatus(feedstore.LINK_STATUS_HIDDEN), []feedstore.LinkInterface{hidden}},
+		{"report count gte", feedstore.LinkQuery().SetReportCountGte(3), []feedstore.LinkInterface{most, other}},
+		{"report count lte", feedstore.LinkQuery().SetReportCountLte(2), []feedstore.LinkInterface{recent, older, hidden}},
+		{"limit and offset", feedstore.LinkQuery().SetLimit(2).SetOffset(1), []feedstore.LinkInterface{other, recent}},
+		{"with soft deleted", feedstore.LinkQuery().SetWithSoftDeleted(true), []feedstore.LinkInterface{deleted, most, other, recent, older, hidden}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			list, err := store.LinkReportedList(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("LinkReportedList should succeed, but got error: %v", err)
+			}
+			if !slices.Equal(ids(tc.expected), ids(list)) {
+				t.Errorf("Expected %v, got %v", titles(tc.expected), titles(list))
+			}
+		})
+	}
+
+	t.Run("report count filters of LinkList", func(t *testing.T) {
+		list, err := store.LinkList(ctx, feedstore.LinkQuery().SetReportCountGte(2).SetReportCountLte(3))
+		if err != nil {
+			t.Fatalf("LinkList should succeed, but got error: %v", err)
+		}
+		if !sameIDs(ids([]feedstore.LinkInterface{recent, older, other}), ids(list)) {
+			t.Errorf("Expected %v, got %v", titles([]feedstore.LinkInterface{recent, older, other}), titles(list))
+		}
+	})
+
+	cursor := feedstore.NewLinkCursor(most, feedstore.COLUMN_ID).Encode()
+
+	invalidQueries := []struct {
+		name  string
+		query feedstore.LinkQueryInterface
+	}{
+		{"order by", feedstore.LinkQuery().SetOrderBy(feedstore.COLUMN_TITLE)},
+		{"ranking", feedstore.LinkQuery().SetRanking(feedstore.DefaultLinkRanking())},
+		{"cursor", feedstore.LinkQuery().SetCursor(cursor)},
+		{"invalid filter", feedstore.LinkQuery().SetStatus("")},
+	}
+
+	for _, tc := range invalidQueries {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := store.LinkReportedList(ctx, tc.query); !errors.Is(err, feedstore.ErrValidation) {
+				t.Errorf("Expected a validation error, but got: %v", err)
+			}
+		})
+	}
+}
+
+func testLinkReportResolve(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	report := func(link feedstore.LinkInterface) {
+		t.Helper()
+		if err := store.LinkCreate(ctx, link); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+		for _, reason := range []string{"spam", "offensive"} {
+			if _, err := store.LinkReport(ctx, link.ID(), reason); err != nil {
+				t.Fatalf("LinkReport should succeed, but got error: %v", err)
+			}
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		link          feedstore.LinkInterface
+		resolution    string
+		status        string
+		reportCount   int64
+		softDeleted   bool
+		stillReported bool
+	}{
+		{"dismiss", newLink("feed1", "Dismissed", "https://example.com/1"), feedstore.MODERATION_DISMISS, feedstore.LINK_STATUS_ACTIVE, 0, false, false},
+		{"dismiss a hidden link", newLink("feed1", "Shown", "https://example.com/2").SetStatus(feedstore.LINK_STATUS_HIDDEN), feedstore.MODERATION_DISMISS, feedstore.LINK_STATUS_ACTIVE, 0, false, false},
+		{"dismiss an inactive link", newLink("feed1", "Inactive", "https://example.com/3").SetStatus(feedstore.LINK_STATUS_INACTIVE), feedstore.MODERATION_DISMISS, feedstore.LINK_STATUS_INACTIVE, 0, false, false},
+		{"hide", newLink("feed1", "Hidden", "https://example.com/4"), feedstore.MODERATION_HIDE, feedstore.LINK_STATUS_HIDDEN, 0, false, false},
+		{"soft delete", newLink("feed1", "Deleted", "https://example.com/5"), feedstore.MODERATION_SOFT_DELETE, feedstore.LINK_STATUS_ACTIVE, 2, true, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report(tc.link)
+
+			if err := store.LinkReportResolve(ctx, tc.link.ID(), tc.resolution); err != nil {
+				t.Fatalf("LinkReportResolve should succeed, but got error: %v", err)
+			}
+
+			if _, err := store.LinkFindByID(ctx, tc.link.ID()); tc.softDeleted && !errors.Is(err, feedstore.ErrNotFound) {
+				t.Errorf("Expected the link to be soft deleted, but got: %v", err)
+			}
+
+			found, err := store.LinkFindByID(ctx, tc.link.ID(), feedstore.FindByIDOptions{WithSoftDeleted: true})
+			if err != nil {
+				t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+			}
+			if found.Status() != tc.status {
+				t.Errorf("Expected the status '%s', got '%s'", tc.status, found.Status())
+			}
+			if count, _ := found.ReportCountInt64(); count != tc.reportCount || int64(len(feedstore.LinkReports(found))) != tc.reportCount {
+				t.Errorf("Expected %d reports, got %d with the reasons %v", tc.reportCount, count, reasons(found))
+			}
+			if tc.reportCount == 0 && !sameTime(sb.NULL_DATETIME, found.ReportedAt()) {
+				t.Errorf("Expected the reported at time to be cleared, got '%s'", found.ReportedAt())
+			}
+
+			list, err := store.LinkReportedList(ctx, nil)
+			if err != nil {
+				t.Fatalf("LinkReportedList should succeed, but got error: %v", err)
+			}
+			if slices.Contains(ids(list), tc.link.ID()) != tc.stillReported {
+				t.Errorf("Expected the link to be listed as reported: %v", tc.stillReported)
+			}
+		})
+	}
+
+	link := newLink("feed1", "Link", "https://example.com/6")
+	report(link)
+
+	errorCases := []struct {
+		name       string
+		id         string
+		resolution string
+		err        error
+	}{
+		{"missing link", "missing", feedstore.MODERATION_DISMISS, feedstore.ErrNotFound},
+		{"empty ID", "", feedstore.MODERATION_HIDE, feedstore.ErrValidation},
+		{"unknown resolution", link.ID(), "ignore", feedstore.ErrValidation},
+		{"empty resolution", link.ID(), "", feedstore.ErrValidation},
+	}
+
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := store.LinkReportResolve(ctx, tc.id, tc.resolution); !errors.Is(err, tc.err) {
+				t.Errorf("Expected %v, but got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+// AutoHideStoreFactory returns a new, empty store with its schema migrated,
+// which hides links at the threshold, its ModerationAutoHideThreshold
+type AutoHideStoreFactory func(t *testing.T, threshold int64) feedstore.StoreInterface
+
+// RunAutoHideTests checks that links are hidden when they reach the auto
+// hide threshold of stores created by the factory
+func RunAutoHideTests(t *testing.T, factory AutoHideStoreFactory) {
+	ctx := context.Background()
+
+	store := factory(t, 3)
+
+	if store == nil {
+		t.Fatal("AutoHideStoreFactory returned a nil store")
+	}
+
+	link := newLink("feed1", "Link", "https://example.com/1")
+	if err := store.LinkCreate(ctx, link); err != nil {
+		t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+	}
+
+	for i, status := range []string{
+		feedstore.LINK_STATUS_ACTIVE,
+		feedstore.LINK_STATUS_ACTIVE,
+		feedstore.LINK_STATUS_HIDDEN,
+	} {
+		if _, err := store.LinkReport(ctx, link.ID(), "spam"); err != nil {
+			t.Fatalf("LinkReport should succeed, but got error: %v", err)
+		}
+
+		found, err := store.LinkFindByID(ctx, link.ID())
+		if err != nil {
+			t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+		}
+		if found.Status() != status {
+			t.Errorf("Expected the status '%s' after %d reports, got '%s'", status, i+1, found.Status())
+		}
+	}
+
+	if err := store.LinkReportResolve(ctx, link.ID(), feedstore.MODERATION_DISMISS); err != nil {
+		t.Fatalf("LinkReportResolve should succeed, but got error: %v", err)
+	}
+
+	count, err := store.LinkReport(ctx, link.ID(), "spam")
+	if err != nil {
+		t.Fatalf("LinkReport should succeed, but got error: %v", err)
+	}
+
+	found, err := store.LinkFindByID(ctx, link.ID())
+	if err != nil {
+		t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+	}
+	if count != 1 || found.Status() != feedstore.LINK_STATUS_ACTIVE {
+		t.Errorf("Expected a dismissed link to count its reports again, got %d reports and the status '%s'", count, found.Status())
+	}
+}
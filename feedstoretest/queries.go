@@ -0,0 +1,97 @@
+package feedstoretest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dracory/feedstore"
+)
+
+func testFeedQueryValidation(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		field string
+		query func() feedstore.FeedQueryInterface
+	}{
+		{"category", func() feedstore.FeedQueryInterface { return feedstore.FeedQuery().SetCategory("") }},
+		{"created_at_gte", func() feedstore.FeedQueryInterface { return feedstore.FeedQuery().SetCreatedAtGte("") }},
+		{"created_at_lte", func() feedstore.FeedQueryInterface { return feedstore.FeedQuery().SetCreatedAtLte("") }},
+		{"id", func() feedstore.FeedQueryInterface { return feedstore.FeedQuery().SetID("") }},
+		{"id_in", func() feedstore.FeedQueryInterface { return feedstore.FeedQuery().SetIDIn([]string{}) }},
+		{"last_fetched_at_gte", func() feedstore.FeedQueryInterface { return feedstore.FeedQuery().SetLastFetchedAtGte("") }},
+		{"last_fetched_at_lte", func() feedstore.FeedQueryInterface { return feedstore.FeedQuery().SetLastFetchedAtLte("") }},
+		{"limit", func() feedstore.FeedQueryInterface { return feedstore.FeedQuery().SetLimit(-1) }},
+		{"next_fetch_at_gte", func() feedstore.FeedQueryInterface { return feedstore.FeedQuery().SetNextFetchAtGte("") }},
+		{"next_fetch_at_lte", func() feedstore.FeedQueryInterface { return feedstore.FeedQuery().SetNextFetchAtLte("") }},
+		{"offset", func() feedstore.FeedQueryInterface { return feedstore.FeedQuery().SetOffset(-1) }},
+		{"status", func() feedstore.FeedQueryInterface { return feedstore.FeedQuery().SetStatus("") }},
+		{"status_in", func() feedstore.FeedQueryInterface { return feedstore.FeedQuery().SetStatusIn([]string{}) }},
+		{"url", func() feedstore.FeedQueryInterface { return feedstore.FeedQuery().SetURL("") }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.field, func(t *testing.T) {
+			_, err := store.FeedList(ctx, tc.query())
+			checkValidationError(t, "FeedList", tc.field, err)
+
+			_, err = store.FeedCount(ctx, tc.query())
+			checkValidationError(t, "FeedCount", tc.field, err)
+
+			_, err = store.FeedRestoreByQuery(ctx, tc.query())
+			checkValidationError(t, "FeedRestoreByQuery", tc.field, err)
+		})
+	}
+}
+
+func testLinkQueryValidation(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		field string
+		query func() feedstore.LinkQueryInterface
+	}{
+		{"checked_at_gte", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetCheckedAtGte("") }},
+		{"checked_at_lte", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetCheckedAtLte("") }},
+		{"created_at_gte", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetCreatedAtGte("") }},
+		{"created_at_lte", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetCreatedAtLte("") }},
+		{"guid", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetGUID("") }},
+		{"id", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetID("") }},
+		{"id_in", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetIDIn([]string{}) }},
+		{"limit", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetLimit(-1) }},
+		{"offset", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetOffset(-1) }},
+		{"reported_at_gte", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetReportedAtGte("") }},
+		{"reported_at_lte", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetReportedAtLte("") }},
+		{"status", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetStatus("") }},
+		{"status_in", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetStatusIn([]string{}) }},
+		{"url", func() feedstore.LinkQueryInterface { return feedstore.LinkQuery().SetURL("") }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.field, func(t *testing.T) {
+			_, err := store.LinkList(ctx, tc.query())
+			checkValidationError(t, "LinkList", tc.field, err)
+
+			_, err = store.LinkCount(ctx, tc.query())
+			checkValidationError(t, "LinkCount", tc.field, err)
+
+			_, err = store.LinkRestoreByQuery(ctx, tc.query())
+			checkValidationError(t, "LinkRestoreByQuery", tc.field, err)
+		})
+	}
+}
+
+// Helper function to check that the error is a validation error of the field
+func checkValidationError(t *testing.T, method string, field string, err error) {
+	t.Helper()
+
+	var validationErr *feedstore.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("%s should return a ValidationError, but got: %v", method, err)
+		return
+	}
+	if validationErr.Field != field {
+		t.Errorf("%s should return a ValidationError of field '%s', got '%s'", method, field, validationErr.Field)
+	}
+}
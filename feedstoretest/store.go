@@ -0,0 +1,173 @@
+package feedstoretest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dracory/feedstore"
+)
+
+func testStoreInfo(t *testing.T, store feedstore.StoreInterface) {
+	if store.GetDriverName() == "" {
+		t.Error("GetDriverName should not be empty")
+	}
+	if store.GetFeedTableName() == "" {
+		t.Error("GetFeedTableName should not be empty")
+	}
+	if store.GetLinkTableName() == "" {
+		t.Error("GetLinkTableName should not be empty")
+	}
+	if store.GetFeedTableName() == store.GetLinkTableName() {
+		t.Errorf("Feed and link tables should differ, both are '%s'", store.GetFeedTableName())
+	}
+
+	store.EnableDebug(true)
+	store.EnableDebug(false)
+}
+
+func testStoreMigrate(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+
+	pending, err := store.MigrationsPending(ctx)
+	if err != nil {
+		t.Fatalf("MigrationsPending should succeed, but got error: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("A new store should have no pending migrations, got %v", pending)
+	}
+
+	if err := store.Migrate(ctx); err != nil {
+		t.Errorf("Migrate of a migrated store should succeed, but got error: %v", err)
+	}
+	if err := store.AutoMigrate(); err != nil {
+		t.Errorf("AutoMigrate of a migrated store should succeed, but got error: %v", err)
+	}
+
+	if err := store.FeedCreate(ctx, newFeed("Feed", "https://example.com/feed.xml")); err != nil {
+		t.Errorf("FeedCreate after Migrate should succeed, but got error: %v", err)
+	}
+}
+
+func testWithTx(t *testing.T, store feedstore.StoreInterface) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	testCases := []struct {
+		name      string
+		committed bool
+		run       func(feed feedstore.FeedInterface, link feedstore.LinkInterface) error
+	}{
+		{"commit", true, func(feed feedstore.FeedInterface, link feedstore.LinkInterface) error {
+			return store.WithTx(ctx, func(tx feedstore.StoreInterface) error {
+				return createAndFind(ctx, tx, feed, link)
+			})
+		}},
+		{"rollback on error", false, func(feed feedstore.FeedInterface, link feedstore.LinkInterface) error {
+			return store.WithTx(ctx, func(tx feedstore.StoreInterface) error {
+				if err := createAndFind(ctx, tx, feed, link); err != nil {
+					return err
+				}
+				return errAbort
+			})
+		}},
+		{"rollback on panic", false, func(feed feedstore.FeedInterface, link feedstore.LinkInterface) (err error) {
+			defer func() {
+				if r := recover(); r != errAbort {
+					err = errors.New("WithTx should re-raise the panic of the function")
+				} else {
+					err = errAbort
+				}
+			}()
+
+			return store.WithTx(ctx, func(tx feedstore.StoreInterface) error {
+				if err := createAndFind(ctx, tx, feed, link); err != nil {
+					return err
+				}
+				panic(errAbort)
+			})
+		}},
+		{"nested commit", true, func(feed feedstore.FeedInterface, link feedstore.LinkInterface) error {
+			return store.WithTx(ctx, func(tx feedstore.StoreInterface) error {
+				if err := tx.FeedCreate(ctx, feed); err != nil {
+					return err
+				}
+				return tx.WithTx(ctx, func(nested feedstore.StoreInterface) error {
+					return nested.LinkCreate(ctx, link)
+				})
+			})
+		}},
+		{"nested rollback by the outer function", false, func(feed feedstore.FeedInterface, link feedstore.LinkInterface) error {
+			return store.WithTx(ctx, func(tx feedstore.StoreInterface) error {
+				if err := tx.WithTx(ctx, func(nested feedstore.StoreInterface) error {
+					return createAndFind(ctx, nested, feed, link)
+				}); err != nil {
+					return err
+				}
+				return errAbort
+			})
+		}},
+		{"nested rollback by the nested function", false, func(feed feedstore.FeedInterface, link feedstore.LinkInterface) error {
+			return store.WithTx(ctx, func(tx feedstore.StoreInterface) error {
+				if err := tx.FeedCreate(ctx, feed); err != nil {
+					return err
+				}
+				return tx.WithTx(ctx, func(nested feedstore.StoreInterface) error {
+					if err := nested.LinkCreate(ctx, link); err != nil {
+						return err
+					}
+					return errAbort
+				})
+			})
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			feed := newFeed(tc.name, "https://example.com/feed.xml")
+			link := newLink(feed.ID(), tc.name, "https://example.com/1")
+
+			err := tc.run(feed, link)
+			if tc.committed && err != nil {
+				t.Fatalf("WithTx should succeed, but got error: %v", err)
+			}
+			if !tc.committed && !errors.Is(err, errAbort) {
+				t.Fatalf("WithTx should return the error of the function, but got: %v", err)
+			}
+
+			_, errFeed := store.FeedFindByID(ctx, feed.ID())
+			_, errLink := store.LinkFindByID(ctx, link.ID())
+
+			if tc.committed && (errFeed != nil || errLink != nil) {
+				t.Errorf("Committed feed and link should be found, but got errors: %v, %v", errFeed, errLink)
+			}
+			if !tc.committed && (!errors.Is(errFeed, feedstore.ErrNotFound) || !errors.Is(errLink, feedstore.ErrNotFound)) {
+				t.Errorf("Rolled back feed and link should not be found, but got errors: %v, %v", errFeed, errLink)
+			}
+		})
+	}
+
+	if err := store.WithTx(ctx, nil); !errors.Is(err, feedstore.ErrValidation) {
+		t.Errorf("WithTx with a nil function should return ErrValidation, but got: %v", err)
+	}
+}
+
+// Helper function to create the feed and link with the store, and check
+// they are found by it
+func createAndFind(ctx context.Context, store feedstore.StoreInterface, feed feedstore.FeedInterface, link feedstore.LinkInterface) error {
+	if err := store.FeedCreate(ctx, feed); err != nil {
+		return err
+	}
+
+	if err := store.LinkCreate(ctx, link); err != nil {
+		return err
+	}
+
+	if _, err := store.FeedFindByID(ctx, feed.ID()); err != nil {
+		return err
+	}
+
+	_, err := store.LinkFindByID(ctx, link.ID())
+
+	return err
+}
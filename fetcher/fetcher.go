@@ -0,0 +1,264 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/dracory/feedstore"
+	"github.com/dracory/feedstore/parser"
+	"github.com/dracory/sb"
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+var _ FetcherInterface = (*fetcherImplementation)(nil) // verify it extends the interface
+
+type fetcherImplementation struct {
+	store        feedstore.StoreInterface
+	httpClient   HTTPClientInterface
+	pollInterval time.Duration
+	batchSize    int
+	userAgent    string
+	errorHandler func(err error)
+	debugEnabled bool
+}
+
+// Run fetches the feeds which are due every poll interval, until the
+// context is cancelled
+func (fetcher *fetcherImplementation) Run(ctx context.Context) error {
+	ticker := time.NewTicker(fetcher.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := fetcher.FetchDue(ctx); err != nil && ctx.Err() == nil {
+			fetcher.handleError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// FetchDue fetches every active feed whose next fetch time is in the past,
+// the most overdue first. It returns the number of feeds fetched, and the
+// errors of the feeds which failed joined together.
+//
+// The due feeds are loaded a batch at a time. A fetched feed is due again
+// one fetch interval later, so it drops out of the next batch, and no
+// offset is needed to page through the feeds.
+func (fetcher *fetcherImplementation) FetchDue(ctx context.Context) (int, error) {
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+	seen := map[string]bool{}
+	fetched := 0
+	errs := []error{}
+
+	for {
+		feeds, err := fetcher.dueFeeds(ctx, now)
+
+		if err != nil {
+			return fetched, errors.Join(append(errs, err)...)
+		}
+
+		more := len(feeds) == fetcher.batchSize
+
+		// A feed which could not be marked as fetched is still due, it is
+		// not fetched twice in one run
+		feeds = lo.Filter(feeds, func(feed feedstore.FeedInterface, _ int) bool {
+			return !seen[feed.ID()]
+		})
+
+		for _, feed := range feeds {
+			if ctx.Err() != nil {
+				return fetched, ctx.Err()
+			}
+
+			seen[feed.ID()] = true
+
+			if _, err := fetcher.FetchFeed(ctx, feed); err != nil {
+				errs = append(errs, fmt.Errorf("feed %s: %w", feed.ID(), err))
+				continue
+			}
+
+			fetched++
+		}
+
+		if !more || len(feeds) == 0 {
+			return fetched, errors.Join(errs...)
+		}
+	}
+}
+
+// FetchFeed downloads and parses a single feed, upserts its links by GUID
+// and returns how many of them were new.
+//
+// Links which are already stored get their title and description updated,
+// so edits made by the publisher are picked up.
+//
+// A 304 Not Modified response counts as a fetch without new links.
+// LastFetchedAt is advanced even when the download or parsing fails, so a
+// broken feed is retried after its fetch interval rather than on every poll.
+func (fetcher *fetcherImplementation) FetchFeed(ctx context.Context, feed feedstore.FeedInterface) (int, error) {
+	if feed == nil {
+		return 0, errors.New("feed is nil")
+	}
+
+	if feed.URL() == "" {
+		return 0, errors.New("feed url is empty")
+	}
+
+	etag, lastModified := feed.ETag(), feed.LastModified()
+
+	result, err := fetcher.download(ctx, feed)
+
+	if err != nil {
+		return 0, errors.Join(err, fetcher.markAsFetched(ctx, feed))
+	}
+
+	// Not modified since the last fetch, nothing new
+	if result == nil {
+		return 0, fetcher.markAsFetched(ctx, feed)
+	}
+
+	result.ApplyTo(feed)
+
+	created := 0
+
+	// The links of a document are stored all or nothing
+	err = fetcher.store.WithTx(ctx, func(tx feedstore.StoreInterface) error {
+		for _, link := range result.Links {
+			exists, err := linkExists(ctx, tx, link)
+
+			if err != nil {
+				return err
+			}
+
+			if err := tx.LinkUpsert(ctx, link); err != nil {
+				return err
+			}
+
+			if !exists {
+				created++
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		// Keep the old validators, so the document is not answered with
+		// 304 Not Modified before its links are stored
+		feed.SetETag(etag).SetLastModified(lastModified)
+
+		return 0, errors.Join(err, fetcher.markAsFetched(ctx, feed))
+	}
+
+	if fetcher.debugEnabled {
+		log.Printf("feed fetcher: feed %s fetched, %d new links", feed.ID(), created)
+	}
+
+	return created, fetcher.markAsFetched(ctx, feed)
+}
+
+// download retrieves the feed document and parses it.
+//
+// The validators stored with the feed are sent along, so the publisher can
+// answer with 304 Not Modified, in which case a nil result is returned. The
+// validators of a fresh response are stored on the feed for the next fetch.
+func (fetcher *fetcherImplementation) download(ctx context.Context, feed feedstore.FeedInterface) (*parser.Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feed.URL(), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", fetcher.userAgent)
+
+	if feed.ETag() != "" {
+		req.Header.Set("If-None-Match", feed.ETag())
+	}
+
+	if feed.LastModified() != "" {
+		req.Header.Set("If-Modified-Since", feed.LastModified())
+	}
+
+	if fetcher.debugEnabled {
+		log.Println("feed fetcher: GET " + feed.URL())
+	}
+
+	resp, err := fetcher.httpClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	result, err := parser.Parse(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	feed.SetETag(resp.Header.Get("ETag"))
+	feed.SetLastModified(resp.Header.Get("Last-Modified"))
+
+	return result, nil
+}
+
+// dueFeeds loads a batch of the active feeds which are due at now, the
+// most overdue first
+func (fetcher *fetcherImplementation) dueFeeds(ctx context.Context, now string) ([]feedstore.FeedInterface, error) {
+	return fetcher.store.FeedList(ctx, feedstore.FeedQuery().
+		SetStatus(feedstore.FEED_STATUS_ACTIVE).
+		SetNextFetchAtLte(now).
+		SetOrderBy(feedstore.COLUMN_NEXT_FETCH_AT).
+		SetOrderDirection(sb.ASC).
+		SetLimit(fetcher.batchSize))
+}
+
+// linkExists checks whether a link with the same GUID was already stored for
+// the feed, including soft deleted links
+func linkExists(ctx context.Context, store feedstore.StoreInterface, link feedstore.LinkInterface) (bool, error) {
+	count, err := store.LinkCount(ctx, feedstore.LinkQuery().
+		SetFeedID(link.FeedID()).
+		SetGUID(link.GUID()).
+		SetWithSoftDeleted(true))
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// markAsFetched sets LastFetchedAt to now, which moves NextFetchAt one
+// fetch interval ahead, and saves the feed
+func (fetcher *fetcherImplementation) markAsFetched(ctx context.Context, feed feedstore.FeedInterface) error {
+	feed.SetLastFetchedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return fetcher.store.FeedUpdate(ctx, feed)
+}
+
+func (fetcher *fetcherImplementation) handleError(err error) {
+	if fetcher.errorHandler != nil {
+		fetcher.errorHandler(err)
+		return
+	}
+
+	log.Println("feed fetcher:", err)
+}
@@ -0,0 +1,20 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dracory/feedstore"
+)
+
+// HTTPClientInterface is the part of *http.Client used by the fetcher,
+// so a custom transport or a test double can be plugged in
+type HTTPClientInterface interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type FetcherInterface interface {
+	FetchDue(ctx context.Context) (int, error)
+	FetchFeed(ctx context.Context, feed feedstore.FeedInterface) (int, error)
+	Run(ctx context.Context) error
+}
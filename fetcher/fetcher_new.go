@@ -0,0 +1,75 @@
+package fetcher
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dracory/feedstore"
+)
+
+const DEFAULT_BATCH_SIZE = 100
+const DEFAULT_POLL_INTERVAL = time.Minute
+const DEFAULT_USER_AGENT = "feedstore-fetcher/1.0"
+
+// NewFetcherOptions define the options for creating a new fetcher
+type NewFetcherOptions struct {
+	// Store is the feed store to read feeds from and write links to
+	Store feedstore.StoreInterface
+
+	// HTTPClient is used to download the feeds, defaults to an *http.Client
+	// with a 30 second timeout
+	HTTPClient HTTPClientInterface
+
+	// PollInterval is how often Run looks for feeds which are due,
+	// defaults to DEFAULT_POLL_INTERVAL
+	PollInterval time.Duration
+
+	// BatchSize is the number of feeds loaded per query when looking for
+	// feeds which are due, defaults to DEFAULT_BATCH_SIZE
+	BatchSize int
+
+	// UserAgent is sent with every request, defaults to DEFAULT_USER_AGENT
+	UserAgent string
+
+	// ErrorHandler receives the errors which occur while Run is running.
+	// When not set errors are logged.
+	ErrorHandler func(err error)
+
+	DebugEnabled bool
+}
+
+// NewFetcher creates a new feed fetcher
+func NewFetcher(opts NewFetcherOptions) (FetcherInterface, error) {
+	if opts.Store == nil {
+		return nil, errors.New("feed fetcher: Store is required")
+	}
+
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DEFAULT_POLL_INTERVAL
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DEFAULT_BATCH_SIZE
+	}
+
+	if opts.UserAgent == "" {
+		opts.UserAgent = DEFAULT_USER_AGENT
+	}
+
+	fetcher := &fetcherImplementation{
+		store:        opts.Store,
+		httpClient:   opts.HTTPClient,
+		pollInterval: opts.PollInterval,
+		batchSize:    opts.BatchSize,
+		userAgent:    opts.UserAgent,
+		errorHandler: opts.ErrorHandler,
+		debugEnabled: opts.DebugEnabled,
+	}
+
+	return fetcher, nil
+}
@@ -0,0 +1,356 @@
+package fetcher
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dracory/feedstore"
+	"github.com/dromara/carbon/v2"
+
+	_ "modernc.org/sqlite"
+)
+
+const testRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Test Feed</title>
+    <link>https://example.com/</link>
+    <item>
+      <title>Item One</title>
+      <link>https://example.com/one</link>
+      <pubDate>Mon, 02 Jan 2006 15:04:05 GMT</pubDate>
+    </item>
+    <item>
+      <title>Item Two</title>
+      <link>https://example.com/two</link>
+      <pubDate>Tue, 03 Jan 2006 15:04:05 GMT</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+// Helper function to create a store backed by an in-memory SQLite database
+func createTestStore(t *testing.T) feedstore.StoreInterface {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	store, err := feedstore.NewStore(feedstore.NewStoreOptions{
+		DB:                 db,
+		FeedTableName:      "feeds_fetcher",
+		LinkTableName:      "links_fetcher",
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore should not return an error, but got: %v", err)
+	}
+
+	return store
+}
+
+// Helper function to start a server which serves the test RSS document
+func createTestServer(t *testing.T, requests *int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(testRSS))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// Helper function to create an active feed pointing at the given URL
+func createTestFeed(t *testing.T, store feedstore.StoreInterface, url string, lastFetchedAt string) feedstore.FeedInterface {
+	t.Helper()
+
+	feed := feedstore.NewFeed()
+	feed.SetName("").
+		SetURL(url).
+		SetStatus(feedstore.FEED_STATUS_ACTIVE).
+		SetFetchInterval("600").
+		SetLastFetchedAt(lastFetchedAt)
+
+	if err := store.FeedCreate(context.Background(), feed); err != nil {
+		t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+	}
+
+	return feed
+}
+
+func TestNewFetcherRequiresStore(t *testing.T) {
+	_, err := NewFetcher(NewFetcherOptions{})
+	if err == nil {
+		t.Error("NewFetcher should return an error when Store is missing")
+	}
+}
+
+func TestFetchFeed(t *testing.T) {
+	store := createTestStore(t)
+	ctx := context.Background()
+	requests := int32(0)
+	server := createTestServer(t, &requests)
+
+	feed := createTestFeed(t, store, server.URL, "0002-01-01 00:00:00")
+
+	fetcher, err := NewFetcher(NewFetcherOptions{Store: store, HTTPClient: server.Client()})
+	if err != nil {
+		t.Fatalf("NewFetcher should succeed, but got error: %v", err)
+	}
+
+	created, err := fetcher.FetchFeed(ctx, feed)
+	if err != nil {
+		t.Fatalf("FetchFeed should succeed, but got error: %v", err)
+	}
+	if created != 2 {
+		t.Errorf("Expected 2 links to be created, got %d", created)
+	}
+
+	// Fetching again must not duplicate the links
+	created, err = fetcher.FetchFeed(ctx, feed)
+	if err != nil {
+		t.Fatalf("Second FetchFeed should succeed, but got error: %v", err)
+	}
+	if created != 0 {
+		t.Errorf("Expected no new links on second fetch, got %d", created)
+	}
+
+	count, err := store.LinkCount(ctx, feedstore.LinkQuery().SetFeedID(feed.ID()))
+	if err != nil {
+		t.Fatalf("LinkCount should succeed, but got error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 stored links, got %d", count)
+	}
+
+	found, err := store.FeedFindByID(ctx, feed.ID())
+	if err != nil || found == nil {
+		t.Fatalf("FeedFindByID should find the feed, got %v, %v", found, err)
+	}
+	if found.Name() != "Test Feed" {
+		t.Errorf("Feed name should be filled in from the channel, got '%s'", found.Name())
+	}
+	if !carbon.Parse(found.LastFetchedAt(), carbon.UTC).Gt(carbon.Now(carbon.UTC).SubMinutes(1)) {
+		t.Errorf("LastFetchedAt should be advanced to now, got '%s'", found.LastFetchedAt())
+	}
+}
+
+func TestFetchFeedItemWithoutGUIDOrLink(t *testing.T) {
+	store := createTestStore(t)
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(`<rss version="2.0"><channel><title>Notes</title>
+			<item><title>A note</title><description>Without a link</description></item>
+		</channel></rss>`))
+	}))
+	t.Cleanup(server.Close)
+
+	feed := createTestFeed(t, store, server.URL, "0002-01-01 00:00:00")
+	fetcher, _ := NewFetcher(NewFetcherOptions{Store: store, HTTPClient: server.Client()})
+
+	for i := 0; i < 2; i++ {
+		if _, err := fetcher.FetchFeed(ctx, feed); err != nil {
+			t.Fatalf("FetchFeed %d should succeed, but got error: %v", i+1, err)
+		}
+	}
+
+	count, err := store.LinkCount(ctx, feedstore.LinkQuery().SetFeedID(feed.ID()))
+	if err != nil {
+		t.Fatalf("LinkCount should succeed, but got error: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected the item to be stored once, got %d links", count)
+	}
+}
+
+func TestFetchFeedErrorStillAdvancesLastFetchedAt(t *testing.T) {
+	store := createTestStore(t)
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	feed := createTestFeed(t, store, server.URL, "0002-01-01 00:00:00")
+
+	fetcher, _ := NewFetcher(NewFetcherOptions{Store: store, HTTPClient: server.Client()})
+
+	if _, err := fetcher.FetchFeed(ctx, feed); err == nil {
+		t.Fatal("FetchFeed should return an error for a 500 response")
+	}
+
+	found, _ := store.FeedFindByID(ctx, feed.ID())
+	if found == nil || found.LastFetchedAt() == "0002-01-01 00:00:00" {
+		t.Error("LastFetchedAt should be advanced even when the fetch fails")
+	}
+}
+
+func TestFetchFeedConditional(t *testing.T) {
+	store := createTestStore(t)
+	ctx := context.Background()
+
+	const etag = `"v1"`
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+	notModified := int32(0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag && r.Header.Get("If-Modified-Since") == lastModified {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte(testRSS))
+	}))
+	defer server.Close()
+
+	feed := createTestFeed(t, store, server.URL, "0002-01-01 00:00:00")
+	fetcher, _ := NewFetcher(NewFetcherOptions{Store: store, HTTPClient: server.Client()})
+
+	if _, err := fetcher.FetchFeed(ctx, feed); err != nil {
+		t.Fatalf("FetchFeed should succeed, but got error: %v", err)
+	}
+
+	stored, _ := store.FeedFindByID(ctx, feed.ID())
+	if stored == nil {
+		t.Fatal("Feed should be found after fetching")
+	}
+	if stored.ETag() != etag {
+		t.Errorf("ETag should be persisted, expected '%s', got '%s'", etag, stored.ETag())
+	}
+	if stored.LastModified() != lastModified {
+		t.Errorf("LastModified should be persisted, expected '%s', got '%s'", lastModified, stored.LastModified())
+	}
+
+	// Make the stored LastFetchedAt recognisable to see it being advanced
+	stored.SetLastFetchedAt("2000-01-01 00:00:00")
+	if err := store.FeedUpdate(ctx, stored); err != nil {
+		t.Fatalf("FeedUpdate should succeed, but got error: %v", err)
+	}
+
+	created, err := fetcher.FetchFeed(ctx, stored)
+	if err != nil {
+		t.Fatalf("FetchFeed with a 304 response should succeed, but got error: %v", err)
+	}
+	if created != 0 {
+		t.Errorf("Expected no links to be created for a 304 response, got %d", created)
+	}
+	if atomic.LoadInt32(&notModified) != 1 {
+		t.Errorf("Expected the conditional request to be answered with 304 once, got %d", notModified)
+	}
+
+	refetched, _ := store.FeedFindByID(ctx, feed.ID())
+	if refetched == nil || refetched.LastFetchedAt() == "2000-01-01 00:00:00" {
+		t.Error("LastFetchedAt should be advanced after a 304 response")
+	}
+	if refetched != nil && refetched.ETag() != etag {
+		t.Errorf("ETag should be kept after a 304 response, got '%s'", refetched.ETag())
+	}
+}
+
+func TestFetchDue(t *testing.T) {
+	store := createTestStore(t)
+	ctx := context.Background()
+	requests := int32(0)
+	server := createTestServer(t, &requests)
+
+	now := carbon.Now(carbon.UTC)
+	neverFetched := createTestFeed(t, store, server.URL+"/never", "0002-01-01 00:00:00")
+	overdue := createTestFeed(t, store, server.URL+"/overdue", now.Copy().SubMinutes(20).ToDateTimeString(carbon.UTC))
+	recent := createTestFeed(t, store, server.URL+"/recent", now.Copy().SubMinutes(5).ToDateTimeString(carbon.UTC))
+
+	inactive := feedstore.NewFeed()
+	inactive.SetName("Inactive").SetURL(server.URL + "/inactive").SetStatus(feedstore.FEED_STATUS_INACTIVE)
+	if err := store.FeedCreate(ctx, inactive); err != nil {
+		t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+	}
+
+	fetcher, _ := NewFetcher(NewFetcherOptions{Store: store, HTTPClient: server.Client(), BatchSize: 1})
+
+	fetched, err := fetcher.FetchDue(ctx)
+	if err != nil {
+		t.Fatalf("FetchDue should succeed, but got error: %v", err)
+	}
+	if fetched != 2 {
+		t.Errorf("Expected 2 feeds to be fetched (never fetched and overdue), got %d", fetched)
+	}
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Errorf("Expected 2 HTTP requests, got %d", requests)
+	}
+
+	for _, feed := range []feedstore.FeedInterface{neverFetched, overdue} {
+		count, _ := store.LinkCount(ctx, feedstore.LinkQuery().SetFeedID(feed.ID()))
+		if count != 2 {
+			t.Errorf("Expected 2 links for due feed %s, got %d", feed.URL(), count)
+		}
+	}
+
+	count, _ := store.LinkCount(ctx, feedstore.LinkQuery().SetFeedID(recent.ID()))
+	if count != 0 {
+		t.Errorf("Expected no links for the recently fetched feed, got %d", count)
+	}
+
+	// Nothing is due right after fetching
+	fetched, err = fetcher.FetchDue(ctx)
+	if err != nil {
+		t.Fatalf("Second FetchDue should succeed, but got error: %v", err)
+	}
+	if fetched != 0 {
+		t.Errorf("Expected no feeds to be due on the second run, got %d", fetched)
+	}
+}
+
+func TestRunStopsOnContextCancel(t *testing.T) {
+	store := createTestStore(t)
+	requests := int32(0)
+	server := createTestServer(t, &requests)
+
+	createTestFeed(t, store, server.URL, "0002-01-01 00:00:00")
+
+	fetcher, _ := NewFetcher(NewFetcherOptions{
+		Store:        store,
+		HTTPClient:   server.Client(),
+		PollInterval: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- fetcher.Run(ctx)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&requests) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&requests) == 0 {
+		t.Error("Run should fetch the due feed")
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Run should return context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run should stop when the context is cancelled")
+	}
+}
@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/dracory/feedstore"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	XMLNS    string      `xml:"xmlns,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Author   atomAuthor  `xml:"author"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     atomText   `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Summary   *atomText  `xml:"summary,omitempty"`
+	Published string     `xml:"published,omitempty"`
+	Updated   string     `xml:"updated"`
+}
+
+// WriteAtom renders the feed and its links as an Atom 1.0 document.
+//
+// The feed id is the feed URL when set, otherwise a URN derived from the
+// feed ID. Entry ids are URNs derived from the link ID, so they stay stable
+// when the link URL changes. The link description is published as an HTML
+// summary, the link time as published date.
+func WriteAtom(w io.Writer, feed feedstore.FeedInterface, links []feedstore.LinkInterface, options Options) error {
+	if feed == nil {
+		return errors.New("generator: feed is nil")
+	}
+
+	doc := atomFeed{
+		XMLNS:    atomNamespace,
+		ID:       firstNonEmpty(options.FeedURL, "urn:feedstore:feed:"+feed.ID()),
+		Title:    feed.Name(),
+		Subtitle: feed.Description(),
+		Author:   atomAuthor{Name: firstNonEmpty(options.AuthorName, feed.Name())},
+		Links:    []atomLink{},
+		Entries:  []atomEntry{},
+	}
+
+	if options.FeedURL != "" {
+		doc.Links = append(doc.Links, atomLink{Href: options.FeedURL, Rel: "self", Type: "application/atom+xml"})
+	}
+
+	if options.HomePageURL != "" {
+		doc.Links = append(doc.Links, atomLink{Href: options.HomePageURL, Rel: "alternate", Type: "text/html"})
+	}
+
+	feedUpdated := time.Time{}
+
+	for _, link := range links {
+		published := parseStoredTime(link.Time())
+		updated := parseStoredTime(link.UpdatedAt())
+
+		if updated.IsZero() {
+			updated = published
+		}
+
+		if updated.IsZero() {
+			updated = time.Now().UTC()
+		}
+
+		if updated.After(feedUpdated) {
+			feedUpdated = updated
+		}
+
+		entry := atomEntry{
+			ID:      "urn:feedstore:link:" + link.ID(),
+			Title:   atomText{Type: "text", Value: firstNonEmpty(link.Title(), link.URL())},
+			Links:   []atomLink{},
+			Updated: updated.Format(time.RFC3339),
+		}
+
+		if link.URL() != "" {
+			entry.Links = append(entry.Links, atomLink{Href: link.URL(), Rel: "alternate"})
+		}
+
+		if link.Description() != "" {
+			entry.Summary = &atomText{Type: "html", Value: link.Description()}
+		}
+
+		if !published.IsZero() {
+			entry.Published = published.Format(time.RFC3339)
+		}
+
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	if feedUpdated.IsZero() {
+		feedUpdated = time.Now().UTC()
+	}
+
+	doc.Updated = feedUpdated.Format(time.RFC3339)
+
+	return writeXML(w, doc)
+}
@@ -0,0 +1,77 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dracory/feedstore/parser"
+)
+
+func TestWriteAtom(t *testing.T) {
+	feed, links := createTestLinks()
+
+	buffer := bytes.Buffer{}
+	err := WriteAtom(&buffer, feed, links, Options{
+		HomePageURL: "https://example.com/",
+		FeedURL:     "https://example.com/atom.xml",
+	})
+	if err != nil {
+		t.Fatalf("WriteAtom should succeed, but got error: %v", err)
+	}
+
+	output := buffer.String()
+	if !strings.Contains(output, `<feed xmlns="http://www.w3.org/2005/Atom">`) {
+		t.Errorf("Output should be an Atom document, got '%s'", output)
+	}
+	if !strings.Contains(output, "<id>https://example.com/atom.xml</id>") {
+		t.Errorf("Feed id should be the feed URL, got '%s'", output)
+	}
+	if !strings.Contains(output, "<name>Curated Links</name>") {
+		t.Errorf("Author should default to the feed name, got '%s'", output)
+	}
+	if !strings.Contains(output, "<id>urn:feedstore:link:"+links[0].ID()+"</id>") {
+		t.Errorf("Entry id should be derived from the link ID, got '%s'", output)
+	}
+	if !strings.Contains(output, "<published>2006-01-02T15:04:05Z</published>") {
+		t.Errorf("Output should contain the link time as published, got '%s'", output)
+	}
+
+	// Round trip through the parser
+	result, err := parser.ParseAtom(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatalf("ParseAtom should read the generated document, but got error: %v", err)
+	}
+	if result.Title != "Curated Links" || result.SiteURL != "https://example.com/" {
+		t.Errorf("Round trip feed: got title '%s' and site URL '%s'", result.Title, result.SiteURL)
+	}
+	if len(result.Links) != 2 {
+		t.Fatalf("Round trip: expected 2 links, got %d", len(result.Links))
+	}
+	first := result.Links[0]
+	if first.Title() != "First & Foremost" || first.URL() != "https://example.com/first" {
+		t.Errorf("Round trip link: got title '%s' and URL '%s'", first.Title(), first.URL())
+	}
+	if first.Description() != "<p>First description</p>" {
+		t.Errorf("Round trip description: expected the HTML summary, got '%s'", first.Description())
+	}
+	if first.Time() != "2006-01-02 15:04:05" {
+		t.Errorf("Round trip time: expected '2006-01-02 15:04:05', got '%s'", first.Time())
+	}
+}
+
+func TestWriteAtomWithoutFeedURL(t *testing.T) {
+	feed, _ := createTestLinks()
+
+	buffer := bytes.Buffer{}
+	if err := WriteAtom(&buffer, feed, nil, Options{AuthorName: "Editor"}); err != nil {
+		t.Fatalf("WriteAtom should succeed, but got error: %v", err)
+	}
+
+	if !strings.Contains(buffer.String(), "<id>urn:feedstore:feed:"+feed.ID()+"</id>") {
+		t.Errorf("Feed id should fall back to a URN of the feed ID, got '%s'", buffer.String())
+	}
+	if !strings.Contains(buffer.String(), "<name>Editor</name>") {
+		t.Errorf("Author should be taken from the options, got '%s'", buffer.String())
+	}
+}
@@ -0,0 +1,114 @@
+// Package generator renders stored feeds and links as syndication documents.
+package generator
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/dracory/feedstore"
+	"github.com/dracory/sb"
+	"github.com/dromara/carbon/v2"
+)
+
+// Format identifies a syndication document format
+type Format string
+
+const FORMAT_ATOM Format = "atom"
+const FORMAT_JSON_FEED Format = "jsonfeed"
+const FORMAT_RSS Format = "rss"
+
+// Options carries the publishing details which are not stored with the feed
+type Options struct {
+	// HomePageURL is the URL of the website the generated feed belongs to
+	HomePageURL string
+
+	// FeedURL is the URL the generated document is published at
+	FeedURL string
+
+	// AuthorName is the author of an Atom feed, defaults to the feed name
+	AuthorName string
+}
+
+// Generate renders the links matching the query as a document of the given
+// format, with the channel details taken from the feed.
+//
+// The query is used as is, so links from several feeds can be republished
+// under one feed. When the query is nil, the links of the feed are used.
+func Generate(ctx context.Context, store feedstore.StoreInterface, feed feedstore.FeedInterface, query feedstore.LinkQueryInterface, format Format, w io.Writer, options Options) error {
+	if store == nil {
+		return errors.New("generator: store is nil")
+	}
+
+	if feed == nil {
+		return errors.New("generator: feed is nil")
+	}
+
+	if query == nil {
+		query = feedstore.LinkQuery().SetFeedID(feed.ID())
+	}
+
+	links, err := store.LinkList(ctx, query)
+
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FORMAT_ATOM:
+		return WriteAtom(w, feed, links, options)
+	case FORMAT_JSON_FEED:
+		return WriteJSONFeed(w, feed, links, options)
+	case FORMAT_RSS:
+		return WriteRSS(w, feed, links, options)
+	default:
+		return errors.New("generator: unsupported format " + string(format))
+	}
+}
+
+// parseStoredTime converts a datetime as stored by feedstore into a time.
+// The zero time is returned for empty and NULL datetimes.
+func parseStoredTime(value string) time.Time {
+	if value == "" || value == sb.NULL_DATETIME || value == sb.MAX_DATETIME {
+		return time.Time{}
+	}
+
+	c := carbon.Parse(value, carbon.UTC)
+
+	if c.IsInvalid() || c.IsZero() {
+		return time.Time{}
+	}
+
+	return c.StdTime()
+}
+
+// writeXML writes the XML header followed by the indented document
+func writeXML(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// firstNonEmpty returns the first of the values which is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
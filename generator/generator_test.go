@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/dracory/feedstore"
+
+	_ "modernc.org/sqlite"
+)
+
+// Helper function to create a store backed by an in-memory SQLite database
+func createTestStore(t *testing.T) feedstore.StoreInterface {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	store, err := feedstore.NewStore(feedstore.NewStoreOptions{
+		DB:                 db,
+		FeedTableName:      "feeds_generator",
+		LinkTableName:      "links_generator",
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore should not return an error, but got: %v", err)
+	}
+
+	return store
+}
+
+func TestGenerate(t *testing.T) {
+	store := createTestStore(t)
+	ctx := context.Background()
+
+	feed := feedstore.NewFeed()
+	feed.SetName("Curated Links")
+	if err := store.FeedCreate(ctx, feed); err != nil {
+		t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+	}
+
+	active := feedstore.NewLink()
+	active.SetFeedID(feed.ID()).SetTitle("Active").SetURL("https://example.com/active").SetStatus(feedstore.LINK_STATUS_ACTIVE)
+	inactive := feedstore.NewLink()
+	inactive.SetFeedID(feed.ID()).SetTitle("Inactive").SetURL("https://example.com/inactive").SetStatus(feedstore.LINK_STATUS_INACTIVE)
+
+	for _, link := range []feedstore.LinkInterface{active, inactive} {
+		if err := store.LinkCreate(ctx, link); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		format   Format
+		query    feedstore.LinkQueryInterface
+		expected []string
+		excluded []string
+	}{
+		{
+			format:   FORMAT_RSS,
+			query:    feedstore.LinkQuery().SetStatus(feedstore.LINK_STATUS_ACTIVE),
+			expected: []string{"<rss", "https://example.com/active"},
+			excluded: []string{"https://example.com/inactive"},
+		},
+		{
+			format:   FORMAT_ATOM,
+			query:    nil,
+			expected: []string{"<feed", "https://example.com/active", "https://example.com/inactive"},
+		},
+		{
+			format:   FORMAT_JSON_FEED,
+			query:    feedstore.LinkQuery().SetStatus(feedstore.LINK_STATUS_ACTIVE),
+			expected: []string{JSONFeedVersion, "https://example.com/active"},
+			excluded: []string{"https://example.com/inactive"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.format), func(t *testing.T) {
+			buffer := bytes.Buffer{}
+			if err := Generate(ctx, store, feed, tc.query, tc.format, &buffer, Options{}); err != nil {
+				t.Fatalf("Generate should succeed, but got error: %v", err)
+			}
+
+			for _, expected := range tc.expected {
+				if !strings.Contains(buffer.String(), expected) {
+					t.Errorf("Output should contain '%s', got '%s'", expected, buffer.String())
+				}
+			}
+			for _, excluded := range tc.excluded {
+				if strings.Contains(buffer.String(), excluded) {
+					t.Errorf("Output should not contain '%s', got '%s'", excluded, buffer.String())
+				}
+			}
+		})
+	}
+
+	if err := Generate(ctx, store, feed, nil, Format("unknown"), &bytes.Buffer{}, Options{}); err == nil {
+		t.Error("Generate should return an error for an unsupported format")
+	}
+}
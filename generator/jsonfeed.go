@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/dracory/feedstore"
+)
+
+// JSONFeedVersion is the JSON Feed specification version produced
+const JSONFeedVersion = "https://jsonfeed.org/version/1.1"
+
+type jsonFeedDocument struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url,omitempty"`
+	Title         string `json:"title,omitempty"`
+	ContentHTML   string `json:"content_html,omitempty"`
+	ContentText   string `json:"content_text,omitempty"`
+	DatePublished string `json:"date_published,omitempty"`
+	DateModified  string `json:"date_modified,omitempty"`
+}
+
+// WriteJSONFeed renders the feed and its links as a JSON Feed 1.1 document.
+//
+// The link description is used as the HTML content of an item. As the
+// specification requires every item to have content, links without a
+// description fall back to their title as text content.
+func WriteJSONFeed(w io.Writer, feed feedstore.FeedInterface, links []feedstore.LinkInterface, options Options) error {
+	if feed == nil {
+		return errors.New("generator: feed is nil")
+	}
+
+	doc := jsonFeedDocument{
+		Version:     JSONFeedVersion,
+		Title:       feed.Name(),
+		HomePageURL: options.HomePageURL,
+		FeedURL:     options.FeedURL,
+		Description: feed.Description(),
+		Items:       []jsonFeedItem{},
+	}
+
+	for _, link := range links {
+		item := jsonFeedItem{
+			ID:    link.ID(),
+			URL:   link.URL(),
+			Title: link.Title(),
+		}
+
+		if link.Description() != "" {
+			item.ContentHTML = link.Description()
+		} else {
+			item.ContentText = link.Title()
+		}
+
+		if published := parseStoredTime(link.Time()); !published.IsZero() {
+			item.DatePublished = published.Format(time.RFC3339)
+		}
+
+		if modified := parseStoredTime(link.UpdatedAt()); !modified.IsZero() {
+			item.DateModified = modified.Format(time.RFC3339)
+		}
+
+		doc.Items = append(doc.Items, item)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(doc)
+}
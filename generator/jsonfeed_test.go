@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/dracory/feedstore"
+	"github.com/dracory/feedstore/parser"
+)
+
+func TestWriteJSONFeed(t *testing.T) {
+	feed := feedstore.NewFeed()
+	feed.SetName("Curated Links").SetDescription("Links worth reading")
+
+	withDescription := feedstore.NewLink()
+	withDescription.SetTitle("First").
+		SetURL("https://example.com/first").
+		SetDescription("<p>First description</p>").
+		SetTime("2006-01-02 15:04:05")
+
+	withoutDescription := feedstore.NewLink()
+	withoutDescription.SetTitle("Second").SetURL("https://example.com/second")
+
+	links := []feedstore.LinkInterface{withDescription, withoutDescription}
+
+	buffer := bytes.Buffer{}
+	err := WriteJSONFeed(&buffer, feed, links, Options{
+		HomePageURL: "https://example.com/",
+		FeedURL:     "https://example.com/feed.json",
+	})
+	if err != nil {
+		t.Fatalf("WriteJSONFeed should succeed, but got error: %v", err)
+	}
+
+	doc := map[string]any{}
+	if err := json.Unmarshal(buffer.Bytes(), &doc); err != nil {
+		t.Fatalf("Output should be valid JSON, but got error: %v", err)
+	}
+	if doc["version"] != JSONFeedVersion {
+		t.Errorf("version: expected '%s', got '%v'", JSONFeedVersion, doc["version"])
+	}
+	if doc["feed_url"] != "https://example.com/feed.json" {
+		t.Errorf("feed_url: expected 'https://example.com/feed.json', got '%v'", doc["feed_url"])
+	}
+
+	items, _ := doc["items"].([]any)
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+
+	first := items[0].(map[string]any)
+	if first["id"] != withDescription.ID() {
+		t.Errorf("id: expected '%s', got '%v'", withDescription.ID(), first["id"])
+	}
+	if first["content_html"] != "<p>First description</p>" {
+		t.Errorf("content_html: expected the description, got '%v'", first["content_html"])
+	}
+	if first["date_published"] != "2006-01-02T15:04:05Z" {
+		t.Errorf("date_published: expected '2006-01-02T15:04:05Z', got '%v'", first["date_published"])
+	}
+
+	second := items[1].(map[string]any)
+	if second["content_text"] != "Second" {
+		t.Errorf("content_text: expected title fallback 'Second', got '%v'", second["content_text"])
+	}
+	if _, ok := second["date_published"]; ok {
+		t.Error("date_published should be omitted for a link without time")
+	}
+
+	// Round trip through the parser
+	result, err := parser.ParseJSONFeed(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatalf("ParseJSONFeed should read the generated document, but got error: %v", err)
+	}
+	if result.Title != "Curated Links" {
+		t.Errorf("Round trip title: expected 'Curated Links', got '%s'", result.Title)
+	}
+	if len(result.Links) != 2 || result.Links[0].URL() != "https://example.com/first" {
+		t.Errorf("Round trip links do not match the generated items")
+	}
+}
+
+func TestWriteJSONFeedNilFeed(t *testing.T) {
+	err := WriteJSONFeed(&bytes.Buffer{}, nil, nil, Options{})
+	if err == nil {
+		t.Error("WriteJSONFeed should return an error for a nil feed")
+	}
+}
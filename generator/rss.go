@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/dracory/feedstore"
+)
+
+type rssDocument struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomXMLNS string     `xml:"xmlns:atom,attr,omitempty"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string       `xml:"title"`
+	Link          string       `xml:"link"`
+	Description   string       `xml:"description"`
+	AtomLink      *rssAtomLink `xml:"atom:link,omitempty"`
+	LastBuildDate string       `xml:"lastBuildDate"`
+	Items         []rssItem    `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title,omitempty"`
+	Link        string  `xml:"link,omitempty"`
+	Description string  `xml:"description,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// WriteRSS renders the feed and its links as an RSS 2.0 document.
+//
+// The channel link is the home page URL, falling back to the feed URL, as
+// RSS requires one. When the feed URL is set it is also published as an
+// atom:link with rel="self". The link time is used as pubDate.
+func WriteRSS(w io.Writer, feed feedstore.FeedInterface, links []feedstore.LinkInterface, options Options) error {
+	if feed == nil {
+		return errors.New("generator: feed is nil")
+	}
+
+	channel := rssChannel{
+		Title:         feed.Name(),
+		Link:          firstNonEmpty(options.HomePageURL, options.FeedURL),
+		Description:   firstNonEmpty(feed.Description(), feed.Name()),
+		LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+		Items:         []rssItem{},
+	}
+
+	doc := rssDocument{
+		Version: "2.0",
+	}
+
+	if options.FeedURL != "" {
+		doc.AtomXMLNS = atomNamespace
+		channel.AtomLink = &rssAtomLink{
+			Href: options.FeedURL,
+			Rel:  "self",
+			Type: "application/rss+xml",
+		}
+	}
+
+	for _, link := range links {
+		item := rssItem{
+			Title:       link.Title(),
+			Link:        link.URL(),
+			Description: link.Description(),
+			GUID: rssGUID{
+				IsPermaLink: "false",
+				Value:       link.ID(),
+			},
+		}
+
+		if published := parseStoredTime(link.Time()); !published.IsZero() {
+			item.PubDate = published.Format(time.RFC1123Z)
+		}
+
+		// RSS requires either a title or a description
+		if item.Title == "" && item.Description == "" {
+			item.Title = link.URL()
+		}
+
+		channel.Items = append(channel.Items, item)
+	}
+
+	doc.Channel = channel
+
+	return writeXML(w, doc)
+}
@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dracory/feedstore"
+	"github.com/dracory/feedstore/parser"
+)
+
+// Helper function to create the feed and links rendered by the generator tests
+func createTestLinks() (feedstore.FeedInterface, []feedstore.LinkInterface) {
+	feed := feedstore.NewFeed()
+	feed.SetName("Curated Links").SetDescription("Links worth reading")
+
+	withDescription := feedstore.NewLink()
+	withDescription.SetTitle("First & Foremost").
+		SetURL("https://example.com/first").
+		SetDescription("<p>First description</p>").
+		SetTime("2006-01-02 15:04:05")
+
+	withoutDescription := feedstore.NewLink()
+	withoutDescription.SetTitle("Second").SetURL("https://example.com/second")
+
+	return feed, []feedstore.LinkInterface{withDescription, withoutDescription}
+}
+
+func TestWriteRSS(t *testing.T) {
+	feed, links := createTestLinks()
+
+	buffer := bytes.Buffer{}
+	err := WriteRSS(&buffer, feed, links, Options{
+		HomePageURL: "https://example.com/",
+		FeedURL:     "https://example.com/feed.xml",
+	})
+	if err != nil {
+		t.Fatalf("WriteRSS should succeed, but got error: %v", err)
+	}
+
+	output := buffer.String()
+	if !strings.Contains(output, `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">`) {
+		t.Errorf("Output should be an RSS 2.0 document declaring the atom namespace, got '%s'", output)
+	}
+	if !strings.Contains(output, `<atom:link href="https://example.com/feed.xml" rel="self" type="application/rss+xml"></atom:link>`) {
+		t.Errorf("Output should contain the self link, got '%s'", output)
+	}
+	if !strings.Contains(output, "<pubDate>Mon, 02 Jan 2006 15:04:05 +0000</pubDate>") {
+		t.Errorf("Output should contain the link time as pubDate, got '%s'", output)
+	}
+	if !strings.Contains(output, `<guid isPermaLink="false">`+links[0].ID()+`</guid>`) {
+		t.Errorf("Output should use the link ID as guid, got '%s'", output)
+	}
+
+	// Round trip through the parser
+	result, err := parser.ParseRSS(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatalf("ParseRSS should read the generated document, but got error: %v", err)
+	}
+	if result.Title != "Curated Links" || result.SiteURL != "https://example.com/" {
+		t.Errorf("Round trip channel: got title '%s' and site URL '%s'", result.Title, result.SiteURL)
+	}
+	if len(result.Links) != 2 {
+		t.Fatalf("Round trip: expected 2 links, got %d", len(result.Links))
+	}
+	first := result.Links[0]
+	if first.Title() != "First & Foremost" || first.URL() != "https://example.com/first" {
+		t.Errorf("Round trip link: got title '%s' and URL '%s'", first.Title(), first.URL())
+	}
+	if first.Description() != "<p>First description</p>" {
+		t.Errorf("Round trip description: expected escaped HTML to be restored, got '%s'", first.Description())
+	}
+	if first.Time() != "2006-01-02 15:04:05" {
+		t.Errorf("Round trip time: expected '2006-01-02 15:04:05', got '%s'", first.Time())
+	}
+}
+
+func TestWriteRSSNilFeed(t *testing.T) {
+	err := WriteRSS(&bytes.Buffer{}, nil, nil, Options{})
+	if err == nil {
+		t.Error("WriteRSS should return an error for a nil feed")
+	}
+}
@@ -11,6 +11,7 @@ require (
 	github.com/dromara/carbon/v2 v2.6.15
 	github.com/samber/lo v1.52.0
 	github.com/spf13/cast v1.10.0
+	golang.org/x/text v0.31.0
 	modernc.org/sqlite v1.40.1
 )
 
@@ -23,7 +24,6 @@ require (
 	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
 	golang.org/x/exp v0.0.0-20251113190631-e25ba8c21ef6 // indirect
 	golang.org/x/sys v0.38.0 // indirect
-	golang.org/x/text v0.31.0 // indirect
 	modernc.org/libc v1.67.0 // indirect
 	modernc.org/mathutil v1.7.1 // indirect
 	modernc.org/memory v1.11.0 // indirect
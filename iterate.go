@@ -0,0 +1,148 @@
+package feedstore
+
+import (
+	"context"
+	"iter"
+	"log"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dracory/database"
+	"github.com/spf13/cast"
+)
+
+// FeedIterate streams the feeds matching the query, one row at a time, so
+// large lists are not loaded into memory.
+//
+// The iteration stops at the first error, which is yielded with a nil feed,
+// including when the context is cancelled. The database connection is held
+// until the iteration stops, so within a transaction, or with a single
+// connection, the loop must not use the store.
+func (st *storeImplementation) FeedIterate(ctx context.Context, query FeedQueryInterface) iter.Seq2[FeedInterface, error] {
+	return func(yield func(FeedInterface, error) bool) {
+		if query == nil {
+			query = FeedQuery()
+		}
+
+		q, columns, err := query.ToSelectDataset(st)
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for row, err := range st.iterateRows(ctx, q.Select(columns...)) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(NewFeedFromExistingData(row), nil) {
+				return
+			}
+		}
+	}
+}
+
+// LinkIterate streams the links matching the query, one row at a time, so
+// large lists are not loaded into memory. Unlike LinkList, all matching
+// links are streamed when the query sets no limit.
+//
+// The iteration stops at the first error, which is yielded with a nil link,
+// including when the context is cancelled. The database connection is held
+// until the iteration stops, so within a transaction, or with a single
+// connection, the loop must not use the store.
+func (st *storeImplementation) LinkIterate(ctx context.Context, query LinkQueryInterface) iter.Seq2[LinkInterface, error] {
+	return func(yield func(LinkInterface, error) bool) {
+		if query == nil {
+			query = LinkQuery()
+		}
+
+		q, columns, err := query.ToSelectDataset(st)
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		if !query.IsLimitSet() {
+			q = q.ClearLimit()
+		}
+
+		for row, err := range st.iterateRows(ctx, q.Select(columns...)) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(NewLinkFromExistingData(row), nil) {
+				return
+			}
+		}
+	}
+}
+
+// iterateRows streams the rows selected by the dataset, converted to
+// strings as by database.SelectToMapString
+func (st *storeImplementation) iterateRows(ctx context.Context, q *goqu.SelectDataset) iter.Seq2[map[string]string, error] {
+	return func(yield func(map[string]string, error) bool) {
+		sqlStr, params, errSql := q.Prepared(true).ToSQL()
+
+		if errSql != nil {
+			yield(nil, errSql)
+			return
+		}
+
+		if st.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		rows, err := database.Query(st.toQueryableContext(ctx), sqlStr, params...)
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		defer rows.Close()
+
+		columns, err := rows.Columns()
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		for rows.Next() {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if err := rows.Scan(pointers...); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			row := make(map[string]any, len(columns))
+
+			for i, column := range columns {
+				row[column] = values[i]
+			}
+
+			if !yield(cast.ToStringMapString(row), nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
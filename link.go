@@ -1,10 +1,13 @@
 package feedstore
 
 import (
+	"strconv"
+
 	"github.com/dracory/dataobject"
 	"github.com/dracory/sb"
 	"github.com/dracory/uid"
 	"github.com/dromara/carbon/v2"
+	"github.com/spf13/cast"
 )
 
 // ============================================================================
@@ -28,6 +31,7 @@ var _ LinkInterface = (*linkImplementation)(nil) // verify it extends the interf
 func NewLink() *linkImplementation {
 	link := &linkImplementation{}
 	link.SetID(uid.NanoUid())
+	link.SetGUID(link.ID()) // unique until replaced with the guid of the entry
 	// link.SetStatus(LINK_STATUS_INACTIVE)
 	// link.SetTitle("")
 	link.SetDescription("")
@@ -38,11 +42,13 @@ func NewLink() *linkImplementation {
 	link.SetVotesDown("0")
 	link.SetReportedAt(sb.NULL_DATETIME)
 	link.SetReport("")
+	link.SetReportCount("0")
 	link.SetCheckedAt(sb.NULL_DATETIME)
 	link.SetTime(sb.NULL_DATETIME)
 	link.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
 	link.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
 	link.SetSoftDeletedAt(sb.MAX_DATETIME)
+	link.SetCascadeDeletedBy("")
 	return link
 }
 
@@ -60,12 +66,27 @@ func NewLinkFromExistingData(data map[string]string) *linkImplementation {
 
 // == SETTERS AND GETTERS =====================================================
 
+// CascadeDeletedBy returns the ID of the feed whose soft deletion soft
+// deleted the link, empty when the link was not soft deleted by a cascade
+func (link *linkImplementation) CascadeDeletedBy() string {
+	return link.Get(COLUMN_CASCADE_DELETED_BY)
+}
+
+func (link *linkImplementation) SetCascadeDeletedBy(feedID string) LinkInterface {
+	link.Set(COLUMN_CASCADE_DELETED_BY, feedID)
+	return link
+}
+
 func (link *linkImplementation) CheckedAt() string {
 	return link.Get(COLUMN_CHECKED_AT)
 }
 
-func (link *linkImplementation) SetCheckedAt(timeChecked string) LinkInterface {
-	link.Set(COLUMN_CHECKED_AT, timeChecked)
+func (link *linkImplementation) CheckedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(link.CheckedAt())
+}
+
+func (link *linkImplementation) SetCheckedAt(checkedAt string) LinkInterface {
+	link.Set(COLUMN_CHECKED_AT, checkedAt)
 	return link
 }
 
@@ -100,6 +121,15 @@ func (link *linkImplementation) SetFeedID(feedID string) LinkInterface {
 	return link
 }
 
+func (link *linkImplementation) GUID() string {
+	return link.Get(COLUMN_GUID)
+}
+
+func (link *linkImplementation) SetGUID(guid string) LinkInterface {
+	link.Set(COLUMN_GUID, guid)
+	return link
+}
+
 func (link *linkImplementation) ID() string {
 	return link.Get(COLUMN_ID)
 }
@@ -140,24 +170,53 @@ func (link *linkImplementation) VotesDown() string {
 	return link.Get(COLUMN_VOTES_DOWN)
 }
 
+func (link *linkImplementation) VotesDownInt64() (int64, error) {
+	return cast.ToInt64E(link.VotesDown())
+}
+
 func (link *linkImplementation) SetVotesDown(votesDown string) LinkInterface {
 	link.Set(COLUMN_VOTES_DOWN, votesDown)
 	return link
 }
 
+func (link *linkImplementation) SetVotesDownInt64(votesDown int64) LinkInterface {
+	return link.SetVotesDown(strconv.FormatInt(votesDown, 10))
+}
+
 func (link *linkImplementation) VotesUp() string {
 	return link.Get(COLUMN_VOTES_UP)
 }
 
+func (link *linkImplementation) VotesUpInt64() (int64, error) {
+	return cast.ToInt64E(link.VotesUp())
+}
+
 func (link *linkImplementation) SetVotesUp(votesUp string) LinkInterface {
 	link.Set(COLUMN_VOTES_UP, votesUp)
 	return link
 }
 
+func (link *linkImplementation) SetVotesUpInt64(votesUp int64) LinkInterface {
+	return link.SetVotesUp(strconv.FormatInt(votesUp, 10))
+}
+
+func (link *linkImplementation) Views() string {
+	return link.Get(COLUMN_VIEWS)
+}
+
+func (link *linkImplementation) ViewsInt64() (int64, error) {
+	return cast.ToInt64E(link.Views())
+}
+
 func (link *linkImplementation) SetViews(views string) LinkInterface {
 	link.Set(COLUMN_VIEWS, views)
 	return link
 }
+
+func (link *linkImplementation) SetViewsInt64(views int64) LinkInterface {
+	return link.SetViews(strconv.FormatInt(views, 10))
+}
+
 func (link *linkImplementation) Report() string {
 	return link.Get(COLUMN_REPORT)
 }
@@ -167,6 +226,23 @@ func (link *linkImplementation) SetReport(report string) LinkInterface {
 	return link
 }
 
+func (link *linkImplementation) ReportCount() string {
+	return link.Get(COLUMN_REPORT_COUNT)
+}
+
+func (link *linkImplementation) ReportCountInt64() (int64, error) {
+	return cast.ToInt64E(link.ReportCount())
+}
+
+func (link *linkImplementation) SetReportCount(reportCount string) LinkInterface {
+	link.Set(COLUMN_REPORT_COUNT, reportCount)
+	return link
+}
+
+func (link *linkImplementation) SetReportCountInt64(reportCount int64) LinkInterface {
+	return link.SetReportCount(strconv.FormatInt(reportCount, 10))
+}
+
 func (link *linkImplementation) ReportedAt() string {
 	return link.Get(COLUMN_REPORTED_AT)
 }
@@ -0,0 +1,134 @@
+package feedstore
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/dracory/sb"
+	"github.com/dromara/carbon/v2"
+)
+
+// linkDateTimeColumns are normalized in cursors, as drivers may return
+// datetimes in a different format than stored
+var linkDateTimeColumns = map[string]bool{
+	COLUMN_CHECKED_AT:      true,
+	COLUMN_CREATED_AT:      true,
+	COLUMN_REPORTED_AT:     true,
+	COLUMN_SOFT_DELETED_AT: true,
+	COLUMN_TIME:            true,
+	COLUMN_UPDATED_AT:      true,
+}
+
+// LinkCursor is the keyset position of a link within a list: the order
+// column, its value for the link and the ID of the link. It is passed
+// around encoded, as an opaque string.
+type LinkCursor struct {
+	OrderBy string `json:"o"`
+	Value   string `json:"v"`
+	ID      string `json:"i"`
+}
+
+// NewLinkCursor returns the cursor positioned after the link, in a list
+// ordered by the column
+func NewLinkCursor(link LinkInterface, orderBy string) LinkCursor {
+	value := link.Data()[orderBy]
+
+	if linkDateTimeColumns[orderBy] {
+		value = carbon.Parse(value, carbon.UTC).ToDateTimeString(carbon.UTC)
+	}
+
+	return LinkCursor{
+		OrderBy: orderBy,
+		Value:   value,
+		ID:      link.ID(),
+	}
+}
+
+// Encode returns the cursor as an opaque string
+func (c LinkCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeLinkCursor parses a cursor returned by LinkListPage
+func DecodeLinkCursor(cursor string) (LinkCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return LinkCursor{}, newValidationError("cursor", "link query: cursor is invalid")
+	}
+
+	c := LinkCursor{}
+
+	if err := json.Unmarshal(data, &c); err != nil || c.OrderBy == "" || c.ID == "" {
+		return LinkCursor{}, newValidationError("cursor", "link query: cursor is invalid")
+	}
+
+	return c, nil
+}
+
+// LinkKeysetOrder returns the column and direction links are paged by with
+// cursors: the order of the query, by ID and descending by default. Links
+// with the same value of the column are ordered by their ID.
+func LinkKeysetOrder(q LinkQueryInterface) (orderBy string, orderDirection string) {
+	orderBy = COLUMN_ID
+	orderDirection = sb.DESC
+
+	if q.IsOrderBySet() {
+		orderBy = q.GetOrderBy()
+	}
+
+	if q.IsOrderDirectionSet() && strings.EqualFold(q.GetOrderDirection(), sb.ASC) {
+		orderDirection = sb.ASC
+	}
+
+	return orderBy, orderDirection
+}
+
+// linkKeysetOrder returns the order expressions of the keyset
+func linkKeysetOrder(orderBy string, orderDirection string) []exp.OrderedExpression {
+	columns := []string{orderBy}
+
+	if orderBy != COLUMN_ID {
+		columns = append(columns, COLUMN_ID)
+	}
+
+	order := []exp.OrderedExpression{}
+
+	for _, column := range columns {
+		if orderDirection == sb.ASC {
+			order = append(order, goqu.I(column).Asc())
+		} else {
+			order = append(order, goqu.I(column).Desc())
+		}
+	}
+
+	return order
+}
+
+// linkKeysetWhere returns the condition selecting the links after the
+// cursor
+func linkKeysetWhere(c LinkCursor, orderDirection string) exp.Expression {
+	after := func(column string, value string) exp.Expression {
+		if orderDirection == sb.ASC {
+			return goqu.I(column).Gt(value)
+		}
+
+		return goqu.I(column).Lt(value)
+	}
+
+	if c.OrderBy == COLUMN_ID {
+		return after(COLUMN_ID, c.ID)
+	}
+
+	return goqu.Or(
+		after(c.OrderBy, c.Value),
+		goqu.And(
+			goqu.I(c.OrderBy).Eq(c.Value),
+			after(COLUMN_ID, c.ID),
+		),
+	)
+}
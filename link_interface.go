@@ -7,19 +7,11 @@ type LinkInterface interface {
 	DataChanged() map[string]string
 	MarkAsNotDirty()
 
-	// Views() string
-	// SetViews(views string) LinkInterface
-	// VotesUp() string
-	// SetVotesUp(votesUp string) LinkInterface
-	// VotesDown() string
-	// SetVotesDown(votesDown string) LinkInterface
-	// Report() string
-	// SetReport(report string) LinkInterface
-	// ReportedAt() string
-	// SetReportedAt(reportedAt string) LinkInterface
-	// CheckedAt() string
-	// SetCheckedAt(timeChecked string) LinkInterface
-
+	CascadeDeletedBy() string
+	SetCascadeDeletedBy(feedID string) LinkInterface
+	CheckedAt() string
+	CheckedAtCarbon() *carbon.Carbon
+	SetCheckedAt(checkedAt string) LinkInterface
 	CreatedAt() string
 	CreatedAtCarbon() *carbon.Carbon
 	SetCreatedAt(createdAt string) LinkInterface
@@ -27,8 +19,19 @@ type LinkInterface interface {
 	SetDescription(description string) LinkInterface
 	FeedID() string
 	SetFeedID(feedID string) LinkInterface
+	GUID() string
+	SetGUID(guid string) LinkInterface
 	ID() string
 	SetID(id string) LinkInterface
+	Report() string
+	SetReport(report string) LinkInterface
+	ReportCount() string
+	ReportCountInt64() (int64, error)
+	SetReportCount(reportCount string) LinkInterface
+	SetReportCountInt64(reportCount int64) LinkInterface
+	ReportedAt() string
+	ReportedAtCarbon() *carbon.Carbon
+	SetReportedAt(reportedAt string) LinkInterface
 	Status() string
 	SetStatus(status string) LinkInterface
 	Title() string
@@ -44,4 +47,16 @@ type LinkInterface interface {
 	SetUpdatedAt(updatedAt string) LinkInterface
 	URL() string
 	SetURL(url string) LinkInterface
+	Views() string
+	ViewsInt64() (int64, error)
+	SetViews(views string) LinkInterface
+	SetViewsInt64(views int64) LinkInterface
+	VotesDown() string
+	VotesDownInt64() (int64, error)
+	SetVotesDown(votesDown string) LinkInterface
+	SetVotesDownInt64(votesDown int64) LinkInterface
+	VotesUp() string
+	VotesUpInt64() (int64, error)
+	SetVotesUp(votesUp string) LinkInterface
+	SetVotesUpInt64(votesUp int64) LinkInterface
 }
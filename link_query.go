@@ -1,7 +1,6 @@
 package feedstore
 
 import (
-	"errors"
 	"strings"
 
 	"github.com/doug-martin/goqu/v9"
@@ -20,15 +19,27 @@ type linkQuery struct {
 	isOnlySoftDeletedSet bool
 	onlySoftDeleted      bool
 
+	isCheckedAtGteSet bool
+	checkedAtGte      string
+
+	isCheckedAtLteSet bool
+	checkedAtLte      string
+
 	isCreatedAtGteSet bool
 	createdAtGte      string
 
+	isCursorSet bool
+	cursor      string
+
 	isCreatedAtLteSet bool
 	createdAtLte      string
 
 	isFeedIDSet bool
 	feedID      string
 
+	isGUIDSet bool
+	guid      string
+
 	isIDSet bool
 	id      string
 
@@ -50,6 +61,21 @@ type linkQuery struct {
 	isOrderDirectionSet bool
 	orderDirection      string
 
+	isRankingSet bool
+	ranking      LinkRanking
+
+	isReportCountGteSet bool
+	reportCountGte      int64
+
+	isReportCountLteSet bool
+	reportCountLte      int64
+
+	isReportedAtGteSet bool
+	reportedAtGte      string
+
+	isReportedAtLteSet bool
+	reportedAtLte      string
+
 	isStatusSet bool
 	status      string
 
@@ -64,6 +90,24 @@ type linkQuery struct {
 
 	isUpdatedAtLteSet bool
 	updatedAtLte      string
+
+	isViewsGteSet bool
+	viewsGte      int64
+
+	isViewsLteSet bool
+	viewsLte      int64
+
+	isVotesDownGteSet bool
+	votesDownGte      int64
+
+	isVotesDownLteSet bool
+	votesDownLte      int64
+
+	isVotesUpGteSet bool
+	votesUpGte      int64
+
+	isVotesUpLteSet bool
+	votesUpLte      int64
 }
 
 var _ LinkQueryInterface = (*linkQuery)(nil)
@@ -73,46 +117,110 @@ func LinkQuery() LinkQueryInterface {
 	return &linkQuery{}
 }
 
+// linkQueryCopy returns a copy of the query, which the store can change without
+// changing the query of the caller
+func linkQueryCopy(query LinkQueryInterface) (*linkQuery, error) {
+	q, ok := query.(*linkQuery)
+
+	if !ok {
+		return nil, newValidationError("query", "link query: only queries created by LinkQuery are supported")
+	}
+
+	copied := *q
+
+	return &copied, nil
+}
+
 // Validate validates the query parameters
 func (q *linkQuery) Validate() error {
 	if q.IsOwnerIDSet() && q.GetOwnerID() == "" {
-		return errors.New("document query: owner_id cannot be empty")
+		return newValidationError("owner_id", "link query: owner_id cannot be empty")
+	}
+
+	if q.IsCheckedAtGteSet() && q.GetCheckedAtGte() == "" {
+		return newValidationError("checked_at_gte", "link query: checked_at_gte cannot be empty")
+	}
+
+	if q.IsCheckedAtLteSet() && q.GetCheckedAtLte() == "" {
+		return newValidationError("checked_at_lte", "link query: checked_at_lte cannot be empty")
 	}
 
 	if q.IsCreatedAtGteSet() && q.GetCreatedAtGte() == "" {
-		return errors.New("document query: created_at_gte cannot be empty")
+		return newValidationError("created_at_gte", "link query: created_at_gte cannot be empty")
 	}
 
 	if q.IsCreatedAtLteSet() && q.GetCreatedAtLte() == "" {
-		return errors.New("document query: created_at_lte cannot be empty")
+		return newValidationError("created_at_lte", "link query: created_at_lte cannot be empty")
+	}
+
+	if q.IsGUIDSet() && q.GetGUID() == "" {
+		return newValidationError("guid", "link query: guid cannot be empty")
 	}
 
 	if q.IsIDSet() && q.GetID() == "" {
-		return errors.New("document query: id cannot be empty")
+		return newValidationError("id", "link query: id cannot be empty")
 	}
 
 	if q.IsIDInSet() && len(q.GetIDIn()) < 1 {
-		return errors.New("document query: id_in cannot be empty array")
+		return newValidationError("id_in", "link query: id_in cannot be empty array")
 	}
 
 	if q.IsLimitSet() && q.GetLimit() < 0 {
-		return errors.New("document query: limit cannot be negative")
+		return newValidationError("limit", "link query: limit cannot be negative")
 	}
 
 	if q.IsOffsetSet() && q.GetOffset() < 0 {
-		return errors.New("document query: offset cannot be negative")
+		return newValidationError("offset", "link query: offset cannot be negative")
+	}
+
+	if q.IsReportedAtGteSet() && q.GetReportedAtGte() == "" {
+		return newValidationError("reported_at_gte", "link query: reported_at_gte cannot be empty")
+	}
+
+	if q.IsReportedAtLteSet() && q.GetReportedAtLte() == "" {
+		return newValidationError("reported_at_lte", "link query: reported_at_lte cannot be empty")
 	}
 
 	if q.IsStatusSet() && q.GetStatus() == "" {
-		return errors.New("document query: status cannot be empty")
+		return newValidationError("status", "link query: status cannot be empty")
 	}
 
 	if q.IsStatusInSet() && len(q.GetStatusIn()) < 1 {
-		return errors.New("document query: status_in cannot be empty array")
+		return newValidationError("status_in", "link query: status_in cannot be empty array")
 	}
 
 	if q.IsURLSet() && q.GetURL() == "" {
-		return errors.New("document query: url cannot be empty")
+		return newValidationError("url", "link query: url cannot be empty")
+	}
+
+	if q.IsRankingSet() {
+		if err := q.GetRanking().Validate(); err != nil {
+			return err
+		}
+
+		if q.IsOrderBySet() {
+			return newValidationError("ranking", "link query: ranking cannot be combined with order_by")
+		}
+
+		if q.IsCursorSet() {
+			return newValidationError("ranking", "link query: ranking cannot be combined with cursor")
+		}
+	}
+
+	if q.IsCursorSet() {
+		if q.IsOffsetSet() {
+			return newValidationError("cursor", "link query: cursor cannot be combined with offset")
+		}
+
+		cursor, err := DecodeLinkCursor(q.GetCursor())
+
+		if err != nil {
+			return err
+		}
+
+		if orderBy, _ := LinkKeysetOrder(q); cursor.OrderBy != orderBy {
+			return newValidationError("cursor", "link query: cursor does not match the order of the query")
+		}
 	}
 
 	return nil
@@ -120,7 +228,7 @@ func (q *linkQuery) Validate() error {
 
 func (q *linkQuery) ToSelectDataset(st StoreInterface) (selectDataset *goqu.SelectDataset, columns []any, err error) {
 	if st == nil {
-		return nil, []any{}, errors.New("store cannot be nil")
+		return nil, []any{}, newValidationError("store", "store cannot be nil")
 	}
 
 	if err := q.Validate(); err != nil {
@@ -129,6 +237,15 @@ func (q *linkQuery) ToSelectDataset(st StoreInterface) (selectDataset *goqu.Sele
 
 	sql := goqu.Dialect(st.GetDriverName()).From(st.GetLinkTableName())
 
+	// Checked At filter
+	if q.IsCheckedAtGteSet() {
+		sql = sql.Where(goqu.C(COLUMN_CHECKED_AT).Gte(q.GetCheckedAtGte()))
+	}
+
+	if q.IsCheckedAtLteSet() {
+		sql = sql.Where(goqu.C(COLUMN_CHECKED_AT).Lte(q.GetCheckedAtLte()))
+	}
+
 	// Created At filter
 	if q.IsCreatedAtGteSet() {
 		sql = sql.Where(goqu.C(COLUMN_CREATED_AT).Gte(q.GetCreatedAtGte()))
@@ -143,6 +260,11 @@ func (q *linkQuery) ToSelectDataset(st StoreInterface) (selectDataset *goqu.Sele
 		sql = sql.Where(goqu.C(COLUMN_FEED_ID).Eq(q.GetFeedID()))
 	}
 
+	// GUID filter
+	if q.IsGUIDSet() {
+		sql = sql.Where(goqu.C(COLUMN_GUID).Eq(q.GetGUID()))
+	}
+
 	// ID filter
 	if q.IsIDSet() {
 		sql = sql.Where(goqu.C(COLUMN_ID).Eq(q.GetID()))
@@ -153,6 +275,24 @@ func (q *linkQuery) ToSelectDataset(st StoreInterface) (selectDataset *goqu.Sele
 		sql = sql.Where(goqu.C(COLUMN_ID).In(q.GetIDIn()))
 	}
 
+	// Report Count filter
+	if q.IsReportCountGteSet() {
+		sql = sql.Where(goqu.C(COLUMN_REPORT_COUNT).Gte(q.GetReportCountGte()))
+	}
+
+	if q.IsReportCountLteSet() {
+		sql = sql.Where(goqu.C(COLUMN_REPORT_COUNT).Lte(q.GetReportCountLte()))
+	}
+
+	// Reported At filter
+	if q.IsReportedAtGteSet() {
+		sql = sql.Where(goqu.C(COLUMN_REPORTED_AT).Gte(q.GetReportedAtGte()))
+	}
+
+	if q.IsReportedAtLteSet() {
+		sql = sql.Where(goqu.C(COLUMN_REPORTED_AT).Lte(q.GetReportedAtLte()))
+	}
+
 	// Status filter
 	if q.IsStatusSet() {
 		sql = sql.Where(goqu.C(COLUMN_STATUS).Eq(q.GetStatus()))
@@ -177,6 +317,32 @@ func (q *linkQuery) ToSelectDataset(st StoreInterface) (selectDataset *goqu.Sele
 		sql = sql.Where(goqu.C(COLUMN_UPDATED_AT).Lte(q.GetUpdatedAtLte()))
 	}
 
+	// Views filter
+	if q.IsViewsGteSet() {
+		sql = sql.Where(goqu.C(COLUMN_VIEWS).Gte(q.GetViewsGte()))
+	}
+
+	if q.IsViewsLteSet() {
+		sql = sql.Where(goqu.C(COLUMN_VIEWS).Lte(q.GetViewsLte()))
+	}
+
+	// Votes filters
+	if q.IsVotesDownGteSet() {
+		sql = sql.Where(goqu.C(COLUMN_VOTES_DOWN).Gte(q.GetVotesDownGte()))
+	}
+
+	if q.IsVotesDownLteSet() {
+		sql = sql.Where(goqu.C(COLUMN_VOTES_DOWN).Lte(q.GetVotesDownLte()))
+	}
+
+	if q.IsVotesUpGteSet() {
+		sql = sql.Where(goqu.C(COLUMN_VOTES_UP).Gte(q.GetVotesUpGte()))
+	}
+
+	if q.IsVotesUpLteSet() {
+		sql = sql.Where(goqu.C(COLUMN_VOTES_UP).Lte(q.GetVotesUpLte()))
+	}
+
 	if !q.IsCountOnlySet() {
 		if q.IsLimitSet() {
 			sql = sql.Limit(uint(q.GetLimit()))
@@ -226,6 +392,28 @@ func (q *linkQuery) ToSelectDataset(st StoreInterface) (selectDataset *goqu.Sele
 		}
 	}
 
+	// Hot ranking, highest score first
+	if q.IsRankingSet() {
+		sql = sql.Order(
+			linkRankingScore(st.GetDriverName(), q.GetRanking()).Desc(),
+			goqu.I(COLUMN_ID).Desc(),
+		)
+	}
+
+	// Keyset pagination, after the cursor
+	if q.IsCursorSet() {
+		cursor, err := DecodeLinkCursor(q.GetCursor())
+
+		if err != nil {
+			return nil, []any{}, err
+		}
+
+		orderBy, orderDirection := LinkKeysetOrder(q)
+
+		sql = sql.Where(linkKeysetWhere(cursor, orderDirection)).
+			Order(linkKeysetOrder(orderBy, orderDirection)...)
+	}
+
 	// Soft delete filters
 
 	// Only soft deleted
@@ -287,6 +475,42 @@ func (q *linkQuery) SetCountOnly(countOnly bool) LinkQueryInterface {
 	return q
 }
 
+func (q *linkQuery) IsCheckedAtGteSet() bool {
+	return q.isCheckedAtGteSet
+}
+
+func (q *linkQuery) GetCheckedAtGte() string {
+	if q.IsCheckedAtGteSet() {
+		return q.checkedAtGte
+	}
+
+	return ""
+}
+
+func (q *linkQuery) SetCheckedAtGte(checkedAt string) LinkQueryInterface {
+	q.isCheckedAtGteSet = true
+	q.checkedAtGte = checkedAt
+	return q
+}
+
+func (q *linkQuery) IsCheckedAtLteSet() bool {
+	return q.isCheckedAtLteSet
+}
+
+func (q *linkQuery) GetCheckedAtLte() string {
+	if q.IsCheckedAtLteSet() {
+		return q.checkedAtLte
+	}
+
+	return ""
+}
+
+func (q *linkQuery) SetCheckedAtLte(checkedAt string) LinkQueryInterface {
+	q.isCheckedAtLteSet = true
+	q.checkedAtLte = checkedAt
+	return q
+}
+
 func (q *linkQuery) IsCreatedAtGteSet() bool {
 	return q.isCreatedAtGteSet
 }
@@ -323,6 +547,24 @@ func (q *linkQuery) SetCreatedAtLte(createdAtLte string) LinkQueryInterface {
 	return q
 }
 
+func (q *linkQuery) IsCursorSet() bool {
+	return q.isCursorSet
+}
+
+func (q *linkQuery) GetCursor() string {
+	if q.IsCursorSet() {
+		return q.cursor
+	}
+
+	return ""
+}
+
+func (q *linkQuery) SetCursor(cursor string) LinkQueryInterface {
+	q.isCursorSet = true
+	q.cursor = cursor
+	return q
+}
+
 func (q *linkQuery) IsFeedIDSet() bool {
 	return q.isFeedIDSet
 }
@@ -339,6 +581,24 @@ func (q *linkQuery) SetFeedID(feedID string) LinkQueryInterface {
 	return q
 }
 
+func (q *linkQuery) IsGUIDSet() bool {
+	return q.isGUIDSet
+}
+
+func (q *linkQuery) GetGUID() string {
+	if q.IsGUIDSet() {
+		return q.guid
+	}
+
+	return ""
+}
+
+func (q *linkQuery) SetGUID(guid string) LinkQueryInterface {
+	q.isGUIDSet = true
+	q.guid = guid
+	return q
+}
+
 func (q *linkQuery) IsIDSet() bool {
 	return q.isIDSet
 }
@@ -464,6 +724,96 @@ func (q *linkQuery) SetOrderBy(orderBy string) LinkQueryInterface {
 	return q
 }
 
+func (q *linkQuery) IsRankingSet() bool {
+	return q.isRankingSet
+}
+
+func (q *linkQuery) GetRanking() LinkRanking {
+	if q.IsRankingSet() {
+		return q.ranking
+	}
+
+	return LinkRanking{}
+}
+
+func (q *linkQuery) SetRanking(ranking LinkRanking) LinkQueryInterface {
+	q.isRankingSet = true
+	q.ranking = ranking
+	return q
+}
+
+func (q *linkQuery) IsReportCountGteSet() bool {
+	return q.isReportCountGteSet
+}
+
+func (q *linkQuery) GetReportCountGte() int64 {
+	if q.IsReportCountGteSet() {
+		return q.reportCountGte
+	}
+
+	return 0
+}
+
+func (q *linkQuery) SetReportCountGte(reportCount int64) LinkQueryInterface {
+	q.isReportCountGteSet = true
+	q.reportCountGte = reportCount
+	return q
+}
+
+func (q *linkQuery) IsReportCountLteSet() bool {
+	return q.isReportCountLteSet
+}
+
+func (q *linkQuery) GetReportCountLte() int64 {
+	if q.IsReportCountLteSet() {
+		return q.reportCountLte
+	}
+
+	return 0
+}
+
+func (q *linkQuery) SetReportCountLte(reportCount int64) LinkQueryInterface {
+	q.isReportCountLteSet = true
+	q.reportCountLte = reportCount
+	return q
+}
+
+func (q *linkQuery) IsReportedAtGteSet() bool {
+	return q.isReportedAtGteSet
+}
+
+func (q *linkQuery) GetReportedAtGte() string {
+	if q.IsReportedAtGteSet() {
+		return q.reportedAtGte
+	}
+
+	return ""
+}
+
+func (q *linkQuery) SetReportedAtGte(reportedAt string) LinkQueryInterface {
+	q.isReportedAtGteSet = true
+	q.reportedAtGte = reportedAt
+	return q
+}
+
+func (q *linkQuery) IsReportedAtLteSet() bool {
+	return q.isReportedAtLteSet
+}
+
+func (q *linkQuery) GetReportedAtLte() string {
+	if q.IsReportedAtLteSet() {
+		return q.reportedAtLte
+	}
+
+	return ""
+}
+
+func (q *linkQuery) SetReportedAtLte(reportedAt string) LinkQueryInterface {
+	q.isReportedAtLteSet = true
+	q.reportedAtLte = reportedAt
+	return q
+}
+
 func (q *linkQuery) IsStatusSet() bool {
 	return q.isStatusSet
 }
@@ -552,6 +902,114 @@ func (q *linkQuery) SetURL(url string) LinkQueryInterface {
 	return q
 }
 
+func (q *linkQuery) IsViewsGteSet() bool {
+	return q.isViewsGteSet
+}
+
+func (q *linkQuery) GetViewsGte() int64 {
+	if q.IsViewsGteSet() {
+		return q.viewsGte
+	}
+
+	return 0
+}
+
+func (q *linkQuery) SetViewsGte(views int64) LinkQueryInterface {
+	q.isViewsGteSet = true
+	q.viewsGte = views
+	return q
+}
+
+func (q *linkQuery) IsViewsLteSet() bool {
+	return q.isViewsLteSet
+}
+
+func (q *linkQuery) GetViewsLte() int64 {
+	if q.IsViewsLteSet() {
+		return q.viewsLte
+	}
+
+	return 0
+}
+
+func (q *linkQuery) SetViewsLte(views int64) LinkQueryInterface {
+	q.isViewsLteSet = true
+	q.viewsLte = views
+	return q
+}
+
+func (q *linkQuery) IsVotesDownGteSet() bool {
+	return q.isVotesDownGteSet
+}
+
+func (q *linkQuery) GetVotesDownGte() int64 {
+	if q.IsVotesDownGteSet() {
+		return q.votesDownGte
+	}
+
+	return 0
+}
+
+func (q *linkQuery) SetVotesDownGte(votesDown int64) LinkQueryInterface {
+	q.isVotesDownGteSet = true
+	q.votesDownGte = votesDown
+	return q
+}
+
+func (q *linkQuery) IsVotesDownLteSet() bool {
+	return q.isVotesDownLteSet
+}
+
+func (q *linkQuery) GetVotesDownLte() int64 {
+	if q.IsVotesDownLteSet() {
+		return q.votesDownLte
+	}
+
+	return 0
+}
+
+func (q *linkQuery) SetVotesDownLte(votesDown int64) LinkQueryInterface {
+	q.isVotesDownLteSet = true
+	q.votesDownLte = votesDown
+	return q
+}
+
+func (q *linkQuery) IsVotesUpGteSet() bool {
+	return q.isVotesUpGteSet
+}
+
+func (q *linkQuery) GetVotesUpGte() int64 {
+	if q.IsVotesUpGteSet() {
+		return q.votesUpGte
+	}
+
+	return 0
+}
+
+func (q *linkQuery) SetVotesUpGte(votesUp int64) LinkQueryInterface {
+	q.isVotesUpGteSet = true
+	q.votesUpGte = votesUp
+	return q
+}
+
+func (q *linkQuery) IsVotesUpLteSet() bool {
+	return q.isVotesUpLteSet
+}
+
+func (q *linkQuery) GetVotesUpLte() int64 {
+	if q.IsVotesUpLteSet() {
+		return q.votesUpLte
+	}
+
+	return 0
+}
+
+func (q *linkQuery) SetVotesUpLte(votesUp int64) LinkQueryInterface {
+	q.isVotesUpLteSet = true
+	q.votesUpLte = votesUp
+	return q
+}
+
 func (q *linkQuery) IsWithSoftDeletedSet() bool {
 	return q.isWithSoftDeletedSet
 }
@@ -26,6 +26,14 @@ type LinkQueryInterface interface {
 
 	// Field query methods
 
+	IsCheckedAtGteSet() bool
+	GetCheckedAtGte() string
+	SetCheckedAtGte(checkedAt string) LinkQueryInterface
+
+	IsCheckedAtLteSet() bool
+	GetCheckedAtLte() string
+	SetCheckedAtLte(checkedAt string) LinkQueryInterface
+
 	IsCreatedAtGteSet() bool
 	GetCreatedAtGte() string
 	SetCreatedAtGte(createdAt string) LinkQueryInterface
@@ -34,10 +42,20 @@ type LinkQueryInterface interface {
 	GetCreatedAtLte() string
 	SetCreatedAtLte(createdAt string) LinkQueryInterface
 
+	// IsCursorSet, GetCursor and SetCursor page the links after the cursor
+	// returned by LinkListPage, instead of by offset
+	IsCursorSet() bool
+	GetCursor() string
+	SetCursor(cursor string) LinkQueryInterface
+
 	IsFeedIDSet() bool
 	GetFeedID() string
 	SetFeedID(feedID string) LinkQueryInterface
 
+	IsGUIDSet() bool
+	GetGUID() string
+	SetGUID(guid string) LinkQueryInterface
+
 	IsIDSet() bool
 	GetID() string
 	SetID(id string) LinkQueryInterface
@@ -62,9 +80,34 @@ type LinkQueryInterface interface {
 	GetOrderDirection() string
 	SetOrderDirection(orderDirection string) LinkQueryInterface
 
+	// IsRankingSet, GetRanking and SetRanking order the links by their hot
+	// ranking score, highest first, instead of by a column
+	IsRankingSet() bool
+	GetRanking() LinkRanking
+	SetRanking(ranking LinkRanking) LinkQueryInterface
+
+	IsReportCountGteSet() bool
+	GetReportCountGte() int64
+	SetReportCountGte(reportCount int64) LinkQueryInterface
+
+	IsReportCountLteSet() bool
+	GetReportCountLte() int64
+	SetReportCountLte(reportCount int64) LinkQueryInterface
+
+	IsReportedAtGteSet() bool
+	GetReportedAtGte() string
+	SetReportedAtGte(reportedAt string) LinkQueryInterface
+
+	IsReportedAtLteSet() bool
+	GetReportedAtLte() string
+	SetReportedAtLte(reportedAt string) LinkQueryInterface
+
 	IsStatusSet() bool
 	GetStatus() string
 	SetStatus(status string) LinkQueryInterface
+
+	IsStatusInSet() bool
+	GetStatusIn() []string
 	SetStatusIn(statuses []string) LinkQueryInterface
 
 	IsURLSet() bool
@@ -78,4 +121,28 @@ type LinkQueryInterface interface {
 	IsUpdatedAtLteSet() bool
 	GetUpdatedAtLte() string
 	SetUpdatedAtLte(updatedAt string) LinkQueryInterface
+
+	IsViewsGteSet() bool
+	GetViewsGte() int64
+	SetViewsGte(views int64) LinkQueryInterface
+
+	IsViewsLteSet() bool
+	GetViewsLte() int64
+	SetViewsLte(views int64) LinkQueryInterface
+
+	IsVotesDownGteSet() bool
+	GetVotesDownGte() int64
+	SetVotesDownGte(votesDown int64) LinkQueryInterface
+
+	IsVotesDownLteSet() bool
+	GetVotesDownLte() int64
+	SetVotesDownLte(votesDown int64) LinkQueryInterface
+
+	IsVotesUpGteSet() bool
+	GetVotesUpGte() int64
+	SetVotesUpGte(votesUp int64) LinkQueryInterface
+
+	IsVotesUpLteSet() bool
+	GetVotesUpLte() int64
+	SetVotesUpLte(votesUp int64) LinkQueryInterface
 }
@@ -0,0 +1,130 @@
+package feedstore
+
+import (
+	"math"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/dracory/sb"
+	"github.com/dromara/carbon/v2"
+)
+
+// LinkRanking configures the hot ranking of links, in the style of Hacker
+// News: the points of a link decay with its age, so new links with a few
+// votes rank above old links with many.
+//
+//	points = VotesUpWeight * votes_up - VotesDownWeight * votes_down + ViewsWeight * views
+//	score  = points / (age_hours + AgeOffsetHours) ^ Gravity
+//
+// The age is measured from the time of the link, or from its creation time
+// when the time is unknown.
+type LinkRanking struct {
+	VotesUpWeight   float64
+	VotesDownWeight float64
+	ViewsWeight     float64
+
+	// Gravity sets how fast the score decays with age, 0 ranks by points
+	Gravity float64
+
+	// AgeOffsetHours is added to the age, so new links do not rank
+	// infinitely high. It must be positive.
+	AgeOffsetHours float64
+
+	// Now is the datetime the ages are measured at, the current time when
+	// empty
+	Now string
+}
+
+// DefaultLinkRanking returns the ranking of Hacker News: up votes minus down
+// votes, with a gravity of 1.8 and an age offset of 2 hours
+func DefaultLinkRanking() LinkRanking {
+	return LinkRanking{
+		VotesUpWeight:   1,
+		VotesDownWeight: 1,
+		ViewsWeight:     0,
+		Gravity:         1.8,
+		AgeOffsetHours:  2,
+	}
+}
+
+// Validate checks the ranking settings
+func (r LinkRanking) Validate() error {
+	for _, weight := range []float64{r.VotesUpWeight, r.VotesDownWeight, r.ViewsWeight, r.Gravity, r.AgeOffsetHours} {
+		if math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return newValidationError("ranking", "link query: ranking settings must be finite numbers")
+		}
+	}
+
+	if r.Gravity < 0 {
+		return newValidationError("ranking", "link query: ranking gravity cannot be negative")
+	}
+
+	if r.AgeOffsetHours <= 0 {
+		return newValidationError("ranking", "link query: ranking age offset must be positive")
+	}
+
+	if r.Now != "" && carbon.Parse(r.Now, carbon.UTC).IsInvalid() {
+		return newValidationError("ranking", "link query: ranking now is not a valid datetime")
+	}
+
+	return nil
+}
+
+// NowOrCurrent returns the datetime the ages are measured at
+func (r LinkRanking) NowOrCurrent() string {
+	if r.Now == "" {
+		return carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+	}
+
+	return carbon.Parse(r.Now, carbon.UTC).ToDateTimeString(carbon.UTC)
+}
+
+// Score returns the score of a link with the given counts and age, as
+// computed by the SQL expression of the ranking
+func (r LinkRanking) Score(votesUp int64, votesDown int64, views int64, ageHours float64) float64 {
+	points := r.VotesUpWeight*float64(votesUp) - r.VotesDownWeight*float64(votesDown) + r.ViewsWeight*float64(views)
+
+	return points / math.Pow(max(ageHours, 0)+r.AgeOffsetHours, r.Gravity)
+}
+
+// linkRankingScore returns the SQL expression of the score of a link, for
+// the dialect.
+//
+// On SQLite the expression needs the math functions for POWER. They are
+// built into modernc.org/sqlite, mattn/go-sqlite3 needs the
+// sqlite_math_functions build tag.
+func linkRankingScore(dialect string, r LinkRanking) exp.LiteralExpression {
+	now := r.NowOrCurrent()
+
+	// The time of the link, or its creation time when unknown
+	linkTime := goqu.L("CASE WHEN ? > ? THEN ? ELSE ? END",
+		goqu.C(COLUMN_TIME), sb.NULL_DATETIME, goqu.C(COLUMN_TIME), goqu.C(COLUMN_CREATED_AT))
+
+	var ageHours exp.LiteralExpression
+
+	switch dialect {
+	case sb.DIALECT_MYSQL:
+		ageHours = goqu.L("GREATEST(TIMESTAMPDIFF(SECOND, ?, ?) / 3600.0, 0)", linkTime, now)
+	case sb.DIALECT_POSTGRES:
+		ageHours = goqu.L("GREATEST(EXTRACT(EPOCH FROM (CAST(? AS TIMESTAMP) - ?)) / 3600.0, 0)", now, linkTime)
+	default:
+		ageHours = goqu.L("MAX((julianday(?) - julianday(?)) * 24.0, 0)", now, linkTime)
+	}
+
+	// PostgreSQL infers the type of a parameter from the column it is
+	// multiplied by, which would truncate fractional weights
+	number := func(value float64) any {
+		if dialect == sb.DIALECT_POSTGRES {
+			return goqu.L("CAST(? AS DOUBLE PRECISION)", value)
+		}
+
+		return value
+	}
+
+	points := goqu.L("(? * ? - ? * ? + ? * ?)",
+		number(r.VotesUpWeight), goqu.C(COLUMN_VOTES_UP),
+		number(r.VotesDownWeight), goqu.C(COLUMN_VOTES_DOWN),
+		number(r.ViewsWeight), goqu.C(COLUMN_VIEWS))
+
+	return goqu.L("? / POWER(? + ?, ?)", points, ageHours, number(r.AgeOffsetHours), number(r.Gravity))
+}
@@ -0,0 +1,177 @@
+// Package memstore implements feedstore.StoreInterface in memory.
+//
+// It is meant for the tests of code which depends on the store. It honours
+// the query semantics of the SQL store (filters, ordering, limit and offset,
+// soft delete visibility and counts) and the same constraints (unique IDs,
+// and unique GUIDs per feed), and is verified by the same conformance tests,
+// without the cost of a database.
+package memstore
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/dracory/feedstore"
+	"github.com/samber/lo"
+)
+
+// DRIVER_NAME is the driver name reported by the in-memory store
+const DRIVER_NAME = "memory"
+
+// NewStoreOptions define the options for creating a new in-memory store
+type NewStoreOptions struct {
+	// FeedTableName and LinkTableName are only reported by the getters,
+	// they default to "feeds" and "links"
+	FeedTableName string
+	LinkTableName string
+
+	// CascadeMode, CascadeRestoreEnabled and ModerationAutoHideThreshold
+	// behave as the options of feedstore.NewStore with the same names
+	CascadeMode                 string
+	CascadeRestoreEnabled       bool
+	ModerationAutoHideThreshold int64
+}
+
+// NewStore creates a new, empty, in-memory store
+func NewStore(opts NewStoreOptions) (feedstore.StoreInterface, error) {
+	if opts.FeedTableName == "" {
+		opts.FeedTableName = "feeds"
+	}
+
+	if opts.LinkTableName == "" {
+		opts.LinkTableName = "links"
+	}
+
+	if opts.CascadeMode == "" {
+		opts.CascadeMode = feedstore.CASCADE_NONE
+	}
+
+	if !lo.Contains([]string{feedstore.CASCADE_NONE, feedstore.CASCADE_SOFT, feedstore.CASCADE_HARD}, opts.CascadeMode) {
+		return nil, validationError("CascadeMode", "memory store: CascadeMode must be one of none, soft or hard")
+	}
+
+	if opts.ModerationAutoHideThreshold < 0 {
+		return nil, validationError("ModerationAutoHideThreshold", "memory store: ModerationAutoHideThreshold cannot be negative")
+	}
+
+	return &storeImplementation{
+		db:                    &database{},
+		feedTableName:         opts.FeedTableName,
+		linkTableName:         opts.LinkTableName,
+		cascadeMode:           opts.CascadeMode,
+		cascadeRestoreEnabled: opts.CascadeRestoreEnabled,
+
+		moderationAutoHideThreshold: opts.ModerationAutoHideThreshold,
+	}, nil
+}
+
+// tables holds the rows of the store in insertion order. Rows are never
+// changed in place but replaced, so copies of the slices are a snapshot.
+type tables struct {
+	feeds []map[string]string
+	links []map[string]string
+}
+
+// database is the state shared by a store and its transaction copies
+type database struct {
+	mu     sync.Mutex
+	tables tables
+}
+
+type storeImplementation struct {
+	db *database
+
+	// tx is the working copy of the tables, set within a transaction
+	tx *tables
+
+	feedTableName         string
+	linkTableName         string
+	cascadeMode           string
+	cascadeRestoreEnabled bool
+	debugEnabled          bool
+
+	moderationAutoHideThreshold int64
+}
+
+var _ feedstore.StoreInterface = (*storeImplementation)(nil) // verify it extends the interface
+
+// atomically runs fn with a copy of the store bound to a working copy of
+// the tables, which replaces the tables only when fn succeeds. Operations
+// are serialized; within a transaction fn runs with the store itself.
+func (st *storeImplementation) atomically(ctx context.Context, fn func(txStore *storeImplementation) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if st.tx != nil {
+		return fn(st)
+	}
+
+	st.db.mu.Lock()
+	defer st.db.mu.Unlock()
+
+	working := tables{
+		feeds: slices.Clone(st.db.tables.feeds),
+		links: slices.Clone(st.db.tables.links),
+	}
+
+	txStore := *st
+	txStore.tx = &working
+
+	if err := fn(&txStore); err != nil {
+		return err
+	}
+
+	st.db.tables = working
+
+	return nil
+}
+
+// AutoMigrate does nothing, the in-memory store has no schema
+func (st *storeImplementation) AutoMigrate() error {
+	return nil
+}
+
+// EnableDebug is accepted for compatibility, nothing is logged
+func (st *storeImplementation) EnableDebug(debug bool) {
+	st.debugEnabled = debug
+}
+
+// Migrate does nothing, the in-memory store has no schema
+func (st *storeImplementation) Migrate(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// MigrationsPending returns no migrations, the in-memory store has no schema
+func (st *storeImplementation) MigrationsPending(ctx context.Context) ([]feedstore.MigrationInfo, error) {
+	return []feedstore.MigrationInfo{}, ctx.Err()
+}
+
+func (st *storeImplementation) GetDriverName() string {
+	return DRIVER_NAME
+}
+
+func (st *storeImplementation) GetFeedTableName() string {
+	return st.feedTableName
+}
+
+func (st *storeImplementation) GetLinkTableName() string {
+	return st.linkTableName
+}
+
+// WithTx runs fn with a store whose operations all take part in the same
+// transaction. The changes of fn are kept when it returns nil, and
+// discarded when it returns an error or panics.
+//
+// Other operations on the store wait until fn returns, so fn must only use
+// the store it is passed.
+func (st *storeImplementation) WithTx(ctx context.Context, fn func(tx feedstore.StoreInterface) error) error {
+	if fn == nil {
+		return validationError("fn", "transaction function is nil")
+	}
+
+	return st.atomically(ctx, func(txStore *storeImplementation) error {
+		return fn(txStore)
+	})
+}
@@ -0,0 +1,48 @@
+package memstore_test
+
+import (
+	"testing"
+
+	"github.com/dracory/feedstore"
+	"github.com/dracory/feedstore/feedstoretest"
+	"github.com/dracory/feedstore/memstore"
+)
+
+func TestStoreConformance(t *testing.T) {
+	feedstoretest.RunStoreTests(t, func(t *testing.T, options feedstoretest.StoreOptions) feedstore.StoreInterface {
+		store, err := memstore.NewStore(memstore.NewStoreOptions{
+			CascadeMode:           options.CascadeMode,
+			CascadeRestoreEnabled: options.CascadeRestoreEnabled,
+		})
+		if err != nil {
+			t.Fatalf("NewStore should not return an error, but got: %v", err)
+		}
+
+		return store
+	})
+}
+
+func TestStoreAutoHide(t *testing.T) {
+	feedstoretest.RunAutoHideTests(t, func(t *testing.T, threshold int64) feedstore.StoreInterface {
+		store, err := memstore.NewStore(memstore.NewStoreOptions{ModerationAutoHideThreshold: threshold})
+		if err != nil {
+			t.Fatalf("NewStore should not return an error, but got: %v", err)
+		}
+
+		return store
+	})
+}
+
+func TestNewStoreInvalidCascadeMode(t *testing.T) {
+	_, err := memstore.NewStore(memstore.NewStoreOptions{CascadeMode: "unknown"})
+	if err == nil {
+		t.Fatal("NewStore with an unknown cascade mode should return an error")
+	}
+}
+
+func TestNewStoreNegativeAutoHideThreshold(t *testing.T) {
+	_, err := memstore.NewStore(memstore.NewStoreOptions{ModerationAutoHideThreshold: -1})
+	if err == nil {
+		t.Fatal("NewStore with a negative auto hide threshold should return an error")
+	}
+}
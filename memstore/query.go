@@ -0,0 +1,515 @@
+package memstore
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dracory/feedstore"
+	"github.com/dracory/sb"
+	"github.com/dromara/carbon/v2"
+)
+
+// linkDefaultLimit is the number of links listed when the query sets no
+// limit, as by the SQL store
+const linkDefaultLimit = 1000
+
+// numericColumns are compared as numbers, as the SQL store keeps them in
+// INTEGER columns
+var numericColumns = map[string]bool{
+	feedstore.COLUMN_FETCH_INTERVAL:      true,
+	feedstore.COLUMN_REPORT_COUNT:        true,
+	feedstore.COLUMN_RETENTION_MAX_AGE:   true,
+	feedstore.COLUMN_RETENTION_MAX_ITEMS: true,
+	feedstore.COLUMN_VIEWS:               true,
+	feedstore.COLUMN_VOTES_DOWN:          true,
+	feedstore.COLUMN_VOTES_UP:            true,
+}
+
+// condition reports whether a row matches a query filter
+type condition func(row map[string]string) bool
+
+// selection is a query translated to conditions and paging
+type selection struct {
+	conditions  []condition
+	softDeleted condition
+	orderBy     string
+	descending  bool
+	thenByID    bool
+	score       func(row map[string]string) float64
+	compare     func(a, b map[string]string) int
+	limit       int
+	offset      int
+}
+
+// feedSelection translates the feed query
+func feedSelection(q feedstore.FeedQueryInterface) (selection, error) {
+	if err := q.Validate(); err != nil {
+		return selection{}, err
+	}
+
+	s := selection{limit: -1}
+
+	if q.IsCategorySet() {
+		s.where(eq(feedstore.COLUMN_CATEGORY, q.GetCategory()))
+	}
+
+	if q.IsCreatedAtGteSet() {
+		s.where(gte(feedstore.COLUMN_CREATED_AT, q.GetCreatedAtGte()))
+	}
+
+	if q.IsCreatedAtLteSet() {
+		s.where(lte(feedstore.COLUMN_CREATED_AT, q.GetCreatedAtLte()))
+	}
+
+	if q.IsIDSet() {
+		s.where(eq(feedstore.COLUMN_ID, q.GetID()))
+	}
+
+	if q.IsIDInSet() {
+		s.where(in(feedstore.COLUMN_ID, q.GetIDIn()))
+	}
+
+	if q.IsLastFetchedAtGteSet() {
+		s.where(gte(feedstore.COLUMN_LAST_FETCHED_AT, q.GetLastFetchedAtGte()))
+	}
+
+	if q.IsLastFetchedAtLteSet() {
+		s.where(lte(feedstore.COLUMN_LAST_FETCHED_AT, q.GetLastFetchedAtLte()))
+	}
+
+	if q.IsNextFetchAtGteSet() {
+		s.where(gte(feedstore.COLUMN_NEXT_FETCH_AT, q.GetNextFetchAtGte()))
+	}
+
+	if q.IsNextFetchAtLteSet() {
+		s.where(lte(feedstore.COLUMN_NEXT_FETCH_AT, q.GetNextFetchAtLte()))
+	}
+
+	if q.IsStatusSet() {
+		s.where(eq(feedstore.COLUMN_STATUS, q.GetStatus()))
+	}
+
+	if q.IsStatusInSet() {
+		s.where(in(feedstore.COLUMN_STATUS, q.GetStatusIn()))
+	}
+
+	if q.IsUpdatedAtGteSet() {
+		s.where(gte(feedstore.COLUMN_UPDATED_AT, q.GetUpdatedAtGte()))
+	}
+
+	if q.IsUpdatedAtLteSet() {
+		s.where(lte(feedstore.COLUMN_UPDATED_AT, q.GetUpdatedAtLte()))
+	}
+
+	if q.IsURLSet() {
+		s.where(eq(feedstore.COLUMN_URL, q.GetURL()))
+	}
+
+	s.softDeleted = softDeleted(
+		q.IsOnlySoftDeletedSet() && q.GetOnlySoftDeleted(),
+		q.IsWithSoftDeletedSet() && q.GetWithSoftDeleted(),
+	)
+
+	if q.IsOrderBySet() {
+		s.orderBy = q.GetOrderBy()
+		s.descending = !strings.EqualFold(q.GetOrderDirection(), sb.ASC)
+	}
+
+	if !q.IsCountOnlySet() || !q.GetCountOnly() {
+		if q.IsLimitSet() {
+			s.limit = limitOrNone(q.GetLimit())
+		}
+
+		if q.IsOffsetSet() {
+			s.offset = q.GetOffset()
+		}
+	}
+
+	return s, nil
+}
+
+// linkSelection translates the link query
+func linkSelection(q feedstore.LinkQueryInterface) (selection, error) {
+	if err := q.Validate(); err != nil {
+		return selection{}, err
+	}
+
+	s := selection{limit: linkDefaultLimit}
+
+	if q.IsCheckedAtGteSet() {
+		s.where(gte(feedstore.COLUMN_CHECKED_AT, q.GetCheckedAtGte()))
+	}
+
+	if q.IsCheckedAtLteSet() {
+		s.where(lte(feedstore.COLUMN_CHECKED_AT, q.GetCheckedAtLte()))
+	}
+
+	if q.IsCreatedAtGteSet() {
+		s.where(gte(feedstore.COLUMN_CREATED_AT, q.GetCreatedAtGte()))
+	}
+
+	if q.IsCreatedAtLteSet() {
+		s.where(lte(feedstore.COLUMN_CREATED_AT, q.GetCreatedAtLte()))
+	}
+
+	if q.IsFeedIDSet() {
+		s.where(eq(feedstore.COLUMN_FEED_ID, q.GetFeedID()))
+	}
+
+	if q.IsGUIDSet() {
+		s.where(eq(feedstore.COLUMN_GUID, q.GetGUID()))
+	}
+
+	if q.IsIDSet() {
+		s.where(eq(feedstore.COLUMN_ID, q.GetID()))
+	}
+
+	if q.IsIDInSet() {
+		s.where(in(feedstore.COLUMN_ID, q.GetIDIn()))
+	}
+
+	if q.IsReportCountGteSet() {
+		s.where(gte(feedstore.COLUMN_REPORT_COUNT, strconv.FormatInt(q.GetReportCountGte(), 10)))
+	}
+
+	if q.IsReportCountLteSet() {
+		s.where(lte(feedstore.COLUMN_REPORT_COUNT, strconv.FormatInt(q.GetReportCountLte(), 10)))
+	}
+
+	if q.IsReportedAtGteSet() {
+		s.where(gte(feedstore.COLUMN_REPORTED_AT, q.GetReportedAtGte()))
+	}
+
+	if q.IsReportedAtLteSet() {
+		s.where(lte(feedstore.COLUMN_REPORTED_AT, q.GetReportedAtLte()))
+	}
+
+	if q.IsStatusSet() {
+		s.where(eq(feedstore.COLUMN_STATUS, q.GetStatus()))
+	}
+
+	if q.IsStatusInSet() {
+		s.where(in(feedstore.COLUMN_STATUS, q.GetStatusIn()))
+	}
+
+	if q.IsURLSet() {
+		s.where(eq(feedstore.COLUMN_URL, q.GetURL()))
+	}
+
+	if q.IsUpdatedAtGteSet() {
+		s.where(gte(feedstore.COLUMN_UPDATED_AT, q.GetUpdatedAtGte()))
+	}
+
+	if q.IsUpdatedAtLteSet() {
+		s.where(lte(feedstore.COLUMN_UPDATED_AT, q.GetUpdatedAtLte()))
+	}
+
+	if q.IsViewsGteSet() {
+		s.where(gte(feedstore.COLUMN_VIEWS, strconv.FormatInt(q.GetViewsGte(), 10)))
+	}
+
+	if q.IsViewsLteSet() {
+		s.where(lte(feedstore.COLUMN_VIEWS, strconv.FormatInt(q.GetViewsLte(), 10)))
+	}
+
+	if q.IsVotesDownGteSet() {
+		s.where(gte(feedstore.COLUMN_VOTES_DOWN, strconv.FormatInt(q.GetVotesDownGte(), 10)))
+	}
+
+	if q.IsVotesDownLteSet() {
+		s.where(lte(feedstore.COLUMN_VOTES_DOWN, strconv.FormatInt(q.GetVotesDownLte(), 10)))
+	}
+
+	if q.IsVotesUpGteSet() {
+		s.where(gte(feedstore.COLUMN_VOTES_UP, strconv.FormatInt(q.GetVotesUpGte(), 10)))
+	}
+
+	if q.IsVotesUpLteSet() {
+		s.where(lte(feedstore.COLUMN_VOTES_UP, strconv.FormatInt(q.GetVotesUpLte(), 10)))
+	}
+
+	s.softDeleted = softDeleted(
+		q.IsOnlySoftDeletedSet() && q.GetOnlySoftDeleted(),
+		q.IsWithSoftDeletedSet() && q.GetWithSoftDeleted(),
+	)
+
+	if q.IsOrderBySet() {
+		s.orderBy = q.GetOrderBy()
+		s.descending = !strings.EqualFold(q.GetOrderDirection(), sb.ASC)
+	}
+
+	if q.IsRankingSet() {
+		s.score = rankingScore(q.GetRanking())
+	}
+
+	if q.IsCursorSet() {
+		cursor, err := feedstore.DecodeLinkCursor(q.GetCursor())
+
+		if err != nil {
+			return selection{}, err
+		}
+
+		s.keyset(q)
+		s.where(after(cursor, s.descending))
+	}
+
+	if !q.IsCountOnlySet() || !q.GetCountOnly() {
+		if q.IsLimitSet() {
+			s.limit = limitOrNone(q.GetLimit())
+		}
+
+		if q.IsOffsetSet() {
+			s.offset = q.GetOffset()
+		}
+	} else {
+		s.limit = -1
+	}
+
+	return s, nil
+}
+
+// keyset orders the selection as links are paged with cursors
+func (s *selection) keyset(q feedstore.LinkQueryInterface) {
+	orderBy, orderDirection := feedstore.LinkKeysetOrder(q)
+
+	s.orderBy = orderBy
+	s.descending = orderDirection != sb.ASC
+	s.thenByID = true
+}
+
+// where adds a condition to the selection
+func (s *selection) where(c condition) {
+	s.conditions = append(s.conditions, c)
+}
+
+// onlySoftDeleted returns the selection of all the soft deleted rows
+// matching the conditions, unordered and not paged, as restored by query
+func (s selection) onlySoftDeleted() selection {
+	return selection{
+		conditions:  s.conditions,
+		softDeleted: softDeleted(true, false),
+		limit:       -1,
+	}
+}
+
+// matches reports whether the row matches all conditions
+func (s selection) matches(row map[string]string) bool {
+	if s.softDeleted != nil && !s.softDeleted(row) {
+		return false
+	}
+
+	for _, c := range s.conditions {
+		if !c(row) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// apply returns the matching rows, ordered and paged. Rows compare equal
+// keep their insertion order. A negative limit means no limit.
+func (s selection) apply(rows []map[string]string) []map[string]string {
+	result := []map[string]string{}
+
+	for _, row := range rows {
+		if s.matches(row) {
+			result = append(result, row)
+		}
+	}
+
+	if s.score != nil {
+		scores := make(map[string]float64, len(result))
+
+		for _, row := range result {
+			scores[row[feedstore.COLUMN_ID]] = s.score(row)
+		}
+
+		slices.SortStableFunc(result, func(a, b map[string]string) int {
+			if c := cmp.Compare(scores[b[feedstore.COLUMN_ID]], scores[a[feedstore.COLUMN_ID]]); c != 0 {
+				return c
+			}
+
+			return strings.Compare(b[feedstore.COLUMN_ID], a[feedstore.COLUMN_ID])
+		})
+	} else if s.compare != nil {
+		slices.SortStableFunc(result, s.compare)
+	} else if s.orderBy != "" {
+		slices.SortStableFunc(result, func(a, b map[string]string) int {
+			c := compareValues(s.orderBy, a[s.orderBy], b[s.orderBy])
+
+			if c == 0 && s.thenByID {
+				c = strings.Compare(a[feedstore.COLUMN_ID], b[feedstore.COLUMN_ID])
+			}
+
+			if s.descending {
+				return -c
+			}
+
+			return c
+		})
+	}
+
+	if s.offset > 0 {
+		result = result[min(s.offset, len(result)):]
+	}
+
+	if s.limit >= 0 && s.limit < len(result) {
+		result = result[:s.limit]
+	}
+
+	return result
+}
+
+// limitOrNone returns the limit, or -1 (no limit) for a zero limit as the
+// SQL store omits the LIMIT clause then
+func limitOrNone(limit int) int {
+	if limit == 0 {
+		return -1
+	}
+
+	return limit
+}
+
+func eq(column string, value string) condition {
+	return func(row map[string]string) bool {
+		return row[column] == value
+	}
+}
+
+func in(column string, values []string) condition {
+	return func(row map[string]string) bool {
+		return slices.Contains(values, row[column])
+	}
+}
+
+func gte(column string, value string) condition {
+	return func(row map[string]string) bool {
+		return compareValues(column, row[column], value) >= 0
+	}
+}
+
+func lte(column string, value string) condition {
+	return func(row map[string]string) bool {
+		return compareValues(column, row[column], value) <= 0
+	}
+}
+
+// after returns the condition selecting the rows after the cursor, in the
+// keyset order
+func after(cursor feedstore.LinkCursor, descending bool) condition {
+	return func(row map[string]string) bool {
+		c := 0
+
+		if cursor.OrderBy != feedstore.COLUMN_ID {
+			c = compareValues(cursor.OrderBy, row[cursor.OrderBy], cursor.Value)
+		}
+
+		if c == 0 {
+			c = strings.Compare(row[feedstore.COLUMN_ID], cursor.ID)
+		}
+
+		if descending {
+			return c < 0
+		}
+
+		return c > 0
+	}
+}
+
+// rankingScore returns the hot ranking score of a row, as computed by the
+// SQL store
+func rankingScore(ranking feedstore.LinkRanking) func(row map[string]string) float64 {
+	now := carbon.Parse(ranking.NowOrCurrent(), carbon.UTC).StdTime()
+
+	return func(row map[string]string) float64 {
+		linkTime := row[feedstore.COLUMN_TIME]
+
+		if linkTime <= sb.NULL_DATETIME {
+			linkTime = row[feedstore.COLUMN_CREATED_AT]
+		}
+
+		ageHours := now.Sub(carbon.Parse(linkTime, carbon.UTC).StdTime()).Hours()
+
+		votesUp, _ := strconv.ParseInt(row[feedstore.COLUMN_VOTES_UP], 10, 64)
+		votesDown, _ := strconv.ParseInt(row[feedstore.COLUMN_VOTES_DOWN], 10, 64)
+		views, _ := strconv.ParseInt(row[feedstore.COLUMN_VIEWS], 10, 64)
+
+		return ranking.Score(votesUp, votesDown, views, ageHours)
+	}
+}
+
+// softDeleted returns the soft delete visibility condition: by default rows
+// soft deleted in the past are excluded
+func softDeleted(onlySoftDeleted bool, withSoftDeleted bool) condition {
+	now := now()
+
+	return func(row map[string]string) bool {
+		if onlySoftDeleted {
+			return row[feedstore.COLUMN_SOFT_DELETED_AT] <= now
+		}
+
+		if withSoftDeleted {
+			return true
+		}
+
+		return row[feedstore.COLUMN_SOFT_DELETED_AT] > now
+	}
+}
+
+// compareValues compares two values of the column, as numbers for numeric
+// columns, and as strings otherwise (datetimes are stored sortable)
+func compareValues(column string, a string, b string) int {
+	if numericColumns[column] {
+		x, errX := strconv.ParseInt(a, 10, 64)
+		y, errY := strconv.ParseInt(b, 10, 64)
+
+		if errX == nil && errY == nil {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	return strings.Compare(a, b)
+}
+
+// rowIndex returns the index of the row with the given ID, or -1
+func rowIndex(rows []map[string]string, id string) int {
+	return slices.IndexFunc(rows, func(row map[string]string) bool {
+		return row[feedstore.COLUMN_ID] == id
+	})
+}
+
+// rowReplace replaces the row at the index with a copy holding the changes
+func rowReplace(rows []map[string]string, index int, changes map[string]string) {
+	row := maps.Clone(rows[index])
+	maps.Copy(row, changes)
+	rows[index] = row
+}
+
+// now returns the current time as stored
+func now() string {
+	return carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+}
+
+func validationError(field string, message string) error {
+	return &feedstore.ValidationError{Field: field, Message: message}
+}
+
+func notFoundError(what string, id string) error {
+	return fmt.Errorf("%w: %s %s", feedstore.ErrNotFound, what, id)
+}
+
+func duplicateError(what string, key string) error {
+	return fmt.Errorf("%w: %s %s already exists", feedstore.ErrDuplicate, what, key)
+}
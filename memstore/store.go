@@ -0,0 +1,1230 @@
+package memstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dracory/feedstore"
+	"github.com/dracory/sb"
+	"github.com/dromara/carbon/v2"
+)
+
+// == FEEDS ===================================================================
+
+// FeedCount returns the total number of feeds matching the query filters
+func (st *storeImplementation) FeedCount(ctx context.Context, query feedstore.FeedQueryInterface) (int64, error) {
+	if query == nil {
+		query = feedstore.FeedQuery()
+	}
+
+	list, err := st.FeedList(ctx, query.SetCountOnly(true))
+
+	return int64(len(list)), err
+}
+
+func (st *storeImplementation) FeedCreate(ctx context.Context, feed feedstore.FeedInterface) error {
+	if feed == nil {
+		return validationError("feed", "feed is nil")
+	}
+
+	return st.FeedCreateMany(ctx, []feedstore.FeedInterface{feed})
+}
+
+// FeedCreateMany creates the feeds in a single transaction. All feeds get
+// the same created and updated time.
+func (st *storeImplementation) FeedCreateMany(ctx context.Context, feeds []feedstore.FeedInterface) error {
+	if len(feeds) == 0 {
+		return nil
+	}
+
+	now := now()
+
+	for _, feed := range feeds {
+		if feed == nil {
+			return validationError("feed", "feed is nil")
+		}
+
+		feed.SetCreatedAt(now)
+		feed.SetUpdatedAt(now)
+	}
+
+	err := st.atomically(ctx, func(txStore *storeImplementation) error {
+		for _, feed := range feeds {
+			if rowIndex(txStore.tx.feeds, feed.ID()) >= 0 {
+				return duplicateError("feed", feed.ID())
+			}
+
+			txStore.tx.feeds = append(txStore.tx.feeds, maps.Clone(feed.Data()))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, feed := range feeds {
+		feed.MarkAsNotDirty()
+	}
+
+	return nil
+}
+
+func (st *storeImplementation) FeedDelete(ctx context.Context, feed feedstore.FeedInterface) error {
+	if feed == nil {
+		return validationError("feed", "feed is nil")
+	}
+
+	return st.FeedDeleteByID(ctx, feed.ID())
+}
+
+// FeedDeleteByID deletes the feed with the given ID, and handles its links
+// according to the cascade mode of the store
+func (st *storeImplementation) FeedDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return validationError("id", "feed id is empty")
+	}
+
+	return st.atomically(ctx, func(txStore *storeImplementation) error {
+		switch txStore.cascadeMode {
+		case feedstore.CASCADE_HARD:
+			txStore.tx.links = slices.DeleteFunc(txStore.tx.links, func(row map[string]string) bool {
+				return row[feedstore.COLUMN_FEED_ID] == id
+			})
+		case feedstore.CASCADE_SOFT:
+			txStore.linksSoftDeleteByFeedID(id, now())
+		}
+
+		txStore.tx.feeds = slices.DeleteFunc(txStore.tx.feeds, func(row map[string]string) bool {
+			return row[feedstore.COLUMN_ID] == id
+		})
+
+		return nil
+	})
+}
+
+// FeedFindByID returns the feed with the given ID, or ErrNotFound.
+// Soft deleted feeds are only found with the WithSoftDeleted option.
+func (st *storeImplementation) FeedFindByID(ctx context.Context, id string, options ...feedstore.FindByIDOptions) (feedstore.FeedInterface, error) {
+	if id == "" {
+		return nil, validationError("id", "feed id is empty")
+	}
+
+	query := feedstore.FeedQuery().
+		SetID(id).
+		SetLimit(1)
+
+	if withSoftDeleted(options) {
+		query = query.SetWithSoftDeleted(true)
+	}
+
+	list, err := st.FeedList(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, notFoundError("feed", id)
+}
+
+func (st *storeImplementation) FeedList(ctx context.Context, query feedstore.FeedQueryInterface) ([]feedstore.FeedInterface, error) {
+	if query == nil {
+		query = feedstore.FeedQuery()
+	}
+
+	s, err := feedSelection(query)
+
+	if err != nil {
+		return []feedstore.FeedInterface{}, err
+	}
+
+	list := []feedstore.FeedInterface{}
+
+	err = st.atomically(ctx, func(txStore *storeImplementation) error {
+		for _, row := range s.apply(txStore.tx.feeds) {
+			list = append(list, feedstore.NewFeedFromExistingData(row))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return []feedstore.FeedInterface{}, err
+	}
+
+	return list, nil
+}
+
+// FeedIterate streams the feeds matching the query. The feeds are those
+// stored when the iteration starts, and the loop may use the store.
+func (st *storeImplementation) FeedIterate(ctx context.Context, query feedstore.FeedQueryInterface) iter.Seq2[feedstore.FeedInterface, error] {
+	return func(yield func(feedstore.FeedInterface, error) bool) {
+		if query == nil {
+			query = feedstore.FeedQuery()
+		}
+
+		s, err := feedSelection(query)
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for row, err := range st.iterateRows(ctx, s, func(t *tables) []map[string]string { return t.feeds }) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(feedstore.NewFeedFromExistingData(row), nil) {
+				return
+			}
+		}
+	}
+}
+
+// FeedRestore undoes the soft deletion of the feed. When cascade restore is
+// enabled, the links soft deleted together with the feed are restored too.
+func (st *storeImplementation) FeedRestore(ctx context.Context, feed feedstore.FeedInterface) error {
+	if feed == nil {
+		return validationError("feed", "feed is nil")
+	}
+
+	feed.SetSoftDeletedAt(sb.MAX_DATETIME)
+
+	return st.atomically(ctx, func(txStore *storeImplementation) error {
+		if err := txStore.FeedUpdate(ctx, feed); err != nil {
+			return err
+		}
+
+		if txStore.cascadeRestoreEnabled {
+			txStore.linksRestoreByFeedID(feed.ID())
+		}
+
+		return nil
+	})
+}
+
+// FeedRestoreByID undoes the soft deletion of the feed with the given ID
+func (st *storeImplementation) FeedRestoreByID(ctx context.Context, id string) error {
+	feed, err := st.FeedFindByID(ctx, id, feedstore.FindByIDOptions{WithSoftDeleted: true})
+
+	if err != nil {
+		return err
+	}
+
+	return st.FeedRestore(ctx, feed)
+}
+
+// FeedRestoreByQuery undoes the soft deletion of the soft deleted feeds
+// matching the query, and returns how many were restored. The limit, the
+// offset and the order of the query are ignored, and the query itself is
+// not changed.
+func (st *storeImplementation) FeedRestoreByQuery(ctx context.Context, query feedstore.FeedQueryInterface) (int64, error) {
+	if query == nil {
+		query = feedstore.FeedQuery()
+	}
+
+	s, err := feedSelection(query)
+
+	if err != nil {
+		return 0, err
+	}
+
+	s = s.onlySoftDeleted()
+
+	restored := int64(0)
+
+	err = st.atomically(ctx, func(txStore *storeImplementation) error {
+		list := []feedstore.FeedInterface{}
+
+		for _, row := range s.apply(txStore.tx.feeds) {
+			list = append(list, feedstore.NewFeedFromExistingData(row))
+		}
+
+		for _, feed := range list {
+			if err := txStore.FeedRestore(ctx, feed); err != nil {
+				return err
+			}
+		}
+
+		restored = int64(len(list))
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return restored, nil
+}
+
+// FeedSoftDelete soft deletes the feed. Unless the cascade mode of the store
+// is CASCADE_NONE, its links are soft deleted with the same time.
+func (st *storeImplementation) FeedSoftDelete(ctx context.Context, feed feedstore.FeedInterface) error {
+	if feed == nil {
+		return validationError("feed", "feed is nil")
+	}
+
+	feed.SetSoftDeletedAt(now())
+
+	return st.atomically(ctx, func(txStore *storeImplementation) error {
+		if err := txStore.FeedUpdate(ctx, feed); err != nil {
+			return err
+		}
+
+		if txStore.cascadeMode != feedstore.CASCADE_NONE {
+			txStore.linksSoftDeleteByFeedID(feed.ID(), feed.SoftDeletedAt())
+		}
+
+		return nil
+	})
+}
+
+func (st *storeImplementation) FeedSoftDeleteByID(ctx context.Context, id string) error {
+	feed, err := st.FeedFindByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return st.FeedSoftDelete(ctx, feed)
+}
+
+func (st *storeImplementation) FeedUpdate(ctx context.Context, feed feedstore.FeedInterface) error {
+	if feed == nil {
+		return validationError("feed", "feed is nil")
+	}
+
+	feed.SetUpdatedAt(now())
+
+	dataChanged := feed.DataChanged()
+
+	delete(dataChanged, feedstore.COLUMN_ID) // ID is not updateable
+
+	if len(dataChanged) <= 1 {
+		return nil // only the updated_at field is changed, no need to update
+	}
+
+	err := st.atomically(ctx, func(txStore *storeImplementation) error {
+		index := rowIndex(txStore.tx.feeds, feed.ID())
+
+		if index < 0 {
+			return notFoundError("feed", feed.ID())
+		}
+
+		rowReplace(txStore.tx.feeds, index, dataChanged)
+
+		return nil
+	})
+
+	feed.MarkAsNotDirty()
+
+	return err
+}
+
+// == LINKS ===================================================================
+
+// LinkCount returns the total number of links matching the query filters
+func (st *storeImplementation) LinkCount(ctx context.Context, query feedstore.LinkQueryInterface) (int64, error) {
+	if query == nil {
+		query = feedstore.LinkQuery()
+	}
+
+	list, err := st.LinkList(ctx, query.SetCountOnly(true))
+
+	return int64(len(list)), err
+}
+
+func (st *storeImplementation) LinkCreate(ctx context.Context, link feedstore.LinkInterface) error {
+	if link == nil {
+		return validationError("link", "link is nil")
+	}
+
+	return st.LinkCreateMany(ctx, []feedstore.LinkInterface{link})
+}
+
+// LinkCreateMany creates the links in a single transaction. All links get
+// the same created and updated time.
+func (st *storeImplementation) LinkCreateMany(ctx context.Context, links []feedstore.LinkInterface) error {
+	if len(links) == 0 {
+		return nil
+	}
+
+	now := now()
+
+	for _, link := range links {
+		if link == nil {
+			return validationError("link", "link is nil")
+		}
+
+		link.SetCreatedAt(now)
+		link.SetUpdatedAt(now)
+	}
+
+	err := st.atomically(ctx, func(txStore *storeImplementation) error {
+		for _, link := range links {
+			row := maps.Clone(link.Data())
+
+			if err := txStore.linkUniqueCheck(row, -1); err != nil {
+				return err
+			}
+
+			txStore.tx.links = append(txStore.tx.links, row)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		link.MarkAsNotDirty()
+	}
+
+	return nil
+}
+
+func (st *storeImplementation) LinkDelete(ctx context.Context, link feedstore.LinkInterface) error {
+	if link == nil {
+		return validationError("link", "link is nil")
+	}
+
+	return st.LinkDeleteByID(ctx, link.ID())
+}
+
+func (st *storeImplementation) LinkDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return validationError("id", "link id is empty")
+	}
+
+	return st.atomically(ctx, func(txStore *storeImplementation) error {
+		txStore.tx.links = slices.DeleteFunc(txStore.tx.links, func(row map[string]string) bool {
+			return row[feedstore.COLUMN_ID] == id
+		})
+
+		return nil
+	})
+}
+
+// LinkFindByID returns the link with the given ID, or ErrNotFound.
+// Soft deleted links are only found with the WithSoftDeleted option.
+func (st *storeImplementation) LinkFindByID(ctx context.Context, id string, options ...feedstore.FindByIDOptions) (feedstore.LinkInterface, error) {
+	if id == "" {
+		return nil, validationError("id", "link id is empty")
+	}
+
+	query := feedstore.LinkQuery().
+		SetID(id).
+		SetLimit(1)
+
+	if withSoftDeleted(options) {
+		query = query.SetWithSoftDeleted(true)
+	}
+
+	list, err := st.LinkList(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, notFoundError("link", id)
+}
+
+// LinkIncrementViews adds delta to the views of the link, and returns the
+// new number of views. The updated at time of the link is not changed.
+func (st *storeImplementation) LinkIncrementViews(ctx context.Context, id string, delta int64) (int64, error) {
+	if delta < 0 {
+		return 0, validationError("delta", "delta cannot be negative")
+	}
+
+	values, err := st.linkIncrement(ctx, id, map[string]int64{feedstore.COLUMN_VIEWS: delta})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return values[feedstore.COLUMN_VIEWS], nil
+}
+
+func (st *storeImplementation) LinkList(ctx context.Context, query feedstore.LinkQueryInterface) ([]feedstore.LinkInterface, error) {
+	if query == nil {
+		query = feedstore.LinkQuery()
+	}
+
+	s, err := linkSelection(query)
+
+	if err != nil {
+		return []feedstore.LinkInterface{}, err
+	}
+
+	list := []feedstore.LinkInterface{}
+
+	err = st.atomically(ctx, func(txStore *storeImplementation) error {
+		for _, row := range s.apply(txStore.tx.links) {
+			list = append(list, feedstore.NewLinkFromExistingData(row))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return []feedstore.LinkInterface{}, err
+	}
+
+	return list, nil
+}
+
+// LinkIterate streams the links matching the query, all of them when the
+// query sets no limit. The links are those stored when the iteration
+// starts, and the loop may use the store.
+func (st *storeImplementation) LinkIterate(ctx context.Context, query feedstore.LinkQueryInterface) iter.Seq2[feedstore.LinkInterface, error] {
+	return func(yield func(feedstore.LinkInterface, error) bool) {
+		if query == nil {
+			query = feedstore.LinkQuery()
+		}
+
+		s, err := linkSelection(query)
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		if !query.IsLimitSet() {
+			s.limit = -1
+		}
+
+		for row, err := range st.iterateRows(ctx, s, func(t *tables) []map[string]string { return t.links }) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(feedstore.NewLinkFromExistingData(row), nil) {
+				return
+			}
+		}
+	}
+}
+
+// LinkListPage lists a page of the links matching the query, paged by keyset
+// as by the SQL store
+func (st *storeImplementation) LinkListPage(ctx context.Context, query feedstore.LinkQueryInterface) ([]feedstore.LinkInterface, string, error) {
+	if query == nil {
+		query = feedstore.LinkQuery()
+	}
+
+	if query.IsRankingSet() {
+		return []feedstore.LinkInterface{}, "", validationError("ranking", "link query: ranking cannot be paged by cursor")
+	}
+
+	s, err := linkSelection(query)
+
+	if err != nil {
+		return []feedstore.LinkInterface{}, "", err
+	}
+
+	s.keyset(query)
+
+	if s.limit <= 0 {
+		s.limit = linkDefaultLimit
+	}
+
+	limit := s.limit
+	s.limit++
+
+	list := []feedstore.LinkInterface{}
+	more := false
+
+	err = st.atomically(ctx, func(txStore *storeImplementation) error {
+		rows := s.apply(txStore.tx.links)
+		more = len(rows) > limit
+
+		for _, row := range rows[:min(limit, len(rows))] {
+			list = append(list, feedstore.NewLinkFromExistingData(row))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return []feedstore.LinkInterface{}, "", err
+	}
+
+	if !more {
+		return list, "", nil
+	}
+
+	return list, feedstore.NewLinkCursor(list[len(list)-1], s.orderBy).Encode(), nil
+}
+
+// LinkRestore undoes the soft deletion of the link
+func (st *storeImplementation) LinkRestore(ctx context.Context, link feedstore.LinkInterface) error {
+	if link == nil {
+		return validationError("link", "link is nil")
+	}
+
+	link.SetSoftDeletedAt(sb.MAX_DATETIME)
+	link.SetCascadeDeletedBy("")
+
+	return st.LinkUpdate(ctx, link)
+}
+
+// LinkRestoreByID undoes the soft deletion of the link with the given ID
+func (st *storeImplementation) LinkRestoreByID(ctx context.Context, id string) error {
+	link, err := st.LinkFindByID(ctx, id, feedstore.FindByIDOptions{WithSoftDeleted: true})
+
+	if err != nil {
+		return err
+	}
+
+	return st.LinkRestore(ctx, link)
+}
+
+// LinkRestoreByQuery undoes the soft deletion of the soft deleted links
+// matching the query, and returns how many were restored. The limit, the
+// offset and the order of the query are ignored, and the query itself is
+// not changed.
+func (st *storeImplementation) LinkRestoreByQuery(ctx context.Context, query feedstore.LinkQueryInterface) (int64, error) {
+	if query == nil {
+		query = feedstore.LinkQuery()
+	}
+
+	s, err := linkSelection(query)
+
+	if err != nil {
+		return 0, err
+	}
+
+	s = s.onlySoftDeleted()
+
+	restored := int64(0)
+
+	err = st.atomically(ctx, func(txStore *storeImplementation) error {
+		list := []feedstore.LinkInterface{}
+
+		for _, row := range s.apply(txStore.tx.links) {
+			list = append(list, feedstore.NewLinkFromExistingData(row))
+		}
+
+		for _, link := range list {
+			if err := txStore.LinkRestore(ctx, link); err != nil {
+				return err
+			}
+		}
+
+		restored = int64(len(list))
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return restored, nil
+}
+
+func (st *storeImplementation) LinkSoftDelete(ctx context.Context, link feedstore.LinkInterface) error {
+	if link == nil {
+		return validationError("link", "link is nil")
+	}
+
+	link.SetSoftDeletedAt(now())
+	link.SetCascadeDeletedBy("")
+
+	return st.LinkUpdate(ctx, link)
+}
+
+func (st *storeImplementation) LinkSoftDeleteByID(ctx context.Context, id string) error {
+	link, err := st.LinkFindByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return st.LinkSoftDelete(ctx, link)
+}
+
+// LinkVote adds an up vote, or a down vote, to the link, and returns the new
+// numbers of up and down votes. The updated at time of the link is not
+// changed.
+func (st *storeImplementation) LinkVote(ctx context.Context, id string, up bool) (votesUp int64, votesDown int64, err error) {
+	increments := map[string]int64{feedstore.COLUMN_VOTES_UP: 0, feedstore.COLUMN_VOTES_DOWN: 1}
+
+	if up {
+		increments = map[string]int64{feedstore.COLUMN_VOTES_UP: 1, feedstore.COLUMN_VOTES_DOWN: 0}
+	}
+
+	values, err := st.linkIncrement(ctx, id, increments)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return values[feedstore.COLUMN_VOTES_UP], values[feedstore.COLUMN_VOTES_DOWN], nil
+}
+
+func (st *storeImplementation) LinkUpdate(ctx context.Context, link feedstore.LinkInterface) error {
+	if link == nil {
+		return validationError("link", "link is nil")
+	}
+
+	link.SetUpdatedAt(now())
+
+	dataChanged := link.DataChanged()
+
+	delete(dataChanged, feedstore.COLUMN_ID) // ID is not updateable
+
+	if len(dataChanged) <= 1 {
+		return nil // only the updated_at field is changed, no need to update
+	}
+
+	err := st.atomically(ctx, func(txStore *storeImplementation) error {
+		index := rowIndex(txStore.tx.links, link.ID())
+
+		if index < 0 {
+			return notFoundError("link", link.ID())
+		}
+
+		row := maps.Clone(txStore.tx.links[index])
+		maps.Copy(row, dataChanged)
+
+		if err := txStore.linkUniqueCheck(row, index); err != nil {
+			return err
+		}
+
+		txStore.tx.links[index] = row
+
+		return nil
+	})
+
+	link.MarkAsNotDirty()
+
+	return err
+}
+
+// LinkUpsert inserts the link, or when a link with the same feed ID and GUID
+// already exists, updates its title and description.
+//
+// On success the ID and creation time of the link are set to those of the
+// stored row.
+func (st *storeImplementation) LinkUpsert(ctx context.Context, link feedstore.LinkInterface) error {
+	if link == nil {
+		return validationError("link", "link is nil")
+	}
+
+	if link.FeedID() == "" {
+		return validationError("feed_id", "link feed id is empty")
+	}
+
+	if link.GUID() == "" {
+		return validationError("guid", "link guid is empty")
+	}
+
+	link.SetCreatedAt(now())
+	link.SetUpdatedAt(now())
+
+	err := st.atomically(ctx, func(txStore *storeImplementation) error {
+		index := slices.IndexFunc(txStore.tx.links, func(row map[string]string) bool {
+			return row[feedstore.COLUMN_FEED_ID] == link.FeedID() && row[feedstore.COLUMN_GUID] == link.GUID()
+		})
+
+		if index < 0 {
+			row := maps.Clone(link.Data())
+
+			if err := txStore.linkUniqueCheck(row, -1); err != nil {
+				return err
+			}
+
+			txStore.tx.links = append(txStore.tx.links, row)
+
+			return nil
+		}
+
+		rowReplace(txStore.tx.links, index, map[string]string{
+			feedstore.COLUMN_TITLE:       link.Title(),
+			feedstore.COLUMN_DESCRIPTION: link.Description(),
+			feedstore.COLUMN_UPDATED_AT:  link.UpdatedAt(),
+		})
+
+		link.SetID(txStore.tx.links[index][feedstore.COLUMN_ID])
+		link.SetCreatedAt(txStore.tx.links[index][feedstore.COLUMN_CREATED_AT])
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	link.MarkAsNotDirty()
+
+	return nil
+}
+
+// linkUniqueCheck returns ErrDuplicate when another link than the one at
+// the index has the ID of the row, or its feed ID and GUID
+func (st *storeImplementation) linkUniqueCheck(row map[string]string, index int) error {
+	for i, other := range st.tx.links {
+		if i == index {
+			continue
+		}
+
+		if other[feedstore.COLUMN_ID] == row[feedstore.COLUMN_ID] {
+			return duplicateError("link", row[feedstore.COLUMN_ID])
+		}
+
+		if other[feedstore.COLUMN_FEED_ID] == row[feedstore.COLUMN_FEED_ID] && other[feedstore.COLUMN_GUID] == row[feedstore.COLUMN_GUID] {
+			return duplicateError("link guid", row[feedstore.COLUMN_GUID])
+		}
+	}
+
+	return nil
+}
+
+// linksSoftDeleteByFeedID soft deletes the links of the feed which are not
+// soft deleted yet, with the given time, marking them as soft deleted by
+// the feed
+func (st *storeImplementation) linksSoftDeleteByFeedID(feedID string, softDeletedAt string) {
+	now := now()
+
+	for i, row := range st.tx.links {
+		if row[feedstore.COLUMN_FEED_ID] == feedID && row[feedstore.COLUMN_SOFT_DELETED_AT] > now {
+			rowReplace(st.tx.links, i, map[string]string{
+				feedstore.COLUMN_SOFT_DELETED_AT:    softDeletedAt,
+				feedstore.COLUMN_CASCADE_DELETED_BY: feedID,
+				feedstore.COLUMN_UPDATED_AT:         now,
+			})
+		}
+	}
+}
+
+// linksRestoreByFeedID restores the links of the feed which were soft
+// deleted by the soft deletion of the feed
+func (st *storeImplementation) linksRestoreByFeedID(feedID string) {
+	now := now()
+
+	for i, row := range st.tx.links {
+		if row[feedstore.COLUMN_FEED_ID] == feedID && row[feedstore.COLUMN_CASCADE_DELETED_BY] == feedID {
+			rowReplace(st.tx.links, i, map[string]string{
+				feedstore.COLUMN_SOFT_DELETED_AT:    sb.MAX_DATETIME,
+				feedstore.COLUMN_CASCADE_DELETED_BY: "",
+				feedstore.COLUMN_UPDATED_AT:         now,
+			})
+		}
+	}
+}
+
+// == MODERATION ==============================================================
+
+// LinkReport files a report of the link with the reason, and returns the
+// number of reports of the link, hiding the link at the auto hide threshold
+// as the SQL store does. The updated at time of the link is not changed.
+func (st *storeImplementation) LinkReport(ctx context.Context, id string, reason string) (int64, error) {
+	reason = strings.TrimSpace(reason)
+
+	if reason == "" {
+		return 0, validationError("reason", "report reason is empty")
+	}
+
+	var reportCount int64
+
+	err := st.atomically(ctx, func(txStore *storeImplementation) error {
+		values, err := txStore.linkIncrement(ctx, id, map[string]int64{feedstore.COLUMN_REPORT_COUNT: 1})
+
+		if err != nil {
+			return err
+		}
+
+		index := rowIndex(txStore.tx.links, id)
+		reportCount = values[feedstore.COLUMN_REPORT_COUNT]
+		reportedAt := now()
+
+		link := feedstore.NewLinkFromExistingData(txStore.tx.links[index])
+		reports := append(feedstore.LinkReports(link), feedstore.LinkReportEntry{Reason: reason, ReportedAt: reportedAt})
+
+		data, err := json.Marshal(reports)
+
+		if err != nil {
+			return err
+		}
+
+		changes := map[string]string{
+			feedstore.COLUMN_REPORT:      string(data),
+			feedstore.COLUMN_REPORTED_AT: reportedAt,
+		}
+
+		if txStore.moderationAutoHideThreshold > 0 && reportCount >= txStore.moderationAutoHideThreshold {
+			changes[feedstore.COLUMN_STATUS] = feedstore.LINK_STATUS_HIDDEN
+		}
+
+		rowReplace(txStore.tx.links, index, changes)
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return reportCount, nil
+}
+
+// LinkReportedList lists the reported links matching the query, the most
+// reported first, then the most recently reported
+func (st *storeImplementation) LinkReportedList(ctx context.Context, query feedstore.LinkQueryInterface) ([]feedstore.LinkInterface, error) {
+	if query == nil {
+		query = feedstore.LinkQuery()
+	}
+
+	if query.IsOrderBySet() {
+		return []feedstore.LinkInterface{}, validationError("order_by", "link query: reported links are ordered by report count")
+	}
+
+	if query.IsRankingSet() {
+		return []feedstore.LinkInterface{}, validationError("ranking", "link query: reported links are ordered by report count")
+	}
+
+	if query.IsCursorSet() {
+		return []feedstore.LinkInterface{}, validationError("cursor", "link query: reported links cannot be paged by cursor")
+	}
+
+	s, err := linkSelection(query)
+
+	if err != nil {
+		return []feedstore.LinkInterface{}, err
+	}
+
+	s.where(gte(feedstore.COLUMN_REPORT_COUNT, "1"))
+
+	s.compare = func(a, b map[string]string) int {
+		for _, column := range []string{feedstore.COLUMN_REPORT_COUNT, feedstore.COLUMN_REPORTED_AT, feedstore.COLUMN_ID} {
+			if c := compareValues(column, b[column], a[column]); c != 0 {
+				return c
+			}
+		}
+
+		return 0
+	}
+
+	list := []feedstore.LinkInterface{}
+
+	err = st.atomically(ctx, func(txStore *storeImplementation) error {
+		for _, row := range s.apply(txStore.tx.links) {
+			list = append(list, feedstore.NewLinkFromExistingData(row))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return []feedstore.LinkInterface{}, err
+	}
+
+	return list, nil
+}
+
+// LinkReportResolve resolves the reports of the link, as by the SQL store
+func (st *storeImplementation) LinkReportResolve(ctx context.Context, id string, resolution string) error {
+	if !slices.Contains([]string{feedstore.MODERATION_DISMISS, feedstore.MODERATION_HIDE, feedstore.MODERATION_SOFT_DELETE}, resolution) {
+		return validationError("resolution", "resolution must be one of dismiss, hide or soft_delete")
+	}
+
+	return st.atomically(ctx, func(txStore *storeImplementation) error {
+		link, err := txStore.LinkFindByID(ctx, id)
+
+		if err != nil {
+			return err
+		}
+
+		switch resolution {
+		case feedstore.MODERATION_DISMISS:
+			linkReportsClear(link)
+
+			if link.Status() == feedstore.LINK_STATUS_HIDDEN {
+				link.SetStatus(feedstore.LINK_STATUS_ACTIVE)
+			}
+		case feedstore.MODERATION_HIDE:
+			linkReportsClear(link)
+			link.SetStatus(feedstore.LINK_STATUS_HIDDEN)
+		case feedstore.MODERATION_SOFT_DELETE:
+			link.SetSoftDeletedAt(now())
+			link.SetCascadeDeletedBy("")
+		}
+
+		return txStore.LinkUpdate(ctx, link)
+	})
+}
+
+// linkReportsClear removes the reports of the link
+func linkReportsClear(link feedstore.LinkInterface) {
+	link.SetReport("")
+	link.SetReportCount("0")
+	link.SetReportedAt(sb.NULL_DATETIME)
+}
+
+// == MAINTENANCE =============================================================
+
+// PurgeSoftDeleted permanently deletes the feeds and links which were soft
+// deleted longer ago than olderThan
+func (st *storeImplementation) PurgeSoftDeleted(ctx context.Context, olderThan time.Duration) (feedstore.PurgeResult, error) {
+	result := feedstore.PurgeResult{}
+
+	if olderThan < 0 {
+		return result, validationError("olderThan", "olderThan cannot be negative")
+	}
+
+	cutoff := carbon.CreateFromStdTime(time.Now().UTC().Add(-olderThan), carbon.UTC).
+		ToDateTimeString(carbon.UTC)
+
+	purged := func(row map[string]string) bool {
+		return row[feedstore.COLUMN_SOFT_DELETED_AT] < cutoff
+	}
+
+	err := st.atomically(ctx, func(txStore *storeImplementation) error {
+		links := len(txStore.tx.links)
+		txStore.tx.links = slices.DeleteFunc(txStore.tx.links, purged)
+		result.Links = int64(links - len(txStore.tx.links))
+
+		feeds := len(txStore.tx.feeds)
+		txStore.tx.feeds = slices.DeleteFunc(txStore.tx.feeds, purged)
+		result.Feeds = int64(feeds - len(txStore.tx.feeds))
+
+		return nil
+	})
+
+	if err != nil {
+		return feedstore.PurgeResult{}, err
+	}
+
+	return result, nil
+}
+
+// EnforceRetention applies the retention settings of every feed which has
+// them, and returns the number of links removed
+func (st *storeImplementation) EnforceRetention(ctx context.Context, options feedstore.RetentionOptions) (int64, error) {
+	removed := int64(0)
+
+	err := st.atomically(ctx, func(txStore *storeImplementation) error {
+		feeds, err := txStore.FeedList(ctx, feedstore.FeedQuery().
+			SetOrderBy(feedstore.COLUMN_ID).
+			SetOrderDirection(sb.ASC))
+
+		if err != nil {
+			return err
+		}
+
+		for _, feed := range feeds {
+			count, err := txStore.FeedEnforceRetention(ctx, feed, options)
+			removed += count
+
+			if err != nil {
+				return fmt.Errorf("feed %s: %w", feed.ID(), err)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return removed, nil
+}
+
+// FeedEnforceRetention removes the links of the feed beyond its retention
+// settings, and returns how many were removed.
+//
+// Links are kept newest first by their time, then by their creation time.
+// The age of a link is its time, or its creation time when the time is
+// unknown.
+func (st *storeImplementation) FeedEnforceRetention(ctx context.Context, feed feedstore.FeedInterface, options feedstore.RetentionOptions) (int64, error) {
+	if feed == nil {
+		return 0, validationError("feed", "feed is nil")
+	}
+
+	maxItems, err := feed.RetentionMaxItemsInt64()
+
+	if err != nil {
+		maxItems = 0
+	}
+
+	maxAge, err := feed.RetentionMaxAgeInt64()
+
+	if err != nil {
+		maxAge = 0
+	}
+
+	removed := int64(0)
+
+	err = st.atomically(ctx, func(txStore *storeImplementation) error {
+		now := now()
+
+		links := []map[string]string{}
+
+		for _, row := range txStore.tx.links {
+			if row[feedstore.COLUMN_FEED_ID] == feed.ID() && row[feedstore.COLUMN_SOFT_DELETED_AT] > now {
+				links = append(links, row)
+			}
+		}
+
+		remove := map[string]bool{}
+
+		if maxAge > 0 {
+			cutoff := carbon.Now(carbon.UTC).SubSeconds(int(maxAge)).ToDateTimeString(carbon.UTC)
+
+			for _, row := range links {
+				age := row[feedstore.COLUMN_TIME]
+
+				if age <= sb.NULL_DATETIME {
+					age = row[feedstore.COLUMN_CREATED_AT]
+				}
+
+				if age < cutoff {
+					remove[row[feedstore.COLUMN_ID]] = true
+				}
+			}
+		}
+
+		if maxItems > 0 {
+			kept := slices.DeleteFunc(slices.Clone(links), func(row map[string]string) bool {
+				return remove[row[feedstore.COLUMN_ID]]
+			})
+
+			slices.SortStableFunc(kept, func(a, b map[string]string) int {
+				for _, column := range []string{feedstore.COLUMN_TIME, feedstore.COLUMN_CREATED_AT, feedstore.COLUMN_ID} {
+					if c := compareValues(column, b[column], a[column]); c != 0 {
+						return c
+					}
+				}
+
+				return 0
+			})
+
+			for _, row := range kept[min(int(maxItems), len(kept)):] {
+				remove[row[feedstore.COLUMN_ID]] = true
+			}
+		}
+
+		if options.HardDelete {
+			txStore.tx.links = slices.DeleteFunc(txStore.tx.links, func(row map[string]string) bool {
+				return remove[row[feedstore.COLUMN_ID]]
+			})
+		} else {
+			for i, row := range txStore.tx.links {
+				if remove[row[feedstore.COLUMN_ID]] {
+					rowReplace(txStore.tx.links, i, map[string]string{
+						feedstore.COLUMN_SOFT_DELETED_AT: now,
+						feedstore.COLUMN_UPDATED_AT:      now,
+					})
+				}
+			}
+		}
+
+		removed = int64(len(remove))
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return removed, nil
+}
+
+// withSoftDeleted reports whether any of the options allows soft deleted
+// records to be found
+func withSoftDeleted(options []feedstore.FindByIDOptions) bool {
+	return slices.ContainsFunc(options, func(option feedstore.FindByIDOptions) bool {
+		return option.WithSoftDeleted
+	})
+}
+
+// iterateRows streams the rows of the table matching the selection, as
+// selected when the iteration starts
+func (st *storeImplementation) iterateRows(ctx context.Context, s selection, table func(t *tables) []map[string]string) iter.Seq2[map[string]string, error] {
+	return func(yield func(map[string]string, error) bool) {
+		rows := []map[string]string{}
+
+		err := st.atomically(ctx, func(txStore *storeImplementation) error {
+			rows = s.apply(table(txStore.tx))
+			return nil
+		})
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for _, row := range rows {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}
+}
+
+// linkIncrement adds the increments to the counter columns of the link,
+// which must not be soft deleted, and returns the new values of the columns
+func (st *storeImplementation) linkIncrement(ctx context.Context, id string, increments map[string]int64) (map[string]int64, error) {
+	if id == "" {
+		return nil, validationError("id", "link id is empty")
+	}
+
+	values := map[string]int64{}
+	visible := softDeleted(false, false)
+
+	err := st.atomically(ctx, func(txStore *storeImplementation) error {
+		index := rowIndex(txStore.tx.links, id)
+
+		if index < 0 || !visible(txStore.tx.links[index]) {
+			return notFoundError("link", id)
+		}
+
+		changes := map[string]string{}
+
+		for column, delta := range increments {
+			value, _ := strconv.ParseInt(txStore.tx.links[index][column], 10, 64)
+			values[column] = value + delta
+			changes[column] = strconv.FormatInt(values[column], 10)
+		}
+
+		rowReplace(txStore.tx.links, index, changes)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -0,0 +1,522 @@
+package feedstore
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dracory/database"
+	"github.com/dracory/sb"
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// migration is a versioned step of the schema.
+//
+// The first migration creates the tables with their latest columns, so on
+// a new database the later steps find their changes already in place. Every
+// up step must therefore be idempotent.
+type migration struct {
+	MigrationInfo
+	up func(ctx context.Context, st *storeImplementation) error
+}
+
+// migrations returns the schema migrations in the order they are applied.
+// New migrations are appended with the next version, released ones are
+// never changed.
+func (st *storeImplementation) migrations() []migration {
+	return []migration{
+		{
+			MigrationInfo: MigrationInfo{Version: 1, Description: "create the feed and link tables"},
+			up: func(ctx context.Context, st *storeImplementation) error {
+				return st.execAll(ctx, st.sqlFeedTableCreate(), st.sqlLinkTableCreate())
+			},
+		},
+		{
+			MigrationInfo: MigrationInfo{Version: 2, Description: "add etag and last_modified to the feed table"},
+			up: func(ctx context.Context, st *storeImplementation) error {
+				err := st.columnAddIfNotExists(ctx, st.feedTableName, sb.Column{
+					Name: COLUMN_ETAG,
+					Type: sb.COLUMN_TYPE_STRING,
+				}, "''")
+
+				if err != nil {
+					return err
+				}
+
+				return st.columnAddIfNotExists(ctx, st.feedTableName, sb.Column{
+					Name: COLUMN_LAST_MODIFIED,
+					Type: sb.COLUMN_TYPE_STRING,
+				}, "''")
+			},
+		},
+		{
+			MigrationInfo: MigrationInfo{Version: 3, Description: "add guid to the link table, unique per feed"},
+			up: func(ctx context.Context, st *storeImplementation) error {
+				err := st.columnAddIfNotExists(ctx, st.linkTableName, sb.Column{
+					Name: COLUMN_GUID,
+					Type: sb.COLUMN_TYPE_STRING,
+				}, "''")
+
+				if err != nil {
+					return err
+				}
+
+				// Existing links get their ID as GUID, like new links do
+				sqlStr, _, errSql := goqu.Dialect(st.dbDriverName).
+					Update(st.linkTableName).
+					Set(goqu.Record{COLUMN_GUID: goqu.C(COLUMN_ID)}).
+					Where(goqu.C(COLUMN_GUID).Eq("")).
+					ToSQL()
+
+				if errSql != nil {
+					return errSql
+				}
+
+				if err := st.execAll(ctx, sqlStr); err != nil {
+					return err
+				}
+
+				// MySQL indexes a prefix of the guid, which is longer than
+				// the VARCHAR column added here, so the index is created
+				// once the column is TEXT (version 9)
+				if st.dbDriverName == sb.DIALECT_MYSQL {
+					return nil
+				}
+
+				return st.indexCreateIfNotExists(ctx,
+					st.linkTableName,
+					st.linkTableName+"_feed_id_guid_unique",
+					true,
+					linkGUIDUniqueColumns...)
+			},
+		},
+		{
+			MigrationInfo: MigrationInfo{Version: 4, Description: "add category to the feed table"},
+			up: func(ctx context.Context, st *storeImplementation) error {
+				return st.columnAddIfNotExists(ctx, st.feedTableName, sb.Column{
+					Name: COLUMN_CATEGORY,
+					Type: sb.COLUMN_TYPE_STRING,
+				}, "''")
+			},
+		},
+		{
+			MigrationInfo: MigrationInfo{Version: 5, Description: "add retention settings to the feed table"},
+			up: func(ctx context.Context, st *storeImplementation) error {
+				err := st.columnAddIfNotExists(ctx, st.feedTableName, sb.Column{
+					Name: COLUMN_RETENTION_MAX_ITEMS,
+					Type: sb.COLUMN_TYPE_INTEGER,
+				}, "0")
+
+				if err != nil {
+					return err
+				}
+
+				return st.columnAddIfNotExists(ctx, st.feedTableName, sb.Column{
+					Name: COLUMN_RETENTION_MAX_AGE,
+					Type: sb.COLUMN_TYPE_INTEGER,
+				}, "0")
+			},
+		},
+		{
+			MigrationInfo: MigrationInfo{Version: 6, Description: "add secondary indexes to the feed and link tables"},
+			up: func(ctx context.Context, st *storeImplementation) error {
+				indexes := []struct {
+					table   string
+					name    string
+					columns []string
+				}{
+					{st.linkTableName, st.linkTableName + "_feed_id_time_index", []string{COLUMN_FEED_ID, COLUMN_TIME}},
+					{st.linkTableName, st.linkTableName + "_url_index", []string{COLUMN_URL}},
+					{st.linkTableName, st.linkTableName + "_status_index", []string{COLUMN_STATUS}},
+					{st.linkTableName, st.linkTableName + "_soft_deleted_at_index", []string{COLUMN_SOFT_DELETED_AT}},
+					{st.feedTableName, st.feedTableName + "_last_fetched_at_index", []string{COLUMN_LAST_FETCHED_AT}},
+				}
+
+				for _, index := range indexes {
+					err := st.indexCreateIfNotExists(ctx, index.table, index.name, false, index.columns...)
+
+					if err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+		},
+		{
+			MigrationInfo: MigrationInfo{Version: 7, Description: "add report_count to the link table"},
+			up: func(ctx context.Context, st *storeImplementation) error {
+				err := st.columnAddIfNotExists(ctx, st.linkTableName, sb.Column{
+					Name: COLUMN_REPORT_COUNT,
+					Type: sb.COLUMN_TYPE_INTEGER,
+				}, "0")
+
+				if err != nil {
+					return err
+				}
+
+				// A report of an existing link counts as one
+				sqlStr, _, errSql := goqu.Dialect(st.dbDriverName).
+					Update(st.linkTableName).
+					Set(goqu.Record{COLUMN_REPORT_COUNT: 1}).
+					Where(goqu.C(COLUMN_REPORT).Neq(""), goqu.C(COLUMN_REPORT_COUNT).Eq(0)).
+					ToSQL()
+
+				if errSql != nil {
+					return errSql
+				}
+
+				if err := st.execAll(ctx, sqlStr); err != nil {
+					return err
+				}
+
+				return st.indexCreateIfNotExists(ctx,
+					st.linkTableName,
+					st.linkTableName+"_report_count_index",
+					false,
+					COLUMN_REPORT_COUNT,
+					COLUMN_REPORTED_AT)
+			},
+		},
+		{
+			MigrationInfo: MigrationInfo{Version: 8, Description: "add cascade_deleted_by to the link table"},
+			up: func(ctx context.Context, st *storeImplementation) error {
+				err := st.columnAddIfNotExists(ctx, st.linkTableName, sb.Column{
+					Name:   COLUMN_CASCADE_DELETED_BY,
+					Type:   sb.COLUMN_TYPE_STRING,
+					Length: 40,
+				}, "''")
+
+				if err != nil {
+					return err
+				}
+
+				// Links soft deleted at the time of their soft deleted feed
+				// were soft deleted with it, as the earlier releases assumed
+				feedSoftDeletedAt := goqu.Dialect(st.dbDriverName).
+					From(st.feedTableName).
+					Select(COLUMN_SOFT_DELETED_AT).
+					Where(goqu.I(st.feedTableName + "." + COLUMN_ID).Eq(goqu.I(st.linkTableName + "." + COLUMN_FEED_ID)))
+
+				sqlStr, _, errSql := goqu.Dialect(st.dbDriverName).
+					Update(st.linkTableName).
+					Set(goqu.Record{COLUMN_CASCADE_DELETED_BY: goqu.C(COLUMN_FEED_ID)}).
+					Where(
+						goqu.C(COLUMN_CASCADE_DELETED_BY).Eq(""),
+						goqu.C(COLUMN_SOFT_DELETED_AT).Lt(sb.MAX_DATETIME),
+						goqu.C(COLUMN_SOFT_DELETED_AT).Eq(feedSoftDeletedAt),
+					).
+					ToSQL()
+
+				if errSql != nil {
+					return errSql
+				}
+
+				return st.execAll(ctx, sqlStr)
+			},
+		},
+		{
+			MigrationInfo: MigrationInfo{Version: 9, Description: "store the guid of the link table as TEXT on MySQL"},
+			up: func(ctx context.Context, st *storeImplementation) error {
+				// VARCHAR(255) is too short for some GUIDs. The other
+				// databases do not limit the length of the column.
+				if st.dbDriverName != sb.DIALECT_MYSQL {
+					return nil
+				}
+
+				indexName := st.linkTableName + "_feed_id_guid_unique"
+
+				exists, err := st.indexExists(ctx, st.linkTableName, indexName)
+
+				if err != nil {
+					return err
+				}
+
+				// The index is created on a prefix of the TEXT column. It
+				// exists on the VARCHAR column of the earlier releases.
+				if exists {
+					sqlDrop := "DROP INDEX " + st.quoteIdentifier(indexName) + " ON " + st.quoteIdentifier(st.linkTableName) + ";"
+
+					if err := st.execAll(ctx, sqlDrop); err != nil {
+						return err
+					}
+				}
+
+				sqlChange, err := sb.NewBuilder(st.dbDriverName).TableColumnChange(st.linkTableName, sb.Column{
+					Name: COLUMN_GUID,
+					Type: sb.COLUMN_TYPE_TEXT,
+				})
+
+				if err != nil {
+					return err
+				}
+
+				if err := st.execAll(ctx, sqlChange); err != nil {
+					return err
+				}
+
+				return st.indexCreateIfNotExists(ctx, st.linkTableName, indexName, true, linkGUIDUniqueColumns...)
+			},
+		},
+		{
+			MigrationInfo: MigrationInfo{Version: 10, Description: "add next_fetch_at to the feed table"},
+			up: func(ctx context.Context, st *storeImplementation) error {
+				err := st.columnAddIfNotExists(ctx, st.feedTableName, sb.Column{
+					Name: COLUMN_NEXT_FETCH_AT,
+					Type: sb.COLUMN_TYPE_DATETIME,
+				}, "'"+sb.NULL_DATETIME+"'")
+
+				if err != nil {
+					return err
+				}
+
+				// Feeds which were fetched are due one fetch interval
+				// later, the others stay due at once
+				sqlStr, _, errSql := goqu.Dialect(st.dbDriverName).
+					From(st.feedTableName).
+					Select(COLUMN_ID, COLUMN_LAST_FETCHED_AT, COLUMN_FETCH_INTERVAL).
+					Where(
+						goqu.C(COLUMN_NEXT_FETCH_AT).Eq(sb.NULL_DATETIME),
+						goqu.C(COLUMN_LAST_FETCHED_AT).Gt(sb.NULL_DATETIME),
+					).
+					ToSQL()
+
+				if errSql != nil {
+					return errSql
+				}
+
+				rows, err := database.SelectToMapString(st.toQueryableContext(ctx), sqlStr)
+
+				if err != nil {
+					return err
+				}
+
+				for _, row := range rows {
+					sqlUpdate, _, errSql := goqu.Dialect(st.dbDriverName).
+						Update(st.feedTableName).
+						Set(goqu.Record{COLUMN_NEXT_FETCH_AT: feedNextFetchAt(row[COLUMN_LAST_FETCHED_AT], row[COLUMN_FETCH_INTERVAL])}).
+						Where(goqu.C(COLUMN_ID).Eq(row[COLUMN_ID])).
+						ToSQL()
+
+					if errSql != nil {
+						return errSql
+					}
+
+					if err := st.execAll(ctx, sqlUpdate); err != nil {
+						return err
+					}
+				}
+
+				return st.indexCreateIfNotExists(ctx,
+					st.feedTableName,
+					st.feedTableName+"_status_next_fetch_at_index",
+					false,
+					COLUMN_STATUS,
+					COLUMN_NEXT_FETCH_AT)
+			},
+		},
+	}
+}
+
+// Migrate applies the pending migrations in order. Each migration is
+// applied in its own transaction together with its record in the migration
+// table (MySQL commits schema changes implicitly, so there a failed
+// migration may be partially applied, and is retried on the next run).
+func (st *storeImplementation) Migrate(ctx context.Context) error {
+	sql := st.sqlMigrationTableCreate()
+
+	if st.debugEnabled {
+		log.Println(sql)
+	}
+
+	if _, err := database.Execute(st.toQueryableContext(ctx), sql); err != nil {
+		return err
+	}
+
+	pending, err := st.pendingMigrations(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, m := range pending {
+		err := st.inTx(ctx, func(txStore *storeImplementation) error {
+			if err := m.up(ctx, txStore); err != nil {
+				return err
+			}
+
+			return txStore.migrationRecord(ctx, m.MigrationInfo)
+		})
+
+		if err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+	}
+
+	return nil
+}
+
+// MigrationsPending returns the migrations which are not applied yet,
+// without changing the database
+func (st *storeImplementation) MigrationsPending(ctx context.Context) ([]MigrationInfo, error) {
+	pending, err := st.pendingMigrations(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(pending, func(m migration, _ int) MigrationInfo {
+		return m.MigrationInfo
+	}), nil
+}
+
+// pendingMigrations returns the migrations whose version is not recorded
+// in the migration table
+func (st *storeImplementation) pendingMigrations(ctx context.Context) ([]migration, error) {
+	applied, err := st.appliedVersions(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Filter(st.migrations(), func(m migration, _ int) bool {
+		return !applied[m.Version]
+	}), nil
+}
+
+// appliedVersions returns the versions recorded in the migration table,
+// none when the table does not exist yet
+func (st *storeImplementation) appliedVersions(ctx context.Context) (map[int]bool, error) {
+	applied := map[int]bool{}
+
+	exists, err := st.tableExists(ctx, st.migrationTableName)
+
+	if err != nil || !exists {
+		return applied, err
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(st.dbDriverName).
+		From(st.migrationTableName).
+		Prepared(true).
+		Select(COLUMN_VERSION).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	rows, err := database.SelectToMapString(st.toQueryableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		version, err := strconv.Atoi(row[COLUMN_VERSION])
+
+		if err != nil {
+			return nil, err
+		}
+
+		applied[version] = true
+	}
+
+	return applied, nil
+}
+
+// migrationRecord records the migration as applied
+func (st *storeImplementation) migrationRecord(ctx context.Context, info MigrationInfo) error {
+	sqlStr, params, errSql := goqu.Dialect(st.dbDriverName).
+		Insert(st.migrationTableName).
+		Prepared(true).
+		Rows(goqu.Record{
+			COLUMN_VERSION:     info.Version,
+			COLUMN_DESCRIPTION: info.Description,
+			COLUMN_APPLIED_AT:  carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		}).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if st.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(st.toQueryableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// execAll executes the SQL statements one after the other
+func (st *storeImplementation) execAll(ctx context.Context, statements ...string) error {
+	for _, sql := range statements {
+		if sql == "" {
+			return newValidationError("sql", "migration sql is empty")
+		}
+
+		if st.debugEnabled {
+			log.Println(sql)
+		}
+
+		if _, err := database.Execute(st.toQueryableContext(ctx), sql); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tableExists checks whether a table with the given name exists
+func (st *storeImplementation) tableExists(ctx context.Context, tableName string) (bool, error) {
+	q := goqu.Dialect(st.dbDriverName).Select(goqu.COUNT("*").As("count"))
+
+	switch st.dbDriverName {
+	case sb.DIALECT_MYSQL:
+		q = q.From(goqu.S("information_schema").Table("tables")).
+			Where(
+				goqu.C("table_schema").Eq(goqu.L("DATABASE()")),
+				goqu.C("table_name").Eq(tableName),
+			)
+	case sb.DIALECT_POSTGRES:
+		q = q.From(goqu.S("information_schema").Table("tables")).
+			Where(
+				goqu.C("table_schema").Eq(goqu.L("current_schema()")),
+				goqu.C("table_name").Eq(tableName),
+			)
+	default:
+		q = q.From(goqu.T("sqlite_master")).
+			Where(
+				goqu.C("type").Eq("table"),
+				goqu.C("name").Eq(tableName),
+			)
+	}
+
+	sqlStr, params, errSql := q.Prepared(true).ToSQL()
+
+	if errSql != nil {
+		return false, errSql
+	}
+
+	rows, err := database.SelectToMapString(st.toQueryableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return false, err
+	}
+
+	if len(rows) == 0 {
+		return false, nil
+	}
+
+	count, err := strconv.ParseInt(rows[0]["count"], 10, 64)
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
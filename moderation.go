@@ -0,0 +1,250 @@
+package feedstore
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dracory/database"
+	"github.com/dracory/sb"
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// LinkReportEntry is a report of a link, as kept in its report column
+type LinkReportEntry struct {
+	Reason     string `json:"reason"`
+	ReportedAt string `json:"reported_at"`
+}
+
+// LinkReports returns the reports of the link, oldest first. A report set
+// as plain text, instead of by LinkReport, is returned as a single report
+// made at the reported at time of the link.
+func LinkReports(link LinkInterface) []LinkReportEntry {
+	report := strings.TrimSpace(link.Report())
+
+	if report == "" {
+		return []LinkReportEntry{}
+	}
+
+	reports := []LinkReportEntry{}
+
+	if err := json.Unmarshal([]byte(report), &reports); err == nil {
+		return reports
+	}
+
+	reportedAt := link.ReportedAt()
+
+	if c := carbon.Parse(reportedAt, carbon.UTC); c.IsValid() {
+		reportedAt = c.ToDateTimeString(carbon.UTC)
+	}
+
+	return []LinkReportEntry{{Reason: report, ReportedAt: reportedAt}}
+}
+
+// LinkReport files a report of the link with the reason, and returns the
+// number of reports of the link. The reports accumulate until they are
+// resolved with LinkReportResolve.
+//
+// When the store has an auto hide threshold, and the link reaches it, the
+// status of the link is set to LINK_STATUS_HIDDEN. The updated at time of
+// the link is not changed.
+func (st *storeImplementation) LinkReport(ctx context.Context, id string, reason string) (int64, error) {
+	reason = strings.TrimSpace(reason)
+
+	if reason == "" {
+		return 0, newValidationError("reason", "report reason is empty")
+	}
+
+	var reportCount int64
+
+	err := st.inTx(ctx, func(txStore *storeImplementation) error {
+		// The increment holds the row until the transaction ends, so the
+		// reasons of concurrent reports are appended one after the other
+		values, err := txStore.linkIncrement(ctx, id, map[string]int64{COLUMN_REPORT_COUNT: 1})
+
+		if err != nil {
+			return err
+		}
+
+		link, err := txStore.LinkFindByID(ctx, id)
+
+		if err != nil {
+			return err
+		}
+
+		reportCount = values[COLUMN_REPORT_COUNT]
+		reportedAt := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+		reports := append(LinkReports(link), LinkReportEntry{Reason: reason, ReportedAt: reportedAt})
+
+		data, err := json.Marshal(reports)
+
+		if err != nil {
+			return err
+		}
+
+		record := goqu.Record{
+			COLUMN_REPORT:      string(data),
+			COLUMN_REPORTED_AT: reportedAt,
+		}
+
+		if txStore.moderationAutoHideThreshold > 0 && reportCount >= txStore.moderationAutoHideThreshold {
+			record[COLUMN_STATUS] = LINK_STATUS_HIDDEN
+		}
+
+		sqlStr, params, errSql := goqu.Dialect(txStore.dbDriverName).
+			Update(txStore.linkTableName).
+			Prepared(true).
+			Set(record).
+			Where(goqu.C(COLUMN_ID).Eq(id)).
+			ToSQL()
+
+		if errSql != nil {
+			return errSql
+		}
+
+		if txStore.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		_, err = database.Execute(txStore.toQueryableContext(ctx), sqlStr, params...)
+
+		return err
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return reportCount, nil
+}
+
+// LinkReportedList lists the reported links matching the query, the most
+// reported first, then the most recently reported. Hidden links are
+// included, so they can be reviewed. The order is fixed, so the query
+// cannot set an order, a ranking or a cursor.
+func (st *storeImplementation) LinkReportedList(ctx context.Context, query LinkQueryInterface) ([]LinkInterface, error) {
+	if query == nil {
+		query = LinkQuery()
+	}
+
+	if err := linkReportedQueryCheck(query); err != nil {
+		return []LinkInterface{}, err
+	}
+
+	q, columns, err := query.ToSelectDataset(st)
+
+	if err != nil {
+		return []LinkInterface{}, err
+	}
+
+	sqlStr, sqlParams, errSql := q.Prepared(true).
+		Select(columns...).
+		Where(goqu.C(COLUMN_REPORT_COUNT).Gt(0)).
+		Order(
+			goqu.C(COLUMN_REPORT_COUNT).Desc(),
+			goqu.C(COLUMN_REPORTED_AT).Desc(),
+			goqu.C(COLUMN_ID).Desc(),
+		).
+		ToSQL()
+
+	if errSql != nil {
+		return []LinkInterface{}, errSql
+	}
+
+	if st.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(st.toQueryableContext(ctx), sqlStr, sqlParams...)
+
+	if err != nil {
+		return []LinkInterface{}, err
+	}
+
+	list := []LinkInterface{}
+
+	for _, modelMap := range modelMaps {
+		list = append(list, NewLinkFromExistingData(modelMap))
+	}
+
+	return list, nil
+}
+
+// LinkReportResolve resolves the reports of the link:
+//   - MODERATION_DISMISS clears the reports, and sets a hidden link active
+//   - MODERATION_HIDE clears the reports, and hides the link
+//   - MODERATION_SOFT_DELETE soft deletes the link, keeping its reports
+func (st *storeImplementation) LinkReportResolve(ctx context.Context, id string, resolution string) error {
+	if err := linkReportResolutionCheck(resolution); err != nil {
+		return err
+	}
+
+	return st.inTx(ctx, func(txStore *storeImplementation) error {
+		link, err := txStore.LinkFindByID(ctx, id)
+
+		if err != nil {
+			return err
+		}
+
+		linkReportResolve(link, resolution)
+
+		return txStore.LinkUpdate(ctx, link)
+	})
+}
+
+// linkReportResolve applies the resolution of the reports to the link,
+// without saving it
+func linkReportResolve(link LinkInterface, resolution string) {
+	clearReports := func() {
+		link.SetReport("")
+		link.SetReportCount("0")
+		link.SetReportedAt(sb.NULL_DATETIME)
+	}
+
+	switch resolution {
+	case MODERATION_DISMISS:
+		clearReports()
+
+		if link.Status() == LINK_STATUS_HIDDEN {
+			link.SetStatus(LINK_STATUS_ACTIVE)
+		}
+	case MODERATION_HIDE:
+		clearReports()
+		link.SetStatus(LINK_STATUS_HIDDEN)
+	case MODERATION_SOFT_DELETE:
+		link.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+		link.SetCascadeDeletedBy("")
+	}
+}
+
+// linkReportedQueryCheck rejects the query settings which conflict with
+// the order of the reported links
+func linkReportedQueryCheck(query LinkQueryInterface) error {
+	if query.IsOrderBySet() {
+		return newValidationError("order_by", "link query: reported links are ordered by report count")
+	}
+
+	if query.IsRankingSet() {
+		return newValidationError("ranking", "link query: reported links are ordered by report count")
+	}
+
+	if query.IsCursorSet() {
+		return newValidationError("cursor", "link query: reported links cannot be paged by cursor")
+	}
+
+	return nil
+}
+
+// linkReportResolutionCheck checks the resolution is one of the
+// MODERATION_* constants
+func linkReportResolutionCheck(resolution string) error {
+	if !lo.Contains([]string{MODERATION_DISMISS, MODERATION_HIDE, MODERATION_SOFT_DELETE}, resolution) {
+		return newValidationError("resolution", "resolution must be one of dismiss, hide or soft_delete")
+	}
+
+	return nil
+}
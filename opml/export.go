@@ -0,0 +1,113 @@
+package opml
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dracory/feedstore"
+)
+
+// Options carries the details of the exported document
+type Options struct {
+	// Title is the title of the OPML document
+	Title string
+}
+
+// Write renders the feeds as an OPML 2.0 document.
+//
+// Feeds are grouped in folders by their category, in the order in which the
+// categories first appear. Feeds without a category are placed at the top
+// level.
+func Write(w io.Writer, feeds []feedstore.FeedInterface, options Options) error {
+	doc := document{
+		Version: Version,
+		Head: head{
+			Title:       options.Title,
+			DateCreated: time.Now().UTC().Format(time.RFC1123),
+		},
+	}
+
+	root := &outline{}
+
+	for _, feed := range feeds {
+		if feed == nil {
+			continue
+		}
+
+		parent := root
+
+		for _, folder := range categorySplit(feed.Category()) {
+			if folder = strings.TrimSpace(folder); folder != "" {
+				parent = folderOutline(parent, folder)
+			}
+		}
+
+		// text is required by the specification
+		text := feed.Name()
+		if text == "" {
+			text = feed.URL()
+		}
+
+		parent.Outlines = append(parent.Outlines, outline{
+			Text:        text,
+			Title:       feed.Name(),
+			Type:        "rss",
+			XMLURL:      feed.URL(),
+			Description: feed.Description(),
+		})
+	}
+
+	doc.Body.Outlines = root.Outlines
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// Export renders the feeds matching the query as an OPML 2.0 document
+func Export(ctx context.Context, store feedstore.StoreInterface, query feedstore.FeedQueryInterface, w io.Writer, options Options) error {
+	if store == nil {
+		return errors.New("opml: store is nil")
+	}
+
+	if query == nil {
+		query = feedstore.FeedQuery()
+	}
+
+	feeds, err := store.FeedList(ctx, query)
+
+	if err != nil {
+		return err
+	}
+
+	return Write(w, feeds, options)
+}
+
+// folderOutline returns the folder with the given name below the parent,
+// adding it when it does not exist yet
+func folderOutline(parent *outline, name string) *outline {
+	for i := range parent.Outlines {
+		if parent.Outlines[i].XMLURL == "" && parent.Outlines[i].Text == name {
+			return &parent.Outlines[i]
+		}
+	}
+
+	parent.Outlines = append(parent.Outlines, outline{Text: name, Title: name})
+
+	return &parent.Outlines[len(parent.Outlines)-1]
+}
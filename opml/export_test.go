@@ -0,0 +1,109 @@
+package opml
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dracory/feedstore"
+)
+
+func TestWrite(t *testing.T) {
+	topLevel := feedstore.NewFeed()
+	topLevel.SetName("Top Level").SetURL("https://example.com/top.xml")
+
+	goBlog := feedstore.NewFeed()
+	goBlog.SetName("Go Blog").SetURL("https://go.dev/blog/feed.atom").SetCategory("Tech")
+
+	sqlite := feedstore.NewFeed()
+	sqlite.SetName("").SetURL("https://sqlite.example.com/news.rss").SetCategory("Tech/Databases")
+
+	buffer := bytes.Buffer{}
+	err := Write(&buffer, []feedstore.FeedInterface{topLevel, goBlog, sqlite}, Options{Title: "Export"})
+	if err != nil {
+		t.Fatalf("Write should succeed, but got error: %v", err)
+	}
+
+	output := buffer.String()
+	if !strings.HasPrefix(output, "<?xml") {
+		t.Errorf("Output should start with the XML header, got '%s'", output)
+	}
+	if !strings.Contains(output, `<opml version="2.0">`) {
+		t.Errorf("Output should be an OPML 2.0 document, got '%s'", output)
+	}
+	if !strings.Contains(output, `text="https://sqlite.example.com/news.rss"`) {
+		t.Errorf("Feeds without a name should use the URL as text, got '%s'", output)
+	}
+
+	// The document must round trip through Parse, folders included
+	feeds, err := Parse(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("Parse of the written document should succeed, but got error: %v", err)
+	}
+	if len(feeds) != 3 {
+		t.Fatalf("Expected 3 feeds after round trip, got %d", len(feeds))
+	}
+
+	expectedCategories := []string{"", "Tech", "Tech/Databases"}
+	for i, feed := range feeds {
+		if feed.Category() != expectedCategories[i] {
+			t.Errorf("Feed %d category: expected '%s', got '%s'", i, expectedCategories[i], feed.Category())
+		}
+	}
+	if feeds[1].Name() != "Go Blog" || feeds[1].URL() != "https://go.dev/blog/feed.atom" {
+		t.Errorf("Feed should round trip, got name '%s' and URL '%s'", feeds[1].Name(), feeds[1].URL())
+	}
+}
+
+func TestExport(t *testing.T) {
+	store := createTestStore(t)
+	ctx := context.Background()
+
+	active := feedstore.NewFeed()
+	active.SetName("Active").SetURL("https://example.com/active.xml").SetStatus(feedstore.FEED_STATUS_ACTIVE)
+	inactive := feedstore.NewFeed()
+	inactive.SetName("Inactive").SetURL("https://example.com/inactive.xml")
+
+	for _, feed := range []feedstore.FeedInterface{active, inactive} {
+		if err := store.FeedCreate(ctx, feed); err != nil {
+			t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+		}
+	}
+
+	buffer := bytes.Buffer{}
+	err := Export(ctx, store, feedstore.FeedQuery().SetStatus(feedstore.FEED_STATUS_ACTIVE), &buffer, Options{})
+	if err != nil {
+		t.Fatalf("Export should succeed, but got error: %v", err)
+	}
+
+	if !strings.Contains(buffer.String(), "https://example.com/active.xml") {
+		t.Errorf("Export should contain the matching feed, got '%s'", buffer.String())
+	}
+	if strings.Contains(buffer.String(), "https://example.com/inactive.xml") {
+		t.Errorf("Export should not contain feeds outside the query, got '%s'", buffer.String())
+	}
+}
+
+func TestWriteFolderWithSeparator(t *testing.T) {
+	film := feedstore.NewFeed()
+	film.SetName("Reviews").SetURL("https://example.com/reviews.xml").SetCategory(`Media/TV\/Film`)
+
+	buffer := bytes.Buffer{}
+	if err := Write(&buffer, []feedstore.FeedInterface{film}, Options{}); err != nil {
+		t.Fatalf("Write should succeed, but got error: %v", err)
+	}
+
+	output := buffer.String()
+	if !strings.Contains(output, `<outline text="TV/Film" title="TV/Film">`) {
+		t.Errorf("Expected the escaped separator to stay in one folder, got '%s'", output)
+	}
+
+	feeds, err := Parse(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("Parse of the written document should succeed, but got error: %v", err)
+	}
+	if len(feeds) != 1 || feeds[0].Category() != `Media/TV\/Film` {
+		t.Errorf("Expected the category to round trip, got %v", feeds)
+	}
+}
@@ -0,0 +1,131 @@
+package opml
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/dracory/feedstore"
+)
+
+// ImportResult reports the outcome of an import
+type ImportResult struct {
+	// Created are the feeds which were created
+	Created []feedstore.FeedInterface
+
+	// Skipped are the URLs of the feeds which already existed
+	Skipped []string
+}
+
+// Parse reads an OPML document and returns a new, unsaved feed for every
+// outline with an xmlUrl.
+//
+// The name of a feed is taken from the title attribute, falling back to
+// text. The feeds are active, so they are picked up by the fetcher once
+// they are stored.
+func Parse(r io.Reader) ([]feedstore.FeedInterface, error) {
+	doc := document{}
+
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	if doc.XMLName.Local != "opml" {
+		return nil, errors.New("opml: document is not an OPML document")
+	}
+
+	feeds := []feedstore.FeedInterface{}
+
+	collectFeeds(doc.Body.Outlines, []string{}, &feeds)
+
+	return feeds, nil
+}
+
+// Import reads an OPML document and creates its feeds in the store.
+//
+// Feeds whose URL already exists in the store, soft deleted feeds included,
+// or which appear earlier in the same document, are skipped.
+func Import(ctx context.Context, store feedstore.StoreInterface, r io.Reader) (ImportResult, error) {
+	result := ImportResult{
+		Created: []feedstore.FeedInterface{},
+		Skipped: []string{},
+	}
+
+	if store == nil {
+		return result, errors.New("opml: store is nil")
+	}
+
+	feeds, err := Parse(r)
+
+	if err != nil {
+		return result, err
+	}
+
+	seen := map[string]bool{}
+
+	for _, feed := range feeds {
+		if seen[feed.URL()] {
+			result.Skipped = append(result.Skipped, feed.URL())
+			continue
+		}
+
+		seen[feed.URL()] = true
+
+		count, err := store.FeedCount(ctx, feedstore.FeedQuery().SetURL(feed.URL()).SetWithSoftDeleted(true))
+
+		if err != nil {
+			return result, err
+		}
+
+		if count > 0 {
+			result.Skipped = append(result.Skipped, feed.URL())
+			continue
+		}
+
+		if err := store.FeedCreate(ctx, feed); err != nil {
+			return result, err
+		}
+
+		result.Created = append(result.Created, feed)
+	}
+
+	return result, nil
+}
+
+// collectFeeds walks the outlines depth first, folders contribute their
+// name to the category of the feeds they contain
+func collectFeeds(outlines []outline, folders []string, feeds *[]feedstore.FeedInterface) {
+	for _, item := range outlines {
+		name := strings.TrimSpace(item.Title)
+		if name == "" {
+			name = strings.TrimSpace(item.Text)
+		}
+
+		url := strings.TrimSpace(item.XMLURL)
+
+		if url == "" {
+			if name == "" {
+				collectFeeds(item.Outlines, folders, feeds)
+			} else {
+				collectFeeds(item.Outlines, append(folders[:len(folders):len(folders)], name), feeds)
+			}
+
+			continue
+		}
+
+		feed := feedstore.NewFeed()
+		feed.SetStatus(feedstore.FEED_STATUS_ACTIVE).
+			SetName(name).
+			SetURL(url).
+			SetDescription(strings.TrimSpace(item.Description)).
+			SetCategory(categoryJoin(folders))
+
+		*feeds = append(*feeds, feed)
+	}
+}
@@ -0,0 +1,201 @@
+package opml
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dracory/feedstore"
+
+	_ "modernc.org/sqlite"
+)
+
+// Helper function to create a store backed by an in-memory SQLite database
+func createTestStore(t *testing.T) feedstore.StoreInterface {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	store, err := feedstore.NewStore(feedstore.NewStoreOptions{
+		DB:                 db,
+		FeedTableName:      "feeds_opml",
+		LinkTableName:      "links_opml",
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore should not return an error, but got: %v", err)
+	}
+
+	return store
+}
+
+// Helper function to open a fixture file from the testdata directory
+func openFixture(t *testing.T, name string) *os.File {
+	t.Helper()
+
+	file, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatalf("Opening fixture %s failed: %v", name, err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	return file
+}
+
+func TestParse(t *testing.T) {
+	feeds, err := Parse(openFixture(t, "subscriptions.opml"))
+	if err != nil {
+		t.Fatalf("Parse should succeed, but got error: %v", err)
+	}
+	if len(feeds) != 5 {
+		t.Fatalf("Expected 5 feeds, got %d", len(feeds))
+	}
+
+	testCases := []struct {
+		name     string
+		url      string
+		category string
+	}{
+		{name: "Top Level Feed", url: "https://example.com/top.xml", category: ""},
+		{name: "Go Blog", url: "https://go.dev/blog/feed.atom", category: "Tech"},
+		{name: "SQLite News", url: "https://sqlite.example.com/news.rss", category: "Tech/Databases"},
+		{name: "Duplicate Go Blog", url: "https://go.dev/blog/feed.atom", category: "News & Politics"},
+		{name: "Daily News", url: "https://news.example.com/daily.xml", category: "News & Politics"},
+	}
+
+	for i, tc := range testCases {
+		feed := feeds[i]
+		if feed.Name() != tc.name {
+			t.Errorf("Feed %d name: expected '%s', got '%s'", i, tc.name, feed.Name())
+		}
+		if feed.URL() != tc.url {
+			t.Errorf("Feed %d URL: expected '%s', got '%s'", i, tc.url, feed.URL())
+		}
+		if feed.Category() != tc.category {
+			t.Errorf("Feed %d category: expected '%s', got '%s'", i, tc.category, feed.Category())
+		}
+		if feed.Status() != feedstore.FEED_STATUS_ACTIVE {
+			t.Errorf("Feed %d status: expected '%s', got '%s'", i, feedstore.FEED_STATUS_ACTIVE, feed.Status())
+		}
+	}
+
+	if feeds[0].Description() != "Not in a folder" {
+		t.Errorf("Feed description: expected 'Not in a folder', got '%s'", feeds[0].Description())
+	}
+}
+
+func TestParseNotOPML(t *testing.T) {
+	_, err := Parse(strings.NewReader(`<rss version="2.0"><channel></channel></rss>`))
+	if err == nil {
+		t.Error("Parse should return an error for a document which is not OPML")
+	}
+}
+
+func TestImport(t *testing.T) {
+	store := createTestStore(t)
+	ctx := context.Background()
+
+	existing := feedstore.NewFeed()
+	existing.SetName("Existing").SetURL("https://news.example.com/daily.xml")
+	if err := store.FeedCreate(ctx, existing); err != nil {
+		t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+	}
+
+	result, err := Import(ctx, store, openFixture(t, "subscriptions.opml"))
+	if err != nil {
+		t.Fatalf("Import should succeed, but got error: %v", err)
+	}
+	if len(result.Created) != 3 {
+		t.Errorf("Expected 3 feeds to be created, got %d", len(result.Created))
+	}
+	if len(result.Skipped) != 2 {
+		t.Errorf("Expected 2 feeds to be skipped (duplicate and existing), got %d: %v", len(result.Skipped), result.Skipped)
+	}
+
+	count, err := store.FeedCount(ctx, feedstore.FeedQuery())
+	if err != nil {
+		t.Fatalf("FeedCount should succeed, but got error: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Expected 4 stored feeds, got %d", count)
+	}
+
+	feeds, err := store.FeedList(ctx, feedstore.FeedQuery().SetCategory("Tech/Databases"))
+	if err != nil {
+		t.Fatalf("FeedList should succeed, but got error: %v", err)
+	}
+	if len(feeds) != 1 || feeds[0].Name() != "SQLite News" {
+		t.Errorf("Expected the nested feed to be stored with its category, got %v", feeds)
+	}
+
+	// Importing again creates nothing
+	result, err = Import(ctx, store, openFixture(t, "subscriptions.opml"))
+	if err != nil {
+		t.Fatalf("Second Import should succeed, but got error: %v", err)
+	}
+	if len(result.Created) != 0 {
+		t.Errorf("Expected no feeds to be created on the second import, got %d", len(result.Created))
+	}
+}
+
+func TestImportSkipsSoftDeletedFeeds(t *testing.T) {
+	store := createTestStore(t)
+	ctx := context.Background()
+
+	removed := feedstore.NewFeed()
+	removed.SetName("Removed").SetURL("https://example.com/top.xml")
+	if err := store.FeedCreate(ctx, removed); err != nil {
+		t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+	}
+	if err := store.FeedSoftDelete(ctx, removed); err != nil {
+		t.Fatalf("FeedSoftDelete should succeed, but got error: %v", err)
+	}
+
+	result, err := Import(ctx, store, openFixture(t, "subscriptions.opml"))
+	if err != nil {
+		t.Fatalf("Import should succeed, but got error: %v", err)
+	}
+	if len(result.Created) != 3 {
+		t.Errorf("Expected 3 feeds to be created, got %d", len(result.Created))
+	}
+	if !slices.Contains(result.Skipped, "https://example.com/top.xml") {
+		t.Errorf("Expected the soft deleted feed to be skipped, got %v", result.Skipped)
+	}
+
+	count, err := store.FeedCount(ctx, feedstore.FeedQuery().SetURL("https://example.com/top.xml").SetWithSoftDeleted(true))
+	if err != nil {
+		t.Fatalf("FeedCount should succeed, but got error: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected the soft deleted feed not to be created again, got %d feeds", count)
+	}
+}
+
+func TestParseFolderWithSeparator(t *testing.T) {
+	doc := `<opml version="2.0"><body>
+		<outline text="TV/Film"><outline text="Reviews" type="rss" xmlUrl="https://example.com/reviews.xml"/></outline>
+		<outline text="C:\Users"><outline text="Tips" type="rss" xmlUrl="https://example.com/tips.xml"/></outline>
+	</body></opml>`
+
+	feeds, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Parse should succeed, but got error: %v", err)
+	}
+	if len(feeds) != 2 {
+		t.Fatalf("Expected 2 feeds, got %d", len(feeds))
+	}
+	if feeds[0].Category() != `TV\/Film` {
+		t.Errorf("Expected the separator in the folder name to be escaped, got '%s'", feeds[0].Category())
+	}
+	if feeds[1].Category() != `C:\\Users` {
+		t.Errorf("Expected the escape character in the folder name to be escaped, got '%s'", feeds[1].Category())
+	}
+}
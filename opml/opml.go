@@ -0,0 +1,93 @@
+// Package opml imports and exports feed subscriptions as OPML 2.0 documents.
+//
+// Folders (outlines without an xmlUrl that contain other outlines) map to
+// the category of a feed. Nested folders are joined with CategorySeparator,
+// so a feed in the "Go" folder inside "Tech" gets the category "Tech/Go".
+// A separator within a folder name is escaped with a backslash, so the
+// folder "TV/Film" gets the category "TV\/Film".
+package opml
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// CategorySeparator joins the names of nested folders into a category
+const CategorySeparator = "/"
+
+// categoryEscape is the escape character of a separator within a folder
+// name, it escapes itself too
+const categoryEscape = `\`
+
+var categoryEscaper = strings.NewReplacer(
+	categoryEscape, categoryEscape+categoryEscape,
+	CategorySeparator, categoryEscape+CategorySeparator)
+
+// Version is the OPML specification version produced
+const Version = "2.0"
+
+type document struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    head     `xml:"head"`
+	Body    body     `xml:"body"`
+}
+
+type head struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type body struct {
+	Outlines []outline `xml:"outline"`
+}
+
+type outline struct {
+	Text        string    `xml:"text,attr"`
+	Title       string    `xml:"title,attr,omitempty"`
+	Type        string    `xml:"type,attr,omitempty"`
+	XMLURL      string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL     string    `xml:"htmlUrl,attr,omitempty"`
+	Description string    `xml:"description,attr,omitempty"`
+	Outlines    []outline `xml:"outline"`
+}
+
+// categoryJoin joins the names of nested folders into a category, escaping
+// the separators and escape characters within the names
+func categoryJoin(folders []string) string {
+	escaped := make([]string, 0, len(folders))
+
+	for _, folder := range folders {
+		escaped = append(escaped, categoryEscaper.Replace(folder))
+	}
+
+	return strings.Join(escaped, CategorySeparator)
+}
+
+// categorySplit splits a category into the names of its nested folders, it
+// reverses categoryJoin. An escape character which escapes neither a
+// separator nor itself is kept as it is.
+func categorySplit(category string) []string {
+	folders := []string{}
+	folder := strings.Builder{}
+
+	for rest := category; rest != ""; {
+		switch {
+		case strings.HasPrefix(rest, categoryEscape+CategorySeparator):
+			folder.WriteString(CategorySeparator)
+			rest = rest[len(categoryEscape+CategorySeparator):]
+		case strings.HasPrefix(rest, categoryEscape+categoryEscape):
+			folder.WriteString(categoryEscape)
+			rest = rest[len(categoryEscape+categoryEscape):]
+		case strings.HasPrefix(rest, CategorySeparator):
+			folders = append(folders, folder.String())
+			folder.Reset()
+			rest = rest[len(CategorySeparator):]
+		default:
+			folder.WriteByte(rest[0])
+			rest = rest[1:]
+		}
+	}
+
+	return append(folders, folder.String())
+}
@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/dracory/feedstore"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type atomFeed struct {
+	XMLName  xml.Name
+	Base     string      `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+	Title    atomText    `xml:"title"`
+	Subtitle atomText    `xml:"subtitle"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Base      string     `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+	ID        string     `xml:"id"`
+	Title     atomText   `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Summary   atomText   `xml:"summary"`
+	Content   atomText   `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+type atomLink struct {
+	Base string `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type atomText struct {
+	Type     string `xml:"type,attr"`
+	Value    string `xml:",chardata"`
+	InnerXML string `xml:",innerxml"`
+}
+
+// String returns the text content, keeping the markup of xhtml constructs
+func (text atomText) String() string {
+	if strings.EqualFold(text.Type, "xhtml") {
+		return strings.TrimSpace(text.InnerXML)
+	}
+
+	return strings.TrimSpace(text.Value)
+}
+
+// ParseAtom parses an Atom 1.0 document.
+//
+// The link of an entry is its rel="alternate" link (preferring text/html),
+// resolved against any xml:base in scope. Entries without an alternate link
+// fall back to their id when it is a URL. The link time is the published
+// date, or the updated date when the entry was never published.
+func ParseAtom(r io.Reader) (*Result, error) {
+	doc := atomFeed{}
+
+	if err := newXMLDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	if doc.XMLName.Local != "feed" || (doc.XMLName.Space != "" && doc.XMLName.Space != atomNamespace) {
+		return nil, errors.New("parser: document is not an Atom feed")
+	}
+
+	feedBase := resolveURL("", doc.Base)
+
+	result := &Result{
+		Title:       doc.Title.String(),
+		Description: doc.Subtitle.String(),
+		SiteURL:     atomAlternateLink(feedBase, doc.Links),
+		Links:       []feedstore.LinkInterface{},
+	}
+
+	for _, entry := range doc.Entries {
+		entryBase := resolveURL(feedBase, entry.Base)
+
+		link := atomAlternateLink(entryBase, entry.Links)
+
+		id := strings.TrimSpace(entry.ID)
+		if link == "" && (strings.HasPrefix(id, "http://") || strings.HasPrefix(id, "https://")) {
+			link = id
+		}
+
+		description := firstNonEmpty(entry.Summary.String(), entry.Content.String())
+		title := firstNonEmpty(entry.Title.String(), link)
+
+		if title == "" && description == "" {
+			continue
+		}
+
+		time := parseTime(firstNonEmpty(entry.Published, entry.Updated))
+
+		result.Links = append(result.Links, newLink(id, title, link, description, time))
+	}
+
+	return result, nil
+}
+
+// atomAlternateLink picks the alternate link among the links of an element.
+//
+// A missing rel attribute means "alternate" as per RFC 4287. Among several
+// alternate links the text/html one wins.
+func atomAlternateLink(base string, links []atomLink) string {
+	alternate := ""
+
+	for _, link := range links {
+		rel := strings.ToLower(strings.TrimSpace(link.Rel))
+
+		if rel != "" && rel != "alternate" {
+			continue
+		}
+
+		if strings.TrimSpace(link.Href) == "" {
+			continue
+		}
+
+		href := resolveURL(resolveURL(base, link.Base), link.Href)
+
+		linkType := strings.ToLower(strings.TrimSpace(link.Type))
+		if linkType == "" || strings.HasPrefix(linkType, "text/html") {
+			return href
+		}
+
+		if alternate == "" {
+			alternate = href
+		}
+	}
+
+	return alternate
+}
+
+// resolveURL resolves a possibly relative reference against a base URL.
+// When either cannot be parsed the reference is returned unchanged.
+func resolveURL(base string, reference string) string {
+	reference = strings.TrimSpace(reference)
+
+	if reference == "" {
+		return base
+	}
+
+	if base == "" {
+		return reference
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return reference
+	}
+
+	referenceURL, err := url.Parse(reference)
+	if err != nil {
+		return reference
+	}
+
+	return baseURL.ResolveReference(referenceURL).String()
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dracory/sb"
+)
+
+func TestParseAtom(t *testing.T) {
+	result := parseFixture(t, "atom_valid.xml", func(f *os.File) (*Result, error) { return ParseAtom(f) })
+
+	if result.Title != "Example Atom Blog" {
+		t.Errorf("Title: expected 'Example Atom Blog', got '%s'", result.Title)
+	}
+	if result.Description != "Thoughts in Atom" {
+		t.Errorf("Description: expected 'Thoughts in Atom', got '%s'", result.Description)
+	}
+	if result.SiteURL != "https://example.org/blog/" {
+		t.Errorf("SiteURL: expected 'https://example.org/blog/' (not the self link), got '%s'", result.SiteURL)
+	}
+	if len(result.Links) != 3 {
+		t.Fatalf("Expected 3 links, got %d", len(result.Links))
+	}
+
+	first := result.Links[0]
+	if first.URL() != "https://example.org/blog/posts/1" {
+		t.Errorf("Link URL: expected text/html alternate resolved against xml:base, got '%s'", first.URL())
+	}
+	if first.Description() != "Summary of the first entry" {
+		t.Errorf("Link description: expected summary, got '%s'", first.Description())
+	}
+	if first.Time() != "2006-01-04 08:00:00" {
+		t.Errorf("Link time: expected published date in UTC '2006-01-04 08:00:00', got '%s'", first.Time())
+	}
+	if first.GUID() != "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a" {
+		t.Errorf("Link GUID: expected entry id, got '%s'", first.GUID())
+	}
+
+	second := result.Links[1]
+	if second.Title() != "Entry <em>Base</em>" {
+		t.Errorf("Link title: expected unescaped html title, got '%s'", second.Title())
+	}
+	if second.URL() != "https://other.example.org/archive/2006/second" {
+		t.Errorf("Link URL: expected link resolved against entry xml:base, got '%s'", second.URL())
+	}
+	if !strings.Contains(second.Description(), "<p>Content only</p>") {
+		t.Errorf("Link description: expected xhtml content fallback, got '%s'", second.Description())
+	}
+	if second.Time() != "2006-01-06 09:00:00" {
+		t.Errorf("Link time: expected updated date fallback, got '%s'", second.Time())
+	}
+
+	third := result.Links[2]
+	if third.URL() != "https://example.org/blog/posts/3" {
+		t.Errorf("Link URL: expected id fallback when no alternate link, got '%s'", third.URL())
+	}
+	if third.Time() != sb.NULL_DATETIME {
+		t.Errorf("Link time: expected '%s' for an invalid date, got '%s'", sb.NULL_DATETIME, third.Time())
+	}
+}
+
+func TestParseAtomNotAtom(t *testing.T) {
+	_, err := ParseAtom(strings.NewReader(`<rss version="2.0"><channel></channel></rss>`))
+	if err == nil {
+		t.Error("ParseAtom should return an error for a non Atom document")
+	}
+}
@@ -0,0 +1,27 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// charsetReader converts the single byte encodings still used by older feeds
+// into UTF-8, so they can be consumed by encoding/xml.
+//
+// Windows-1252 is decoded on its own, it differs from ISO-8859-1 in the
+// bytes 0x80 to 0x9F, which hold the curly quotes, dashes and the euro sign.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1":
+		return charmap.ISO8859_1.NewDecoder().Reader(input), nil
+	case "windows-1252", "cp1252":
+		return charmap.Windows1252.NewDecoder().Reader(input), nil
+	}
+
+	return nil, fmt.Errorf("parser: unsupported charset %q", charset)
+}
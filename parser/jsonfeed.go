@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/dracory/feedstore"
+)
+
+type jsonFeedDocument struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            json.RawMessage `json:"id"`
+	URL           string          `json:"url"`
+	ExternalURL   string          `json:"external_url"`
+	Title         string          `json:"title"`
+	Summary       string          `json:"summary"`
+	ContentHTML   string          `json:"content_html"`
+	ContentText   string          `json:"content_text"`
+	DatePublished string          `json:"date_published"`
+	DateModified  string          `json:"date_modified"`
+}
+
+// ParseJSONFeed parses a JSON Feed (version 1 or 1.1) document.
+//
+// The description of a link is the item summary, falling back to its HTML
+// or text content. Items without a url fall back to external_url, and then
+// to the id when it is a URL.
+func ParseJSONFeed(r io.Reader) (*Result, error) {
+	doc := jsonFeedDocument{}
+
+	// encoding/json rejects a byte order mark, which some servers send
+	reader := bufio.NewReader(r)
+
+	if bom, _ := reader.Peek(len(utf8BOM)); bytes.Equal(bom, utf8BOM) {
+		_, _ = reader.Discard(len(utf8BOM))
+	}
+
+	if err := json.NewDecoder(reader).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/1") {
+		return nil, errors.New("parser: document is not a JSON Feed")
+	}
+
+	result := &Result{
+		Title:       strings.TrimSpace(doc.Title),
+		Description: strings.TrimSpace(doc.Description),
+		SiteURL:     strings.TrimSpace(doc.HomePageURL),
+		Links:       []feedstore.LinkInterface{},
+	}
+
+	for _, item := range doc.Items {
+		id := jsonFeedItemID(item.ID)
+
+		url := firstNonEmpty(item.URL, item.ExternalURL)
+		if url == "" && (strings.HasPrefix(id, "http://") || strings.HasPrefix(id, "https://")) {
+			url = id
+		}
+
+		description := firstNonEmpty(item.Summary, item.ContentHTML, item.ContentText)
+		title := firstNonEmpty(item.Title, url)
+
+		if title == "" && description == "" {
+			continue
+		}
+
+		time := parseTime(firstNonEmpty(item.DatePublished, item.DateModified))
+
+		result.Links = append(result.Links, newLink(id, title, url, description, time))
+	}
+
+	return result, nil
+}
+
+// jsonFeedItemID returns the item id as a string. The specification requires
+// a string, but numeric ids are common in the wild.
+func jsonFeedItemID(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	id := ""
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return strings.TrimSpace(id)
+	}
+
+	return strings.TrimSpace(string(raw))
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dracory/sb"
+)
+
+func TestParseJSONFeed(t *testing.T) {
+	result := parseFixture(t, "jsonfeed_valid.json", func(f *os.File) (*Result, error) { return ParseJSONFeed(f) })
+
+	if result.Title != "Example JSON Feed" {
+		t.Errorf("Title: expected 'Example JSON Feed', got '%s'", result.Title)
+	}
+	if result.Description != "A feed in JSON" {
+		t.Errorf("Description: expected 'A feed in JSON', got '%s'", result.Description)
+	}
+	if result.SiteURL != "https://example.net/" {
+		t.Errorf("SiteURL: expected 'https://example.net/', got '%s'", result.SiteURL)
+	}
+	if len(result.Links) != 3 {
+		t.Fatalf("Expected 3 links, got %d", len(result.Links))
+	}
+
+	first := result.Links[0]
+	if first.Title() != "First JSON Post" {
+		t.Errorf("Link title: expected 'First JSON Post', got '%s'", first.Title())
+	}
+	if first.Description() != "Summary of the first post" {
+		t.Errorf("Link description: expected summary, got '%s'", first.Description())
+	}
+	if first.Time() != "2006-01-02 22:04:05" {
+		t.Errorf("Link time: expected '2006-01-02 22:04:05', got '%s'", first.Time())
+	}
+
+	second := result.Links[1]
+	if second.URL() != "https://elsewhere.example.com/article" {
+		t.Errorf("Link URL: expected external_url fallback, got '%s'", second.URL())
+	}
+	if second.Description() != "A post without a title" {
+		t.Errorf("Link description: expected content_text fallback, got '%s'", second.Description())
+	}
+	if second.Time() != "2006-01-03 10:00:00" {
+		t.Errorf("Link time: expected date_modified fallback, got '%s'", second.Time())
+	}
+	if second.GUID() != "2" {
+		t.Errorf("Link GUID: expected numeric id '2', got '%s'", second.GUID())
+	}
+
+	third := result.Links[2]
+	if third.URL() != "https://example.net/posts/3" {
+		t.Errorf("Link URL: expected id fallback, got '%s'", third.URL())
+	}
+	if third.Time() != sb.NULL_DATETIME {
+		t.Errorf("Link time: expected '%s' for an invalid date, got '%s'", sb.NULL_DATETIME, third.Time())
+	}
+}
+
+func TestParseJSONFeedNotJSONFeed(t *testing.T) {
+	_, err := ParseJSONFeed(strings.NewReader(`{"title": "Just some JSON"}`))
+	if err == nil {
+		t.Error("ParseJSONFeed should return an error for a document without a JSON Feed version")
+	}
+}
+
+func TestParseJSONFeedByteOrderMark(t *testing.T) {
+	result := parseFixture(t, "jsonfeed_bom.json", func(f *os.File) (*Result, error) { return ParseJSONFeed(f) })
+
+	if result.Title != "Example JSON Feed" {
+		t.Errorf("Title: expected 'Example JSON Feed', got '%s'", result.Title)
+	}
+	if len(result.Links) != 3 {
+		t.Errorf("Expected 3 links, got %d", len(result.Links))
+	}
+}
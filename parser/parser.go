@@ -0,0 +1,131 @@
+// Package parser turns fetched feed documents into feedstore links.
+//
+// Every supported format is normalized into the same Result type, so the
+// caller does not need to know which format a feed is published in.
+package parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/dracory/feedstore"
+	"github.com/dracory/sb"
+)
+
+// newXMLDecoder returns a lenient XML decoder, as real world feeds
+// are often not well-formed
+func newXMLDecoder(r io.Reader) *xml.Decoder {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = charsetReader
+	return decoder
+}
+
+// newLink creates an active link from the normalized entry fields.
+// The URL is used as GUID for entries which do not carry an identifier,
+// and a hash of the content for entries without a URL either, so the
+// entry keeps its GUID from one fetch to the next.
+func newLink(guid, title, url, description, time string) feedstore.LinkInterface {
+	if time == "" {
+		time = sb.NULL_DATETIME
+	}
+
+	link := feedstore.NewLink().
+		SetStatus(feedstore.LINK_STATUS_ACTIVE).
+		SetTitle(title).
+		SetURL(url).
+		SetDescription(description).
+		SetTime(time)
+
+	link.SetGUID(firstNonEmpty(guid, url, contentGUID(title, description, time)))
+
+	return link
+}
+
+// contentGUID derives a GUID from the content of an entry
+func contentGUID(title, description, time string) string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{title, description, time}, "\x00")))
+
+	return "sha256:" + hex.EncodeToString(hash[:])
+}
+
+// firstNonEmpty returns the first of the values which is not blank
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// Parse detects the format of the document and parses it accordingly
+func Parse(r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch detectFormat(data) {
+	case formatRSS:
+		return ParseRSS(bytes.NewReader(data))
+	case formatAtom:
+		return ParseAtom(bytes.NewReader(data))
+	case formatJSONFeed:
+		return ParseJSONFeed(bytes.NewReader(data))
+	}
+
+	return nil, errors.New("parser: unsupported feed format")
+}
+
+// utf8BOM is the UTF-8 byte order mark some documents start with
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+const (
+	formatUnknown  = ""
+	formatRSS      = "rss"
+	formatAtom     = "atom"
+	formatJSONFeed = "jsonfeed"
+)
+
+// detectFormat looks at the opening of the document (JSON object or XML root element)
+func detectFormat(data []byte) string {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, utf8BOM), " \t\r\n")
+
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return formatJSONFeed
+	}
+
+	decoder := newXMLDecoder(bytes.NewReader(data))
+
+	for {
+		token, err := decoder.Token()
+
+		if err != nil {
+			return formatUnknown
+		}
+
+		element, ok := token.(xml.StartElement)
+
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(element.Name.Local) {
+		case "rss":
+			return formatRSS
+		case "feed":
+			return formatAtom
+		}
+
+		return formatUnknown
+	}
+}
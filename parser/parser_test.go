@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// Helper function to parse a fixture file from the testdata directory
+func parseFixture(t *testing.T, name string, parse func(f *os.File) (*Result, error)) *Result {
+	t.Helper()
+
+	file, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatalf("Opening fixture %s failed: %v", name, err)
+	}
+	defer file.Close()
+
+	result, err := parse(file)
+	if err != nil {
+		t.Fatalf("Parsing fixture %s should succeed, but got error: %v", name, err)
+	}
+
+	return result
+}
+
+func TestParseDetectsFormat(t *testing.T) {
+	testCases := []struct {
+		fixture       string
+		expectedTitle string
+	}{
+		{fixture: "rss_valid.xml", expectedTitle: "Example Blog"},
+		{fixture: "atom_valid.xml", expectedTitle: "Example Atom Blog"},
+		{fixture: "jsonfeed_valid.json", expectedTitle: "Example JSON Feed"},
+		{fixture: "jsonfeed_bom.json", expectedTitle: "Example JSON Feed"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.fixture, func(t *testing.T) {
+			result := parseFixture(t, tc.fixture, func(f *os.File) (*Result, error) { return Parse(f) })
+
+			if result.Title != tc.expectedTitle {
+				t.Errorf("Title: expected '%s', got '%s'", tc.expectedTitle, result.Title)
+			}
+		})
+	}
+}
+
+func TestParseUnsupportedFormat(t *testing.T) {
+	_, err := Parse(strings.NewReader(`<html><body>Not a feed</body></html>`))
+	if err == nil {
+		t.Error("Parse should return an error for an unsupported document")
+	}
+}
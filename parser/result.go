@@ -0,0 +1,43 @@
+package parser
+
+import "github.com/dracory/feedstore"
+
+// Result is the normalized outcome of parsing a feed document, independent
+// of the format the document was published in
+type Result struct {
+	// Title is the title of the channel
+	Title string
+
+	// Description is the description (or subtitle) of the channel
+	Description string
+
+	// SiteURL is the URL of the website the channel belongs to
+	SiteURL string
+
+	// Links are the entries of the channel, ready to be passed to LinkCreate
+	// once a feed ID has been assigned (see ApplyTo)
+	Links []feedstore.LinkInterface
+}
+
+// ApplyTo copies the channel metadata onto the feed and assigns the feed ID
+// to every parsed link.
+//
+// The name and description of the feed are only filled in when empty, so
+// values set by the user are never overwritten.
+func (result *Result) ApplyTo(feed feedstore.FeedInterface) {
+	if feed == nil {
+		return
+	}
+
+	if feed.Name() == "" && result.Title != "" {
+		feed.SetName(result.Title)
+	}
+
+	if feed.Description() == "" && result.Description != "" {
+		feed.SetDescription(result.Description)
+	}
+
+	for _, link := range result.Links {
+		link.SetFeedID(feed.ID())
+	}
+}
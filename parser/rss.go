@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/dracory/feedstore"
+)
+
+type rssDocument struct {
+	XMLName xml.Name
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Links       []rssLink `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title          string    `xml:"title"`
+	Links          []rssLink `xml:"link"`
+	Description    string    `xml:"description"`
+	ContentEncoded string    `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate        string    `xml:"pubDate"`
+	DCDate         string    `xml:"http://purl.org/dc/elements/1.1/ date"`
+	GUID           rssGUID   `xml:"guid"`
+}
+
+// rssLink captures both the plain RSS <link> and namespaced variants such as
+// <atom:link rel="self">, which encoding/xml would otherwise mix up
+type rssLink struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink string `xml:"isPermaLink,attr"`
+}
+
+// ParseRSS parses an RSS 2.0 document.
+//
+// Missing titles fall back to the link URL, missing links fall back to a
+// permalink guid, and unparseable publication dates are stored as
+// sb.NULL_DATETIME. Items without a title, link and description are skipped.
+func ParseRSS(r io.Reader) (*Result, error) {
+	doc := rssDocument{}
+
+	if err := newXMLDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(doc.XMLName.Local, "rss") {
+		return nil, errors.New("parser: document is not an RSS feed")
+	}
+
+	result := &Result{
+		Title:       strings.TrimSpace(doc.Channel.Title),
+		Description: strings.TrimSpace(doc.Channel.Description),
+		SiteURL:     rssLinkValue(doc.Channel.Links),
+		Links:       []feedstore.LinkInterface{},
+	}
+
+	for _, item := range doc.Channel.Items {
+		url := rssLinkValue(item.Links)
+
+		if url == "" && isPermaLink(item.GUID) {
+			url = strings.TrimSpace(item.GUID.Value)
+		}
+
+		description := firstNonEmpty(item.Description, item.ContentEncoded)
+		title := firstNonEmpty(item.Title, url)
+
+		if title == "" && description == "" {
+			continue
+		}
+
+		time := parseTime(firstNonEmpty(item.PubDate, item.DCDate))
+
+		result.Links = append(result.Links, newLink(item.GUID.Value, title, url, description, time))
+	}
+
+	return result, nil
+}
+
+// rssLinkValue returns the first non-namespaced link
+func rssLinkValue(links []rssLink) string {
+	for _, link := range links {
+		if link.XMLName.Space != "" {
+			continue
+		}
+
+		if value := strings.TrimSpace(link.Value); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// isPermaLink reports whether the guid can be used as the item URL
+func isPermaLink(guid rssGUID) bool {
+	if strings.EqualFold(strings.TrimSpace(guid.IsPermaLink), "false") {
+		return false
+	}
+
+	value := strings.TrimSpace(guid.Value)
+
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
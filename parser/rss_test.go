@@ -0,0 +1,173 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dracory/feedstore"
+	"github.com/dracory/sb"
+)
+
+func TestParseRSS(t *testing.T) {
+	result := parseFixture(t, "rss_valid.xml", func(f *os.File) (*Result, error) { return ParseRSS(f) })
+
+	if result.Title != "Example Blog" {
+		t.Errorf("Title: expected 'Example Blog', got '%s'", result.Title)
+	}
+	if result.Description != "News from the example blog" {
+		t.Errorf("Description: expected 'News from the example blog', got '%s'", result.Description)
+	}
+	if result.SiteURL != "https://example.com/" {
+		t.Errorf("SiteURL: expected 'https://example.com/' (not the atom:link), got '%s'", result.SiteURL)
+	}
+	if len(result.Links) != 2 {
+		t.Fatalf("Expected 2 links, got %d", len(result.Links))
+	}
+
+	first := result.Links[0]
+	if first.Title() != "First Post" {
+		t.Errorf("Link title: expected 'First Post', got '%s'", first.Title())
+	}
+	if first.URL() != "https://example.com/posts/first" {
+		t.Errorf("Link URL: expected 'https://example.com/posts/first', got '%s'", first.URL())
+	}
+	if first.Description() != "<p>Hello <b>world</b></p>" {
+		t.Errorf("Link description: expected CDATA content, got '%s'", first.Description())
+	}
+	if first.Time() != "2006-01-02 22:04:05" {
+		t.Errorf("Link time: expected '2006-01-02 22:04:05' (UTC), got '%s'", first.Time())
+	}
+	if first.Status() != feedstore.LINK_STATUS_ACTIVE {
+		t.Errorf("Link status: expected '%s', got '%s'", feedstore.LINK_STATUS_ACTIVE, first.Status())
+	}
+	if first.GUID() != "post-1" {
+		t.Errorf("Link GUID: expected 'post-1', got '%s'", first.GUID())
+	}
+
+	second := result.Links[1]
+	if second.Description() != "Full content of the second post" {
+		t.Errorf("Link description: expected content:encoded fallback, got '%s'", second.Description())
+	}
+	if second.Time() != "2006-01-03 10:00:00" {
+		t.Errorf("Link time: expected '2006-01-03 10:00:00', got '%s'", second.Time())
+	}
+	if second.GUID() != "https://example.com/posts/second" {
+		t.Errorf("Link GUID: expected 'https://example.com/posts/second', got '%s'", second.GUID())
+	}
+}
+
+func TestParseRSSMalformed(t *testing.T) {
+	result := parseFixture(t, "rss_malformed.xml", func(f *os.File) (*Result, error) { return ParseRSS(f) })
+
+	if result.Title != "Café Feed" {
+		t.Errorf("Title: expected 'Café Feed' decoded from ISO-8859-1, got '%s'", result.Title)
+	}
+	if len(result.Links) != 3 {
+		t.Fatalf("Expected 3 links (empty item skipped), got %d", len(result.Links))
+	}
+
+	noTitle := result.Links[0]
+	if noTitle.Title() != "https://cafe.example.com/no-title" {
+		t.Errorf("Missing title should fall back to URL, got '%s'", noTitle.Title())
+	}
+	if noTitle.Time() != "2006-01-03 10:00:00" {
+		t.Errorf("Misspelled day name should still parse, got '%s'", noTitle.Time())
+	}
+
+	noLink := result.Links[1]
+	if noLink.URL() != "https://cafe.example.com/from-guid" {
+		t.Errorf("Missing link should fall back to permalink guid, got '%s'", noLink.URL())
+	}
+	if noLink.Time() != sb.NULL_DATETIME {
+		t.Errorf("Unparseable date should be '%s', got '%s'", sb.NULL_DATETIME, noLink.Time())
+	}
+
+	entities := result.Links[2]
+	if !strings.HasPrefix(entities.Title(), "Unescaped & entities") {
+		t.Errorf("HTML entities should be decoded, got '%s'", entities.Title())
+	}
+	if entities.Time() != "2006-01-04 08:30:00" {
+		t.Errorf("RFC 3339 date should parse, got '%s'", entities.Time())
+	}
+}
+
+func TestParseRSSNotRSS(t *testing.T) {
+	_, err := ParseRSS(strings.NewReader(`<feed xmlns="http://www.w3.org/2005/Atom"></feed>`))
+	if err == nil {
+		t.Error("ParseRSS should return an error for a non RSS document")
+	}
+}
+
+func TestResultApplyTo(t *testing.T) {
+	result := parseFixture(t, "rss_valid.xml", func(f *os.File) (*Result, error) { return ParseRSS(f) })
+
+	feed := feedstore.NewFeed()
+	result.ApplyTo(feed)
+
+	if feed.Name() != "Example Blog" {
+		t.Errorf("ApplyTo should fill in an empty feed name, got '%s'", feed.Name())
+	}
+	if feed.Description() != "News from the example blog" {
+		t.Errorf("ApplyTo should fill in an empty feed description, got '%s'", feed.Description())
+	}
+	for _, link := range result.Links {
+		if link.FeedID() != feed.ID() {
+			t.Errorf("ApplyTo should set the feed ID on links, expected '%s', got '%s'", feed.ID(), link.FeedID())
+		}
+	}
+
+	named := feedstore.NewFeed()
+	named.SetName("My Name")
+	result.ApplyTo(named)
+
+	if named.Name() != "My Name" {
+		t.Errorf("ApplyTo should not overwrite an existing feed name, got '%s'", named.Name())
+	}
+}
+
+func TestParseRSSWindows1252(t *testing.T) {
+	result := parseFixture(t, "rss_cp1252.xml", func(f *os.File) (*Result, error) { return ParseRSS(f) })
+
+	if result.Title != "“Smart” Feed – Café" {
+		t.Errorf("Title: expected '“Smart” Feed – Café' decoded from Windows-1252, got '%s'", result.Title)
+	}
+	if result.Description != "Prices in €" {
+		t.Errorf("Description: expected 'Prices in €', got '%s'", result.Description)
+	}
+	if len(result.Links) != 1 {
+		t.Fatalf("Expected 1 link, got %d", len(result.Links))
+	}
+	if result.Links[0].Title() != "It’s 5 €…" {
+		t.Errorf("Link title: expected 'It’s 5 €…', got '%s'", result.Links[0].Title())
+	}
+}
+
+func TestParseRSSItemWithoutGUIDOrLink(t *testing.T) {
+	doc := `<rss version="2.0"><channel><title>Notes</title>
+		<item><title>A note</title><description>Without a link</description></item>
+		<item><title>Another note</title><description>Without a link</description></item>
+	</channel></rss>`
+
+	first, err := ParseRSS(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("ParseRSS should succeed, but got error: %v", err)
+	}
+	second, err := ParseRSS(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Second ParseRSS should succeed, but got error: %v", err)
+	}
+	if len(first.Links) != 2 || len(second.Links) != 2 {
+		t.Fatalf("Expected 2 links per parse, got %d and %d", len(first.Links), len(second.Links))
+	}
+
+	if first.Links[0].GUID() != second.Links[0].GUID() {
+		t.Errorf("Expected the GUID to be stable across parses, got '%s' and '%s'", first.Links[0].GUID(), second.Links[0].GUID())
+	}
+	if first.Links[0].GUID() == first.Links[0].ID() {
+		t.Errorf("Expected the GUID to be derived from the content, not the random ID '%s'", first.Links[0].ID())
+	}
+	if first.Links[0].GUID() == first.Links[1].GUID() {
+		t.Errorf("Expected entries with different content to get different GUIDs, got '%s'", first.Links[0].GUID())
+	}
+}
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dracory/sb"
+	"github.com/dromara/carbon/v2"
+)
+
+// timeLayouts are the date layouts seen in real world feeds, most common first
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	time.RFC3339Nano,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"Mon, 2 Jan 06 15:04:05 -0700",
+	"Mon, 2 Jan 06 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04 MST",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04:05 MST",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04:05 MST",
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// weekdayPrefix matches a leading (possibly misspelled) day name, e.g. "Tues, "
+var weekdayPrefix = regexp.MustCompile(`^[A-Za-z]+,?\s+`)
+
+// parseTime converts a feed date into a UTC datetime string as stored by
+// feedstore. Dates that cannot be understood yield sb.NULL_DATETIME.
+func parseTime(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+
+	if value == "" {
+		return sb.NULL_DATETIME
+	}
+
+	candidates := []string{value}
+
+	// Publishers frequently get the day name wrong or misspell it,
+	// so also try with the day name removed
+	if withoutWeekday := weekdayPrefix.ReplaceAllString(value, ""); withoutWeekday != value {
+		candidates = append(candidates, withoutWeekday)
+	}
+
+	for _, candidate := range candidates {
+		for _, layout := range timeLayouts {
+			t, err := time.Parse(layout, candidate)
+
+			if err == nil {
+				return carbon.CreateFromStdTime(t).ToDateTimeString(carbon.UTC)
+			}
+		}
+	}
+
+	return sb.NULL_DATETIME
+}
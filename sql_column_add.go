@@ -0,0 +1,93 @@
+package feedstore
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dracory/database"
+	"github.com/dracory/sb"
+)
+
+// columnAddIfNotExists adds the column to the table unless it already
+// exists. The default value is a SQL literal, such as 0, which fills
+// the column of the existing rows, as a NOT NULL column cannot be added
+// without one.
+func (st *storeImplementation) columnAddIfNotExists(ctx context.Context, tableName string, column sb.Column, defaultValue string) error {
+	exists, err := st.columnExists(ctx, tableName, column.Name)
+
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	sql, err := sb.NewBuilder(st.dbDriverName).TableColumnAdd(tableName, column)
+
+	if err != nil {
+		return err
+	}
+
+	sql = strings.TrimSuffix(sql, ";") + " DEFAULT " + defaultValue + ";"
+
+	if st.debugEnabled {
+		log.Println(sql)
+	}
+
+	_, err = database.Execute(st.toQueryableContext(ctx), sql)
+
+	return err
+}
+
+// columnExists checks whether the table has a column with the given name
+func (st *storeImplementation) columnExists(ctx context.Context, tableName string, columnName string) (bool, error) {
+	q := goqu.Dialect(st.dbDriverName).Select(goqu.COUNT("*").As("count"))
+
+	switch st.dbDriverName {
+	case sb.DIALECT_MYSQL:
+		q = q.From(goqu.S("information_schema").Table("columns")).
+			Where(
+				goqu.C("table_schema").Eq(goqu.L("DATABASE()")),
+				goqu.C("table_name").Eq(tableName),
+				goqu.C("column_name").Eq(columnName),
+			)
+	case sb.DIALECT_POSTGRES:
+		q = q.From(goqu.S("information_schema").Table("columns")).
+			Where(
+				goqu.C("table_schema").Eq(goqu.L("current_schema()")),
+				goqu.C("table_name").Eq(tableName),
+				goqu.C("column_name").Eq(columnName),
+			)
+	default:
+		q = q.From(goqu.Func("pragma_table_info", tableName)).
+			Where(goqu.C("name").Eq(columnName))
+	}
+
+	sqlStr, params, errSql := q.Prepared(true).ToSQL()
+
+	if errSql != nil {
+		return false, errSql
+	}
+
+	rows, err := database.SelectToMapString(st.toQueryableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return false, err
+	}
+
+	if len(rows) == 0 {
+		return false, nil
+	}
+
+	count, err := strconv.ParseInt(rows[0]["count"], 10, 64)
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -23,6 +23,10 @@ func (st *storeImplementation) sqlFeedTableCreate() string {
 			Name: COLUMN_NAME,
 			Type: sb.COLUMN_TYPE_STRING,
 		}).
+		Column(sb.Column{
+			Name: COLUMN_CATEGORY,
+			Type: sb.COLUMN_TYPE_STRING,
+		}).
 		Column(sb.Column{
 			Name: COLUMN_DESCRIPTION,
 			Type: sb.COLUMN_TYPE_TEXT,
@@ -39,6 +43,26 @@ func (st *storeImplementation) sqlFeedTableCreate() string {
 			Name: COLUMN_LAST_FETCHED_AT,
 			Type: sb.COLUMN_TYPE_DATETIME,
 		}).
+		Column(sb.Column{
+			Name: COLUMN_NEXT_FETCH_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_ETAG,
+			Type: sb.COLUMN_TYPE_STRING,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_LAST_MODIFIED,
+			Type: sb.COLUMN_TYPE_STRING,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_RETENTION_MAX_ITEMS,
+			Type: sb.COLUMN_TYPE_INTEGER,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_RETENTION_MAX_AGE,
+			Type: sb.COLUMN_TYPE_INTEGER,
+		}).
 		Column(sb.Column{
 			Name: COLUMN_MEMO,
 			Type: sb.COLUMN_TYPE_TEXT,
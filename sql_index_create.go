@@ -0,0 +1,142 @@
+package feedstore
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dracory/database"
+	"github.com/dracory/sb"
+)
+
+// mysqlIndexPrefixLengths are the lengths of the prefixes MySQL indexes of
+// the TEXT columns, as it cannot index them whole. Values sharing the prefix
+// are equal for the index, so for the unique index of the links two GUIDs
+// of a feed must differ within their first 512 characters.
+var mysqlIndexPrefixLengths = map[string]int{
+	COLUMN_GUID: 512,
+}
+
+// sqlIndexCreate returns a SQL string for creating an index on a table.
+//
+// MySQL does not support CREATE INDEX IF NOT EXISTS, so the existence of the
+// index is checked separately (see indexExists).
+func (st *storeImplementation) sqlIndexCreate(tableName string, indexName string, unique bool, columns ...string) string {
+	quotedColumns := make([]string, 0, len(columns))
+
+	for _, column := range columns {
+		quotedColumn := st.quoteIdentifier(column)
+
+		if prefixLength := mysqlIndexPrefixLengths[column]; prefixLength > 0 && st.dbDriverName == sb.DIALECT_MYSQL {
+			quotedColumn += "(" + strconv.Itoa(prefixLength) + ")"
+		}
+
+		quotedColumns = append(quotedColumns, quotedColumn)
+	}
+
+	sql := "CREATE "
+
+	if unique {
+		sql += "UNIQUE "
+	}
+
+	sql += "INDEX " + st.quoteIdentifier(indexName) +
+		" ON " + st.quoteIdentifier(tableName) +
+		" (" + strings.Join(quotedColumns, ", ") + ");"
+
+	return sql
+}
+
+// indexCreateIfNotExists creates the index unless it already exists
+func (st *storeImplementation) indexCreateIfNotExists(ctx context.Context, tableName string, indexName string, unique bool, columns ...string) error {
+	exists, err := st.indexExists(ctx, tableName, indexName)
+
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	sql := st.sqlIndexCreate(tableName, indexName, unique, columns...)
+
+	if st.debugEnabled {
+		log.Println(sql)
+	}
+
+	_, err = database.Execute(st.toQueryableContext(ctx), sql)
+
+	return err
+}
+
+// indexExists checks whether an index with the given name exists on the table
+func (st *storeImplementation) indexExists(ctx context.Context, tableName string, indexName string) (bool, error) {
+	sqlStr, params, errSql := st.sqlIndexExists(tableName, indexName)
+
+	if errSql != nil {
+		return false, errSql
+	}
+
+	rows, err := database.SelectToMapString(st.toQueryableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return false, err
+	}
+
+	if len(rows) == 0 {
+		return false, nil
+	}
+
+	count, err := strconv.ParseInt(rows[0]["count"], 10, 64)
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// quoteIdentifier quotes a table, column or index name for the dialect
+func (st *storeImplementation) quoteIdentifier(name string) string {
+	if st.dbDriverName == sb.DIALECT_MYSQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sqlIndexExists returns the SQL counting the indexes with the given name on
+// the table. On MySQL and PostgreSQL only the current schema is looked at, as
+// a table of the same name may exist in another schema.
+func (st *storeImplementation) sqlIndexExists(tableName string, indexName string) (string, []any, error) {
+	q := goqu.Dialect(st.dbDriverName).Select(goqu.COUNT("*").As("count"))
+
+	switch st.dbDriverName {
+	case sb.DIALECT_MYSQL:
+		q = q.From(goqu.S("information_schema").Table("statistics")).
+			Where(
+				goqu.C("table_schema").Eq(goqu.L("DATABASE()")),
+				goqu.C("table_name").Eq(tableName),
+				goqu.C("index_name").Eq(indexName),
+			)
+	case sb.DIALECT_POSTGRES:
+		q = q.From(goqu.T("pg_indexes")).
+			Where(
+				goqu.C("schemaname").Eq(goqu.L("current_schema()")),
+				goqu.C("tablename").Eq(tableName),
+				goqu.C("indexname").Eq(indexName),
+			)
+	default:
+		q = q.From(goqu.T("sqlite_master")).
+			Where(
+				goqu.C("type").Eq("index"),
+				goqu.C("tbl_name").Eq(tableName),
+				goqu.C("name").Eq(indexName),
+			)
+	}
+
+	return q.Prepared(true).ToSQL()
+}
@@ -21,6 +21,10 @@ func (st *storeImplementation) sqlLinkTableCreate() string {
 			Type:   sb.COLUMN_TYPE_STRING,
 			Length: 40,
 		}).
+		Column(sb.Column{
+			Name: COLUMN_GUID,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
 		Column(sb.Column{
 			Name: COLUMN_TITLE,
 			Type: sb.COLUMN_TYPE_STRING,
@@ -53,6 +57,10 @@ func (st *storeImplementation) sqlLinkTableCreate() string {
 			Name: COLUMN_REPORT,
 			Type: sb.COLUMN_TYPE_TEXT,
 		}).
+		Column(sb.Column{
+			Name: COLUMN_REPORT_COUNT,
+			Type: sb.COLUMN_TYPE_INTEGER,
+		}).
 		Column(sb.Column{
 			Name: COLUMN_REPORTED_AT,
 			Type: sb.COLUMN_TYPE_DATETIME,
@@ -74,6 +82,11 @@ func (st *storeImplementation) sqlLinkTableCreate() string {
 			Type:    sb.COLUMN_TYPE_DATETIME,
 			Default: sb.MAX_DATETIME,
 		}).
+		Column(sb.Column{
+			Name:   COLUMN_CASCADE_DELETED_BY,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
 		CreateIfNotExists()
 
 	return sql
@@ -0,0 +1,28 @@
+package feedstore
+
+import (
+	"github.com/dracory/sb"
+)
+
+// sqlMigrationTableCreate returns a SQL string for creating the table which
+// records the applied migrations
+func (st *storeImplementation) sqlMigrationTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.migrationTableName).
+		Column(sb.Column{
+			Name:       COLUMN_VERSION,
+			Type:       sb.COLUMN_TYPE_INTEGER,
+			PrimaryKey: true,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_DESCRIPTION,
+			Type: sb.COLUMN_TYPE_STRING,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_APPLIED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
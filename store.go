@@ -4,24 +4,39 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/dracory/database"
+	"github.com/dracory/sb"
 	"github.com/dromara/carbon/v2"
 	"github.com/samber/lo"
 )
 
 var _ StoreInterface = (*storeImplementation)(nil) // verify it extends the interface
 
+// linkGUIDUniqueColumns are the columns of the unique index of the links,
+// which is the conflict target of LinkUpsert
+var linkGUIDUniqueColumns = []string{COLUMN_FEED_ID, COLUMN_GUID}
+
 type storeImplementation struct {
 	feedTableName      string
 	linkTableName      string
+	migrationTableName string
 	db                 *sql.DB
+	tx                 *sql.Tx
 	dbDriverName       string
 	automigrateEnabled bool
 	debugEnabled       bool
+
+	cascadeMode           string
+	cascadeRestoreEnabled bool
+
+	moderationAutoHideThreshold int64
 }
 
 // FeedCount returns the total number of feeds matching the query filters
@@ -56,7 +71,7 @@ func (storeImplementation *storeImplementation) FeedCount(ctx context.Context, q
 		log.Println(countSQL)
 	}
 
-	rows, err := database.SelectToMapString(database.NewQueryableContext(ctx, storeImplementation.db), countSQL, countParams...)
+	rows, err := database.SelectToMapString(storeImplementation.toQueryableContext(ctx), countSQL, countParams...)
 	if err != nil {
 		return 0, err
 	}
@@ -74,33 +89,97 @@ func (storeImplementation *storeImplementation) FeedCount(ctx context.Context, q
 	return n, nil
 }
 
-// AutoMigrate auto migrate
+// AutoMigrate creates the tables, and brings the schema of existing tables
+// up to date by applying the pending migrations
 func (storeImplementation *storeImplementation) AutoMigrate() error {
-	sql := storeImplementation.sqlFeedTableCreate()
+	return storeImplementation.Migrate(context.Background())
+}
 
-	if sql == "" {
-		return errors.New("feed table create sql is empty")
+// WithTx runs fn with a store whose operations all take part in the same
+// transaction. The transaction is committed when fn returns nil, and rolled
+// back when fn returns an error or panics, in which case the panic is
+// re-raised after the rollback.
+//
+// Calling WithTx on the store passed to fn runs the nested function in the
+// already open transaction.
+func (storeImplementation *storeImplementation) WithTx(ctx context.Context, fn func(tx StoreInterface) error) (err error) {
+	if fn == nil {
+		return newValidationError("fn", "transaction function is nil")
 	}
 
-	_, err := storeImplementation.db.Exec(sql)
+	txStore, end, err := storeImplementation.txBegin(ctx)
 
 	if err != nil {
 		return err
 	}
 
-	sql = storeImplementation.sqlLinkTableCreate()
+	defer end(&err)
 
-	if sql == "" {
-		return errors.New("link table create sql is empty")
-	}
+	return fn(txStore)
+}
 
-	_, err = storeImplementation.db.Exec(sql)
+// inTx runs fn with a copy of the store bound to a transaction, or with the
+// store itself when it is bound to a transaction already
+func (storeImplementation *storeImplementation) inTx(ctx context.Context, fn func(txStore *storeImplementation) error) (err error) {
+	txStore, end, err := storeImplementation.txBegin(ctx)
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	defer end(&err)
+
+	return fn(txStore)
+}
+
+// txBegin returns a copy of the store bound to a new transaction, or the
+// store itself when it is bound to a transaction already, and the function
+// ending the transaction, to be deferred with the address of the error
+// returned by the caller. The transaction is committed when the error is
+// nil, and rolled back when it is not, or on panic, which is re-raised. A
+// transaction opened by an outer call is left to it.
+func (storeImplementation *storeImplementation) txBegin(ctx context.Context) (txStore *storeImplementation, end func(err *error), err error) {
+	if storeImplementation.tx != nil {
+		return storeImplementation, func(*error) {}, nil
+	}
+
+	tx, err := storeImplementation.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	copied := *storeImplementation
+	copied.tx = tx
+
+	end = func(err *error) {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+
+		if *err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				*err = errors.Join(*err, errRollback)
+			}
+
+			return
+		}
+
+		*err = tx.Commit()
+	}
+
+	return &copied, end, nil
+}
+
+// toQueryableContext binds the context to the transaction of a transaction
+// scoped store, or to the database otherwise
+func (storeImplementation *storeImplementation) toQueryableContext(ctx context.Context) database.QueryableContext {
+	if storeImplementation.tx != nil {
+		return database.NewQueryableContext(ctx, storeImplementation.tx)
+	}
+
+	return database.NewQueryableContext(ctx, storeImplementation.db)
 }
 
 // EnableDebug - enables the debug option
@@ -121,6 +200,10 @@ func (storeImplementation *storeImplementation) GetLinkTableName() string {
 }
 
 func (storeImplementation *storeImplementation) FeedCreate(ctx context.Context, feed FeedInterface) error {
+	if feed == nil {
+		return newValidationError("feed", "feed is nil")
+	}
+
 	feed.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
 	feed.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
 
@@ -140,10 +223,10 @@ func (storeImplementation *storeImplementation) FeedCreate(ctx context.Context,
 		log.Println(sqlStr)
 	}
 
-	_, err := storeImplementation.db.ExecContext(ctx, sqlStr, params...)
+	_, err := database.Execute(storeImplementation.toQueryableContext(ctx), sqlStr, params...)
 
 	if err != nil {
-		return err
+		return duplicateError(err)
 	}
 
 	feed.MarkAsNotDirty()
@@ -151,19 +234,83 @@ func (storeImplementation *storeImplementation) FeedCreate(ctx context.Context,
 	return nil
 }
 
+// FeedCreateMany creates the feeds with multi-row inserts, in a single
+// transaction. All feeds get the same created and updated time.
+func (storeImplementation *storeImplementation) FeedCreateMany(ctx context.Context, feeds []FeedInterface) error {
+	if len(feeds) == 0 {
+		return nil
+	}
+
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+	rows := make([]map[string]string, 0, len(feeds))
+
+	for _, feed := range feeds {
+		if feed == nil {
+			return newValidationError("feed", "feed is nil")
+		}
+
+		feed.SetCreatedAt(now)
+		feed.SetUpdatedAt(now)
+
+		rows = append(rows, feed.Data())
+	}
+
+	err := storeImplementation.insertMany(ctx, storeImplementation.feedTableName, rows)
+
+	if err != nil {
+		return err
+	}
+
+	for _, feed := range feeds {
+		feed.MarkAsNotDirty()
+	}
+
+	return nil
+}
+
+// FeedDelete deletes the feed, see FeedDeleteByID
 func (storeImplementation *storeImplementation) FeedDelete(ctx context.Context, feed FeedInterface) error {
 	if feed == nil {
-		return errors.New("feed is nil")
+		return newValidationError("feed", "feed is nil")
 	}
 
 	return storeImplementation.FeedDeleteByID(ctx, feed.ID())
 }
 
-func (storeImplementation *storeImplementation) FeedDeleteByID(ctx context.Context, id string) error {
+// FeedDeleteByID deletes the feed with the given ID, and handles its links
+// according to the cascade mode of the store. Deleting is idempotent, so
+// deleting a missing feed is not an error.
+func (storeImplementation *storeImplementation) FeedDeleteByID(ctx context.Context, id string) (err error) {
 	if id == "" {
-		return errors.New("feed id is empty")
+		return newValidationError("id", "feed id is empty")
+	}
+
+	if storeImplementation.cascadeMode == CASCADE_NONE {
+		return storeImplementation.feedDeleteByID(ctx, id)
+	}
+
+	txStore, end, err := storeImplementation.txBegin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer end(&err)
+
+	if txStore.cascadeMode == CASCADE_HARD {
+		err = txStore.linksDeleteByFeedID(ctx, id)
+	} else {
+		err = txStore.linksSoftDeleteByFeedID(ctx, id, carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+
+	if err != nil {
+		return err
 	}
 
+	return txStore.feedDeleteByID(ctx, id)
+}
+
+func (storeImplementation *storeImplementation) feedDeleteByID(ctx context.Context, id string) error {
 	sqlStr, params, errSql := goqu.Dialect(storeImplementation.dbDriverName).
 		Delete(storeImplementation.feedTableName).
 		Prepared(true).
@@ -178,19 +325,27 @@ func (storeImplementation *storeImplementation) FeedDeleteByID(ctx context.Conte
 		log.Println(sqlStr)
 	}
 
-	_, err := storeImplementation.db.Exec(sqlStr, params...)
+	_, err := database.Execute(storeImplementation.toQueryableContext(ctx), sqlStr, params...)
 
 	return err
 }
 
-func (storeImplementation *storeImplementation) FeedFindByID(ctx context.Context, id string) (FeedInterface, error) {
+// FeedFindByID returns the feed with the given ID, or ErrNotFound.
+// Soft deleted feeds are only found with the WithSoftDeleted option.
+func (storeImplementation *storeImplementation) FeedFindByID(ctx context.Context, id string, options ...FindByIDOptions) (FeedInterface, error) {
 	if id == "" {
-		return nil, errors.New("feed id is empty")
+		return nil, newValidationError("id", "feed id is empty")
 	}
 
-	list, err := storeImplementation.FeedList(ctx, FeedQuery().
+	query := FeedQuery().
 		SetID(id).
-		SetLimit(1))
+		SetLimit(1)
+
+	if withSoftDeleted(options) {
+		query = query.SetWithSoftDeleted(true)
+	}
+
+	list, err := storeImplementation.FeedList(ctx, query)
 
 	if err != nil {
 		return nil, err
@@ -200,7 +355,7 @@ func (storeImplementation *storeImplementation) FeedFindByID(ctx context.Context
 		return list[0], nil
 	}
 
-	return nil, nil
+	return nil, notFoundError("feed", id)
 }
 
 func (storeImplementation *storeImplementation) FeedList(ctx context.Context, query FeedQueryInterface) ([]FeedInterface, error) {
@@ -220,7 +375,7 @@ func (storeImplementation *storeImplementation) FeedList(ctx context.Context, qu
 		log.Println(sqlStr)
 	}
 
-	modelMaps, err := database.SelectToMapString(database.NewQueryableContext(ctx, storeImplementation.db), sqlStr, sqlParams...)
+	modelMaps, err := database.SelectToMapString(storeImplementation.toQueryableContext(ctx), sqlStr, sqlParams...)
 	if err != nil {
 		return []FeedInterface{}, err
 	}
@@ -267,7 +422,7 @@ func (storeImplementation *storeImplementation) LinkCount(ctx context.Context, q
 		log.Println(countSQL)
 	}
 
-	rows, err := database.SelectToMapString(database.NewQueryableContext(ctx, storeImplementation.db), countSQL, countParams...)
+	rows, err := database.SelectToMapString(storeImplementation.toQueryableContext(ctx), countSQL, countParams...)
 	if err != nil {
 		return 0, err
 	}
@@ -285,14 +440,32 @@ func (storeImplementation *storeImplementation) LinkCount(ctx context.Context, q
 	return n, nil
 }
 
-func (storeImplementation *storeImplementation) FeedSoftDelete(ctx context.Context, feed FeedInterface) error {
+// FeedSoftDelete soft deletes the feed. Unless the cascade mode of the store
+// is CASCADE_NONE, its links are soft deleted with the same time.
+func (storeImplementation *storeImplementation) FeedSoftDelete(ctx context.Context, feed FeedInterface) (err error) {
 	if feed == nil {
-		return errors.New("feed is nil")
+		return newValidationError("feed", "feed is nil")
 	}
 
 	feed.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
 
-	return storeImplementation.FeedUpdate(ctx, feed)
+	if storeImplementation.cascadeMode == CASCADE_NONE {
+		return storeImplementation.FeedUpdate(ctx, feed)
+	}
+
+	txStore, end, err := storeImplementation.txBegin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer end(&err)
+
+	if err := txStore.FeedUpdate(ctx, feed); err != nil {
+		return err
+	}
+
+	return txStore.linksSoftDeleteByFeedID(ctx, feed.ID(), feed.SoftDeletedAt())
 }
 
 func (storeImplementation *storeImplementation) FeedSoftDeleteByID(ctx context.Context, id string) error {
@@ -305,9 +478,120 @@ func (storeImplementation *storeImplementation) FeedSoftDeleteByID(ctx context.C
 	return storeImplementation.FeedSoftDelete(ctx, feed)
 }
 
+// FeedRestore undoes the soft deletion of the feed. When cascade restore is
+// enabled, the links soft deleted together with the feed are restored too.
+func (storeImplementation *storeImplementation) FeedRestore(ctx context.Context, feed FeedInterface) (err error) {
+	if feed == nil {
+		return newValidationError("feed", "feed is nil")
+	}
+
+	feed.SetSoftDeletedAt(sb.MAX_DATETIME)
+
+	if !storeImplementation.cascadeRestoreEnabled {
+		return storeImplementation.FeedUpdate(ctx, feed)
+	}
+
+	txStore, end, err := storeImplementation.txBegin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer end(&err)
+
+	if err := txStore.FeedUpdate(ctx, feed); err != nil {
+		return err
+	}
+
+	return txStore.linksRestoreByFeedID(ctx, feed.ID())
+}
+
+// FeedRestoreByID undoes the soft deletion of the feed with the given ID
+func (storeImplementation *storeImplementation) FeedRestoreByID(ctx context.Context, id string) error {
+	feed, err := storeImplementation.FeedFindByID(ctx, id, FindByIDOptions{WithSoftDeleted: true})
+
+	if err != nil {
+		return err
+	}
+
+	return storeImplementation.FeedRestore(ctx, feed)
+}
+
+// FeedRestoreByQuery undoes the soft deletion of the soft deleted feeds
+// matching the query, and returns how many were restored. The limit, the
+// offset and the order of the query are ignored, and the query itself is
+// not changed.
+func (storeImplementation *storeImplementation) FeedRestoreByQuery(ctx context.Context, query FeedQueryInterface) (restored int64, err error) {
+	if query == nil {
+		query = FeedQuery()
+	}
+
+	copied, err := feedQueryCopy(query)
+
+	if err != nil {
+		return 0, err
+	}
+
+	q, _, err := copied.SetOnlySoftDeleted(true).ToSelectDataset(storeImplementation)
+
+	if err != nil {
+		return 0, err
+	}
+
+	q = q.ClearLimit().ClearOffset().ClearOrder()
+
+	txStore, end, err := storeImplementation.txBegin(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer end(&err)
+
+	// The feeds are read before they are restored, as afterwards they no
+	// longer match the query
+	feeds := []map[string]string{}
+
+	if txStore.cascadeRestoreEnabled {
+		sqlStr, params, errSql := q.Prepared(true).
+			Select(COLUMN_ID).
+			ToSQL()
+
+		if errSql != nil {
+			return 0, errSql
+		}
+
+		if txStore.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		feeds, err = database.SelectToMapString(txStore.toQueryableContext(ctx), sqlStr, params...)
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	restored, err = txStore.restoreWhere(ctx, txStore.feedTableName, q)
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, feed := range feeds {
+		if err := txStore.linksRestoreByFeedID(ctx, feed[COLUMN_ID]); err != nil {
+			return 0, err
+		}
+	}
+
+	return restored, nil
+}
+
+// FeedUpdate saves the changed fields of the feed, and returns ErrNotFound
+// when the feed does not exist. A feed with no changed fields is not saved.
 func (storeImplementation *storeImplementation) FeedUpdate(ctx context.Context, feed FeedInterface) error {
 	if feed == nil {
-		return errors.New("feed is nil")
+		return newValidationError("feed", "feed is nil")
 	}
 
 	feed.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
@@ -335,14 +619,22 @@ func (storeImplementation *storeImplementation) FeedUpdate(ctx context.Context,
 		log.Println(sqlStr)
 	}
 
-	_, err := storeImplementation.db.Exec(sqlStr, params...)
+	result, err := database.Execute(storeImplementation.toQueryableContext(ctx), sqlStr, params...)
+
+	if err == nil {
+		err = storeImplementation.updatedCheck(ctx, result, storeImplementation.feedTableName, "feed", feed.ID())
+	}
 
 	feed.MarkAsNotDirty()
 
-	return err
+	return duplicateError(err)
 }
 
 func (storeImplementation *storeImplementation) LinkCreate(ctx context.Context, link LinkInterface) error {
+	if link == nil {
+		return newValidationError("link", "link is nil")
+	}
+
 	link.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
 	link.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
 
@@ -362,10 +654,10 @@ func (storeImplementation *storeImplementation) LinkCreate(ctx context.Context,
 		log.Println(sqlStr)
 	}
 
-	_, err := storeImplementation.db.Exec(sqlStr, params...)
+	_, err := database.Execute(storeImplementation.toQueryableContext(ctx), sqlStr, params...)
 
 	if err != nil {
-		return err
+		return duplicateError(err)
 	}
 
 	link.MarkAsNotDirty()
@@ -373,17 +665,54 @@ func (storeImplementation *storeImplementation) LinkCreate(ctx context.Context,
 	return nil
 }
 
+// LinkCreateMany creates the links with multi-row inserts, in a single
+// transaction. All links get the same created and updated time.
+func (storeImplementation *storeImplementation) LinkCreateMany(ctx context.Context, links []LinkInterface) error {
+	if len(links) == 0 {
+		return nil
+	}
+
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+	rows := make([]map[string]string, 0, len(links))
+
+	for _, link := range links {
+		if link == nil {
+			return newValidationError("link", "link is nil")
+		}
+
+		link.SetCreatedAt(now)
+		link.SetUpdatedAt(now)
+
+		rows = append(rows, link.Data())
+	}
+
+	err := storeImplementation.insertMany(ctx, storeImplementation.linkTableName, rows)
+
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		link.MarkAsNotDirty()
+	}
+
+	return nil
+}
+
+// LinkDelete deletes the link, see LinkDeleteByID
 func (storeImplementation *storeImplementation) LinkDelete(ctx context.Context, link LinkInterface) error {
 	if link == nil {
-		return errors.New("link is nil")
+		return newValidationError("link", "link is nil")
 	}
 
 	return storeImplementation.LinkDeleteByID(ctx, link.ID())
 }
 
+// LinkDeleteByID deletes the link with the given ID. Deleting is
+// idempotent, so deleting a missing link is not an error.
 func (storeImplementation *storeImplementation) LinkDeleteByID(ctx context.Context, id string) error {
 	if id == "" {
-		return errors.New("link id is empty")
+		return newValidationError("id", "link id is empty")
 	}
 
 	sqlStr, params, errSql := goqu.Dialect(storeImplementation.dbDriverName).
@@ -400,19 +729,27 @@ func (storeImplementation *storeImplementation) LinkDeleteByID(ctx context.Conte
 		log.Println(sqlStr)
 	}
 
-	_, err := storeImplementation.db.Exec(sqlStr, params...)
+	_, err := database.Execute(storeImplementation.toQueryableContext(ctx), sqlStr, params...)
 
 	return err
 }
 
-func (storeImplementation *storeImplementation) LinkFindByID(ctx context.Context, id string) (LinkInterface, error) {
+// LinkFindByID returns the link with the given ID, or ErrNotFound.
+// Soft deleted links are only found with the WithSoftDeleted option.
+func (storeImplementation *storeImplementation) LinkFindByID(ctx context.Context, id string, options ...FindByIDOptions) (LinkInterface, error) {
 	if id == "" {
-		return nil, errors.New("link id is empty")
+		return nil, newValidationError("id", "link id is empty")
 	}
 
-	list, err := storeImplementation.LinkList(ctx, LinkQuery().
+	query := LinkQuery().
 		SetID(id).
-		SetLimit(1))
+		SetLimit(1)
+
+	if withSoftDeleted(options) {
+		query = query.SetWithSoftDeleted(true)
+	}
+
+	list, err := storeImplementation.LinkList(ctx, query)
 
 	if err != nil {
 		return nil, err
@@ -422,7 +759,7 @@ func (storeImplementation *storeImplementation) LinkFindByID(ctx context.Context
 		return list[0], nil
 	}
 
-	return nil, nil
+	return nil, notFoundError("link", id)
 }
 
 func (storeImplementation *storeImplementation) LinkList(ctx context.Context, query LinkQueryInterface) ([]LinkInterface, error) {
@@ -435,14 +772,14 @@ func (storeImplementation *storeImplementation) LinkList(ctx context.Context, qu
 	sqlStr, sqlParams, errSql := q.Prepared(true).Select(columns...).ToSQL()
 
 	if errSql != nil {
-		return []LinkInterface{}, nil
+		return []LinkInterface{}, errSql
 	}
 
 	if storeImplementation.debugEnabled {
 		log.Println(sqlStr)
 	}
 
-	modelMaps, err := database.SelectToMapString(database.NewQueryableContext(ctx, storeImplementation.db), sqlStr, sqlParams...)
+	modelMaps, err := database.SelectToMapString(storeImplementation.toQueryableContext(ctx), sqlStr, sqlParams...)
 	if err != nil {
 		return []LinkInterface{}, err
 	}
@@ -457,12 +794,76 @@ func (storeImplementation *storeImplementation) LinkList(ctx context.Context, qu
 	return list, nil
 }
 
+// LinkListPage lists a page of the links matching the query, paged by keyset
+// instead of by offset, so pages stay consistent while links are added.
+//
+// The links are ordered by the order column of the query (the ID by
+// default), then by ID. The page holds up to the query limit of links, and
+// starts after the cursor of the query, if set. The returned cursor is set
+// on the query to list the next page, and is empty after the last page.
+func (storeImplementation *storeImplementation) LinkListPage(ctx context.Context, query LinkQueryInterface) ([]LinkInterface, string, error) {
+	if query == nil {
+		query = LinkQuery()
+	}
+
+	if query.IsRankingSet() {
+		return []LinkInterface{}, "", newValidationError("ranking", "link query: ranking cannot be paged by cursor")
+	}
+
+	q, columns, err := query.ToSelectDataset(storeImplementation)
+
+	if err != nil {
+		return []LinkInterface{}, "", err
+	}
+
+	limit := 1000
+
+	if query.IsLimitSet() && query.GetLimit() > 0 {
+		limit = query.GetLimit()
+	}
+
+	orderBy, orderDirection := LinkKeysetOrder(query)
+
+	sqlStr, sqlParams, errSql := q.Prepared(true).
+		Select(columns...).
+		Order(linkKeysetOrder(orderBy, orderDirection)...).
+		Limit(uint(limit + 1)).
+		ToSQL()
+
+	if errSql != nil {
+		return []LinkInterface{}, "", errSql
+	}
+
+	if storeImplementation.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(storeImplementation.toQueryableContext(ctx), sqlStr, sqlParams...)
+
+	if err != nil {
+		return []LinkInterface{}, "", err
+	}
+
+	list := []LinkInterface{}
+
+	for _, modelMap := range modelMaps[:min(limit, len(modelMaps))] {
+		list = append(list, NewLinkFromExistingData(modelMap))
+	}
+
+	if len(modelMaps) <= limit {
+		return list, "", nil
+	}
+
+	return list, NewLinkCursor(list[len(list)-1], orderBy).Encode(), nil
+}
+
 func (storeImplementation *storeImplementation) LinkSoftDelete(ctx context.Context, link LinkInterface) error {
 	if link == nil {
-		return errors.New("link is nil")
+		return newValidationError("link", "link is nil")
 	}
 
 	link.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	link.SetCascadeDeletedBy("")
 
 	return storeImplementation.LinkUpdate(ctx, link)
 }
@@ -477,9 +878,58 @@ func (storeImplementation *storeImplementation) LinkSoftDeleteByID(ctx context.C
 	return storeImplementation.LinkSoftDelete(ctx, link)
 }
 
+// LinkRestore undoes the soft deletion of the link
+func (storeImplementation *storeImplementation) LinkRestore(ctx context.Context, link LinkInterface) error {
+	if link == nil {
+		return newValidationError("link", "link is nil")
+	}
+
+	link.SetSoftDeletedAt(sb.MAX_DATETIME)
+	link.SetCascadeDeletedBy("")
+
+	return storeImplementation.LinkUpdate(ctx, link)
+}
+
+// LinkRestoreByID undoes the soft deletion of the link with the given ID
+func (storeImplementation *storeImplementation) LinkRestoreByID(ctx context.Context, id string) error {
+	link, err := storeImplementation.LinkFindByID(ctx, id, FindByIDOptions{WithSoftDeleted: true})
+
+	if err != nil {
+		return err
+	}
+
+	return storeImplementation.LinkRestore(ctx, link)
+}
+
+// LinkRestoreByQuery undoes the soft deletion of the soft deleted links
+// matching the query, and returns how many were restored. The limit, the
+// offset and the order of the query are ignored, and the query itself is
+// not changed.
+func (storeImplementation *storeImplementation) LinkRestoreByQuery(ctx context.Context, query LinkQueryInterface) (int64, error) {
+	if query == nil {
+		query = LinkQuery()
+	}
+
+	copied, err := linkQueryCopy(query)
+
+	if err != nil {
+		return 0, err
+	}
+
+	q, _, err := copied.SetOnlySoftDeleted(true).ToSelectDataset(storeImplementation)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return storeImplementation.restoreWhere(ctx, storeImplementation.linkTableName, q)
+}
+
+// LinkUpdate saves the changed fields of the link, and returns ErrNotFound
+// when the link does not exist. A link with no changed fields is not saved.
 func (storeImplementation *storeImplementation) LinkUpdate(ctx context.Context, link LinkInterface) error {
 	if link == nil {
-		return errors.New("link is nil")
+		return newValidationError("link", "link is nil")
 	}
 
 	link.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
@@ -507,13 +957,655 @@ func (storeImplementation *storeImplementation) LinkUpdate(ctx context.Context,
 		log.Println(sqlStr)
 	}
 
-	_, err := storeImplementation.db.Exec(sqlStr, params...)
+	result, err := database.Execute(storeImplementation.toQueryableContext(ctx), sqlStr, params...)
+
+	if err == nil {
+		err = storeImplementation.updatedCheck(ctx, result, storeImplementation.linkTableName, "link", link.ID())
+	}
 
 	link.MarkAsNotDirty()
 
+	return duplicateError(err)
+}
+
+// LinkUpsert inserts the link, or when a link with the same feed ID and GUID
+// already exists, updates its title and description.
+//
+// On success the ID and creation time of the link are set to those of the
+// stored row, read back in the same transaction.
+func (storeImplementation *storeImplementation) LinkUpsert(ctx context.Context, link LinkInterface) (err error) {
+	if link == nil {
+		return newValidationError("link", "link is nil")
+	}
+
+	if link.FeedID() == "" {
+		return newValidationError("feed_id", "link feed id is empty")
+	}
+
+	if link.GUID() == "" {
+		return newValidationError("guid", "link guid is empty")
+	}
+
+	link.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	link.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	sqlStr, params, errSql := storeImplementation.sqlLinkUpsert(link.Data())
+
+	if errSql != nil {
+		return errSql
+	}
+
+	txStore, end, err := storeImplementation.txBegin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer end(&err)
+
+	if txStore.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err = database.Execute(txStore.toQueryableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return duplicateError(err)
+	}
+
+	stored, err := txStore.LinkList(ctx, LinkQuery().
+		SetFeedID(link.FeedID()).
+		SetGUID(link.GUID()).
+		SetWithSoftDeleted(true).
+		SetLimit(1))
+
+	if err != nil {
+		return err
+	}
+
+	if len(stored) > 0 {
+		link.SetID(stored[0].ID())
+		link.SetCreatedAt(stored[0].CreatedAt())
+	}
+
+	link.MarkAsNotDirty()
+
+	return nil
+}
+
+// PurgeSoftDeleted permanently deletes the feeds and links which were soft
+// deleted longer ago than olderThan.
+//
+// Rows are deleted in batches of PURGE_BATCH_SIZE, each in its own
+// statement, so large tables are not locked for long. When the context is
+// cancelled the purge stops between batches, reporting what was removed.
+func (storeImplementation *storeImplementation) PurgeSoftDeleted(ctx context.Context, olderThan time.Duration) (PurgeResult, error) {
+	result := PurgeResult{}
+
+	if olderThan < 0 {
+		return result, newValidationError("olderThan", "olderThan cannot be negative")
+	}
+
+	cutoff := carbon.CreateFromStdTime(time.Now().UTC().Add(-olderThan), carbon.UTC).
+		ToDateTimeString(carbon.UTC)
+
+	links, err := storeImplementation.purgeTable(ctx, storeImplementation.linkTableName, cutoff)
+	result.Links = links
+
+	if err != nil {
+		return result, err
+	}
+
+	feeds, err := storeImplementation.purgeTable(ctx, storeImplementation.feedTableName, cutoff)
+	result.Feeds = feeds
+
+	return result, err
+}
+
+// purgeTable deletes the rows soft deleted before the cutoff in batches,
+// and returns how many were deleted
+func (storeImplementation *storeImplementation) purgeTable(ctx context.Context, tableName string, cutoff string) (int64, error) {
+	purged := int64(0)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+
+		sqlStr, params, errSql := goqu.Dialect(storeImplementation.dbDriverName).
+			From(tableName).
+			Prepared(true).
+			Select(COLUMN_ID).
+			Where(goqu.C(COLUMN_SOFT_DELETED_AT).Lt(cutoff)).
+			Limit(PURGE_BATCH_SIZE).
+			ToSQL()
+
+		if errSql != nil {
+			return purged, errSql
+		}
+
+		if storeImplementation.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		rows, err := database.SelectToMapString(storeImplementation.toQueryableContext(ctx), sqlStr, params...)
+
+		if err != nil {
+			return purged, err
+		}
+
+		if len(rows) == 0 {
+			return purged, nil
+		}
+
+		ids := lo.Map(rows, func(row map[string]string, _ int) string {
+			return row[COLUMN_ID]
+		})
+
+		sqlStr, params, errSql = goqu.Dialect(storeImplementation.dbDriverName).
+			Delete(tableName).
+			Prepared(true).
+			Where(goqu.C(COLUMN_ID).In(ids)).
+			ToSQL()
+
+		if errSql != nil {
+			return purged, errSql
+		}
+
+		if storeImplementation.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		result, err := database.Execute(storeImplementation.toQueryableContext(ctx), sqlStr, params...)
+
+		if err != nil {
+			return purged, err
+		}
+
+		deleted, err := result.RowsAffected()
+
+		if err != nil {
+			deleted = int64(len(ids))
+		}
+
+		purged += deleted
+
+		if len(rows) < PURGE_BATCH_SIZE {
+			return purged, nil
+		}
+	}
+}
+
+// EnforceRetention applies the retention settings of every feed which has
+// them, and returns the number of links removed
+func (storeImplementation *storeImplementation) EnforceRetention(ctx context.Context, options RetentionOptions) (int64, error) {
+	removed := int64(0)
+
+	for offset := 0; ; offset += PURGE_BATCH_SIZE {
+		feeds, err := storeImplementation.FeedList(ctx, FeedQuery().
+			SetOrderBy(COLUMN_ID).
+			SetOrderDirection(sb.ASC).
+			SetLimit(PURGE_BATCH_SIZE).
+			SetOffset(offset))
+
+		if err != nil {
+			return removed, err
+		}
+
+		for _, feed := range feeds {
+			count, err := storeImplementation.FeedEnforceRetention(ctx, feed, options)
+			removed += count
+
+			if err != nil {
+				return removed, fmt.Errorf("feed %s: %w", feed.ID(), err)
+			}
+		}
+
+		if len(feeds) < PURGE_BATCH_SIZE {
+			return removed, nil
+		}
+	}
+}
+
+// FeedEnforceRetention removes the links of the feed beyond its retention
+// settings, and returns how many were removed.
+//
+// Links are kept newest first by their time, then by their creation time.
+// The age of a link is its time, or its creation time when the time is
+// unknown. Links are removed in batches, each in its own statement.
+func (storeImplementation *storeImplementation) FeedEnforceRetention(ctx context.Context, feed FeedInterface, options RetentionOptions) (int64, error) {
+	if feed == nil {
+		return 0, newValidationError("feed", "feed is nil")
+	}
+
+	maxItems, err := feed.RetentionMaxItemsInt64()
+
+	if err != nil {
+		maxItems = 0
+	}
+
+	maxAge, err := feed.RetentionMaxAgeInt64()
+
+	if err != nil {
+		maxAge = 0
+	}
+
+	removed := int64(0)
+
+	if maxAge > 0 {
+		cutoff := carbon.Now(carbon.UTC).SubSeconds(int(maxAge)).ToDateTimeString(carbon.UTC)
+
+		count, err := storeImplementation.removeLinksInBatches(ctx, options, func(q *goqu.SelectDataset) *goqu.SelectDataset {
+			return q.Where(
+				goqu.C(COLUMN_FEED_ID).Eq(feed.ID()),
+				goqu.Or(
+					goqu.And(
+						goqu.C(COLUMN_TIME).Gt(sb.NULL_DATETIME),
+						goqu.C(COLUMN_TIME).Lt(cutoff),
+					),
+					goqu.And(
+						goqu.C(COLUMN_TIME).Lte(sb.NULL_DATETIME),
+						goqu.C(COLUMN_CREATED_AT).Lt(cutoff),
+					),
+				),
+			)
+		})
+
+		removed += count
+
+		if err != nil {
+			return removed, err
+		}
+	}
+
+	if maxItems > 0 {
+		count, err := storeImplementation.removeLinksInBatches(ctx, options, func(q *goqu.SelectDataset) *goqu.SelectDataset {
+			return q.Where(goqu.C(COLUMN_FEED_ID).Eq(feed.ID())).
+				Order(
+					goqu.C(COLUMN_TIME).Desc(),
+					goqu.C(COLUMN_CREATED_AT).Desc(),
+					goqu.C(COLUMN_ID).Desc(),
+				).
+				Offset(uint(maxItems))
+		})
+
+		removed += count
+
+		if err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
+
+// removeLinksInBatches removes the links which are not soft deleted and
+// match the filter, PURGE_BATCH_SIZE at a time. As the removed links no
+// longer match, every batch selects from the start again.
+func (storeImplementation *storeImplementation) removeLinksInBatches(ctx context.Context, options RetentionOptions, filter func(q *goqu.SelectDataset) *goqu.SelectDataset) (int64, error) {
+	removed := int64(0)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+
+		now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+		q := goqu.Dialect(storeImplementation.dbDriverName).
+			From(storeImplementation.linkTableName).
+			Prepared(true).
+			Select(COLUMN_ID).
+			Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(now)).
+			Limit(PURGE_BATCH_SIZE)
+
+		sqlStr, params, errSql := filter(q).ToSQL()
+
+		if errSql != nil {
+			return removed, errSql
+		}
+
+		if storeImplementation.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		rows, err := database.SelectToMapString(storeImplementation.toQueryableContext(ctx), sqlStr, params...)
+
+		if err != nil {
+			return removed, err
+		}
+
+		if len(rows) == 0 {
+			return removed, nil
+		}
+
+		ids := lo.Map(rows, func(row map[string]string, _ int) string {
+			return row[COLUMN_ID]
+		})
+
+		if options.HardDelete {
+			sqlStr, params, errSql = goqu.Dialect(storeImplementation.dbDriverName).
+				Delete(storeImplementation.linkTableName).
+				Prepared(true).
+				Where(goqu.C(COLUMN_ID).In(ids)).
+				ToSQL()
+		} else {
+			sqlStr, params, errSql = goqu.Dialect(storeImplementation.dbDriverName).
+				Update(storeImplementation.linkTableName).
+				Prepared(true).
+				Set(goqu.Record{
+					COLUMN_SOFT_DELETED_AT: now,
+					COLUMN_UPDATED_AT:      now,
+				}).
+				Where(goqu.C(COLUMN_ID).In(ids)).
+				ToSQL()
+		}
+
+		if errSql != nil {
+			return removed, errSql
+		}
+
+		if storeImplementation.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		if _, err := database.Execute(storeImplementation.toQueryableContext(ctx), sqlStr, params...); err != nil {
+			return removed, err
+		}
+
+		removed += int64(len(ids))
+
+		if len(rows) < PURGE_BATCH_SIZE {
+			return removed, nil
+		}
+	}
+}
+
+// linksDeleteByFeedID deletes the links of the feed
+func (storeImplementation *storeImplementation) linksDeleteByFeedID(ctx context.Context, feedID string) error {
+	sqlStr, params, errSql := goqu.Dialect(storeImplementation.dbDriverName).
+		Delete(storeImplementation.linkTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_FEED_ID).Eq(feedID)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if storeImplementation.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(storeImplementation.toQueryableContext(ctx), sqlStr, params...)
+
 	return err
 }
 
+// linksSoftDeleteByFeedID soft deletes the links of the feed which are not
+// soft deleted yet, with the given time, marking them as soft deleted by
+// the feed
+func (storeImplementation *storeImplementation) linksSoftDeleteByFeedID(ctx context.Context, feedID string, softDeletedAt string) error {
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	sqlStr, params, errSql := goqu.Dialect(storeImplementation.dbDriverName).
+		Update(storeImplementation.linkTableName).
+		Prepared(true).
+		Set(goqu.Record{
+			COLUMN_SOFT_DELETED_AT:    softDeletedAt,
+			COLUMN_CASCADE_DELETED_BY: feedID,
+			COLUMN_UPDATED_AT:         now,
+		}).
+		Where(
+			goqu.C(COLUMN_FEED_ID).Eq(feedID),
+			goqu.C(COLUMN_SOFT_DELETED_AT).Gt(now),
+		).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if storeImplementation.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(storeImplementation.toQueryableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// linksRestoreByFeedID restores the links of the feed which were soft
+// deleted by the soft deletion of the feed. Links soft deleted on their
+// own stay soft deleted, whenever that happened.
+func (storeImplementation *storeImplementation) linksRestoreByFeedID(ctx context.Context, feedID string) error {
+	sqlStr, params, errSql := goqu.Dialect(storeImplementation.dbDriverName).
+		Update(storeImplementation.linkTableName).
+		Prepared(true).
+		Set(goqu.Record{
+			COLUMN_SOFT_DELETED_AT:    sb.MAX_DATETIME,
+			COLUMN_CASCADE_DELETED_BY: "",
+			COLUMN_UPDATED_AT:         carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		}).
+		Where(
+			goqu.C(COLUMN_FEED_ID).Eq(feedID),
+			goqu.C(COLUMN_CASCADE_DELETED_BY).Eq(feedID),
+		).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if storeImplementation.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(storeImplementation.toQueryableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// restoreWhere resets the soft deleted time of the rows matching the
+// filters of the dataset, in a single statement, and returns how many
+// were restored
+func (storeImplementation *storeImplementation) restoreWhere(ctx context.Context, tableName string, q *goqu.SelectDataset) (int64, error) {
+	record := goqu.Record{
+		COLUMN_SOFT_DELETED_AT: sb.MAX_DATETIME,
+		COLUMN_UPDATED_AT:      carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+	}
+
+	if tableName == storeImplementation.linkTableName {
+		record[COLUMN_CASCADE_DELETED_BY] = ""
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(storeImplementation.dbDriverName).
+		Update(tableName).
+		Prepared(true).
+		Set(record).
+		Where(q.GetClauses().Where()).
+		ToSQL()
+
+	if errSql != nil {
+		return 0, errSql
+	}
+
+	if storeImplementation.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	result, err := database.Execute(storeImplementation.toQueryableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// insertMany inserts the rows with as few statements as the parameter limit
+// of the driver allows. The rows must all have the same columns.
+func (storeImplementation *storeImplementation) insertMany(ctx context.Context, tableName string, rows []map[string]string) (err error) {
+	columns := len(rows[0])
+
+	for _, row := range rows {
+		if len(row) != columns {
+			return newValidationError("rows", "rows must all have the same columns")
+		}
+
+		for column := range row {
+			if _, ok := rows[0][column]; !ok {
+				return newValidationError("rows", "rows must all have the same columns")
+			}
+		}
+	}
+
+	chunkSize := max(storeImplementation.maxParams()/max(columns, 1), 1)
+
+	txStore, end, err := storeImplementation.txBegin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer end(&err)
+
+	for _, chunk := range lo.Chunk(rows, chunkSize) {
+		records := make([]any, 0, len(chunk))
+
+		for _, row := range chunk {
+			records = append(records, row)
+		}
+
+		sqlStr, params, errSql := goqu.Dialect(txStore.dbDriverName).
+			Insert(tableName).
+			Prepared(true).
+			Rows(records...).
+			ToSQL()
+
+		if errSql != nil {
+			return errSql
+		}
+
+		if txStore.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		_, err := database.Execute(txStore.toQueryableContext(ctx), sqlStr, params...)
+
+		if err != nil {
+			return duplicateError(err)
+		}
+	}
+
+	return nil
+}
+
+// updatedCheck returns ErrNotFound when the update affected no row because
+// no row has the ID. MySQL does not count the rows whose values did not
+// change as affected, so the row is looked up before it is reported missing.
+func (storeImplementation *storeImplementation) updatedCheck(ctx context.Context, result sql.Result, tableName string, what string, id string) error {
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affected > 0 {
+		return nil
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(storeImplementation.dbDriverName).
+		From(tableName).
+		Prepared(true).
+		Select(goqu.COUNT(goqu.Star()).As("count")).
+		Where(goqu.C(COLUMN_ID).Eq(id)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if storeImplementation.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	rows, err := database.SelectToMapString(storeImplementation.toQueryableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	if len(rows) == 0 || rows[0]["count"] == "0" {
+		return notFoundError(what, id)
+	}
+
+	return nil
+}
+
+// maxParams returns the maximum number of parameters a single statement
+// may have for the driver. SQLite builds before 3.32 are limited to 999.
+func (storeImplementation *storeImplementation) maxParams() int {
+	switch storeImplementation.dbDriverName {
+	case sb.DIALECT_MYSQL, sb.DIALECT_POSTGRES:
+		return 65535
+	default:
+		return 999
+	}
+}
+
+// withSoftDeleted reports whether any of the options allows soft deleted
+// records to be found
+func withSoftDeleted(options []FindByIDOptions) bool {
+	return lo.SomeBy(options, func(option FindByIDOptions) bool {
+		return option.WithSoftDeleted
+	})
+}
+
+// sqlLinkUpsert returns the statement inserting the link data, or updating
+// the title, description and update time of the link with the same feed ID
+// and GUID.
+//
+// The conflict clause is appended to the insert by hand, because goqu turns
+// an insert with a conflict clause into INSERT IGNORE on MySQL (INSERT OR
+// IGNORE on sqlite3), which silently skips rows violating other constraints.
+func (storeImplementation *storeImplementation) sqlLinkUpsert(data map[string]string) (string, []any, error) {
+	sqlStr, params, errSql := goqu.Dialect(storeImplementation.dbDriverName).
+		Insert(storeImplementation.linkTableName).
+		Prepared(true).
+		Rows(data).
+		ToSQL()
+
+	if errSql != nil {
+		return "", nil, errSql
+	}
+
+	isMySQL := storeImplementation.dbDriverName == sb.DIALECT_MYSQL
+
+	updates := []string{}
+
+	for _, column := range []string{COLUMN_TITLE, COLUMN_DESCRIPTION, COLUMN_UPDATED_AT} {
+		quotedColumn := storeImplementation.quoteIdentifier(column)
+
+		if isMySQL {
+			updates = append(updates, quotedColumn+" = VALUES("+quotedColumn+")")
+		} else {
+			updates = append(updates, quotedColumn+" = excluded."+quotedColumn)
+		}
+	}
+
+	// MySQL has no conflict target, it updates on any unique key
+	if isMySQL {
+		return sqlStr + " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", "), params, nil
+	}
+
+	target := lo.Map(linkGUIDUniqueColumns, func(column string, _ int) string {
+		return storeImplementation.quoteIdentifier(column)
+	})
+
+	return sqlStr + " ON CONFLICT (" + strings.Join(target, ", ") + ") DO UPDATE SET " + strings.Join(updates, ", "), params, nil
+}
+
 // func (storeImplementation *storeImplementation) linkQuery(options LinkQueryOptions) *goqu.SelectDataset {
 // 	q := goqu.Dialect(storeImplementation.dbDriverName).From(storeImplementation.linkTableName)
 
@@ -0,0 +1,50 @@
+package feedstore_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/dracory/feedstore"
+	"github.com/dracory/feedstore/feedstoretest"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestStoreConformance(t *testing.T) {
+	feedstoretest.RunStoreTests(t, func(t *testing.T, options feedstoretest.StoreOptions) feedstore.StoreInterface {
+		return newConformanceStore(t, options, 0)
+	})
+}
+
+func TestStoreAutoHide(t *testing.T) {
+	feedstoretest.RunAutoHideTests(t, func(t *testing.T, threshold int64) feedstore.StoreInterface {
+		return newConformanceStore(t, feedstoretest.StoreOptions{}, threshold)
+	})
+}
+
+// newConformanceStore returns a new store on an empty in-memory database
+func newConformanceStore(t *testing.T, options feedstoretest.StoreOptions, autoHideThreshold int64) feedstore.StoreInterface {
+	// Each connection to ":memory:" opens its own database, so a single
+	// connection keeps the tables visible to all queries
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	store, err := feedstore.NewStore(feedstore.NewStoreOptions{
+		DB:                          db,
+		FeedTableName:               "feeds",
+		LinkTableName:               "links",
+		AutomigrateEnabled:          true,
+		CascadeMode:                 options.CascadeMode,
+		CascadeRestoreEnabled:       options.CascadeRestoreEnabled,
+		ModerationAutoHideThreshold: autoHideThreshold,
+	})
+	if err != nil {
+		t.Fatalf("NewStore should not return an error, but got: %v", err)
+	}
+
+	return store
+}
@@ -1,10 +1,41 @@
 package feedstore
 
-import "context"
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// FindByIDOptions define the options of the Find*ByID methods
+type FindByIDOptions struct {
+	// WithSoftDeleted allows a soft deleted record to be found,
+	// e.g. to restore it
+	WithSoftDeleted bool
+}
+
+// RetentionOptions define how EnforceRetention removes links
+type RetentionOptions struct {
+	// HardDelete deletes the links instead of soft deleting them
+	HardDelete bool
+}
+
+// MigrationInfo describes a schema migration
+type MigrationInfo struct {
+	Version     int
+	Description string
+}
+
+// PurgeResult reports how many rows were permanently removed by a purge
+type PurgeResult struct {
+	Feeds int64
+	Links int64
+}
 
 type StoreInterface interface {
 	AutoMigrate() error
 	EnableDebug(debug bool)
+	Migrate(ctx context.Context) error
+	MigrationsPending(ctx context.Context) ([]MigrationInfo, error)
 
 	GetDriverName() string
 	GetFeedTableName() string
@@ -12,21 +43,44 @@ type StoreInterface interface {
 
 	FeedCount(ctx context.Context, query FeedQueryInterface) (int64, error)
 	FeedCreate(ctx context.Context, feed FeedInterface) error
+	FeedCreateMany(ctx context.Context, feeds []FeedInterface) error
 	FeedDelete(ctx context.Context, feed FeedInterface) error
 	FeedDeleteByID(ctx context.Context, id string) error
-	FeedFindByID(ctx context.Context, id string) (FeedInterface, error)
+	FeedFindByID(ctx context.Context, id string, options ...FindByIDOptions) (FeedInterface, error)
+	FeedIterate(ctx context.Context, query FeedQueryInterface) iter.Seq2[FeedInterface, error]
 	FeedList(ctx context.Context, query FeedQueryInterface) ([]FeedInterface, error)
+	FeedRestore(ctx context.Context, feed FeedInterface) error
+	FeedRestoreByID(ctx context.Context, id string) error
+	FeedRestoreByQuery(ctx context.Context, query FeedQueryInterface) (int64, error)
 	FeedSoftDelete(ctx context.Context, feed FeedInterface) error
 	FeedSoftDeleteByID(ctx context.Context, id string) error
 	FeedUpdate(ctx context.Context, feed FeedInterface) error
 
 	LinkCount(ctx context.Context, query LinkQueryInterface) (int64, error)
 	LinkCreate(ctx context.Context, link LinkInterface) error
+	LinkCreateMany(ctx context.Context, links []LinkInterface) error
 	LinkDelete(ctx context.Context, link LinkInterface) error
 	LinkDeleteByID(ctx context.Context, id string) error
-	LinkFindByID(ctx context.Context, id string) (LinkInterface, error)
+	LinkFindByID(ctx context.Context, id string, options ...FindByIDOptions) (LinkInterface, error)
+	LinkIncrementViews(ctx context.Context, id string, delta int64) (int64, error)
+	LinkIterate(ctx context.Context, query LinkQueryInterface) iter.Seq2[LinkInterface, error]
 	LinkList(ctx context.Context, query LinkQueryInterface) ([]LinkInterface, error)
+	LinkListPage(ctx context.Context, query LinkQueryInterface) (list []LinkInterface, nextCursor string, err error)
+	LinkReport(ctx context.Context, id string, reason string) (reportCount int64, err error)
+	LinkReportedList(ctx context.Context, query LinkQueryInterface) ([]LinkInterface, error)
+	LinkReportResolve(ctx context.Context, id string, resolution string) error
+	LinkRestore(ctx context.Context, link LinkInterface) error
+	LinkRestoreByID(ctx context.Context, id string) error
+	LinkRestoreByQuery(ctx context.Context, query LinkQueryInterface) (int64, error)
 	LinkSoftDelete(ctx context.Context, link LinkInterface) error
 	LinkSoftDeleteByID(ctx context.Context, id string) error
 	LinkUpdate(ctx context.Context, link LinkInterface) error
+	LinkUpsert(ctx context.Context, link LinkInterface) error
+	LinkVote(ctx context.Context, id string, up bool) (votesUp int64, votesDown int64, err error)
+
+	EnforceRetention(ctx context.Context, options RetentionOptions) (int64, error)
+	FeedEnforceRetention(ctx context.Context, feed FeedInterface, options RetentionOptions) (int64, error)
+	PurgeSoftDeleted(ctx context.Context, olderThan time.Duration) (PurgeResult, error)
+
+	WithTx(ctx context.Context, fn func(tx StoreInterface) error) error
 }
@@ -2,46 +2,97 @@ package feedstore
 
 import (
 	"database/sql"
-	"errors"
 
+	"github.com/doug-martin/goqu/v9"
 	"github.com/dracory/sb"
+	"github.com/samber/lo"
 )
 
 // NewStoreOptions define the options for creating a new block store
 type NewStoreOptions struct {
-	FeedTableName      string
-	LinkTableName      string
+	FeedTableName string
+	LinkTableName string
+
+	// MigrationTableName is the table recording the applied schema
+	// migrations, defaults to FeedTableName + "_migrations"
+	MigrationTableName string
+
 	DB                 *sql.DB
 	DbDriverName       string
 	AutomigrateEnabled bool
 	DebugEnabled       bool
+
+	// CascadeMode defines what happens to the links of a feed when the feed
+	// is removed, in the same transaction:
+	//   - CASCADE_NONE (default) leaves the links untouched
+	//   - CASCADE_SOFT soft deletes the links when the feed is deleted or
+	//     soft deleted
+	//   - CASCADE_HARD deletes the links when the feed is deleted, and soft
+	//     deletes them when the feed is soft deleted
+	CascadeMode string
+
+	// CascadeRestoreEnabled restores the links which were soft deleted
+	// together with a feed, when the feed is restored
+	CascadeRestoreEnabled bool
+
+	// ModerationAutoHideThreshold is the number of reports at which
+	// LinkReport hides a link, setting its status to LINK_STATUS_HIDDEN.
+	// Zero (default) disables auto hiding.
+	ModerationAutoHideThreshold int64
 }
 
 // NewStore creates a new block store
 func NewStore(opts NewStoreOptions) (StoreInterface, error) {
 	if opts.FeedTableName == "" {
-		return nil, errors.New("feed store: FeedTableName is required")
+		return nil, newValidationError("FeedTableName", "feed store: FeedTableName is required")
 	}
 
 	if opts.LinkTableName == "" {
-		return nil, errors.New("feed store: LinkTableName is required")
+		return nil, newValidationError("LinkTableName", "feed store: LinkTableName is required")
 	}
 
 	if opts.DB == nil {
-		return nil, errors.New("feed store: DB is required")
+		return nil, newValidationError("DB", "feed store: DB is required")
+	}
+
+	if opts.MigrationTableName == "" {
+		opts.MigrationTableName = opts.FeedTableName + "_migrations"
+	}
+
+	if opts.CascadeMode == "" {
+		opts.CascadeMode = CASCADE_NONE
+	}
+
+	if !lo.Contains([]string{CASCADE_NONE, CASCADE_SOFT, CASCADE_HARD}, opts.CascadeMode) {
+		return nil, newValidationError("CascadeMode", "feed store: CascadeMode must be one of none, soft or hard")
+	}
+
+	if opts.ModerationAutoHideThreshold < 0 {
+		return nil, newValidationError("ModerationAutoHideThreshold", "feed store: ModerationAutoHideThreshold cannot be negative")
 	}
 
 	if opts.DbDriverName == "" {
 		opts.DbDriverName = sb.DatabaseDriverName(opts.DB)
 	}
 
+	// goqu falls back to its default dialect, whose SQL MySQL and
+	// PostgreSQL reject, when the dialect is not registered
+	if lo.Contains([]string{sb.DIALECT_MYSQL, sb.DIALECT_POSTGRES}, opts.DbDriverName) && goqu.GetDialect(opts.DbDriverName).Dialect() != opts.DbDriverName {
+		return nil, newValidationError("DbDriverName", "feed store: the goqu "+opts.DbDriverName+" dialect is not registered, import github.com/doug-martin/goqu/v9/dialect/"+opts.DbDriverName)
+	}
+
 	store := &storeImplementation{
-		feedTableName:      opts.FeedTableName,
-		linkTableName:      opts.LinkTableName,
-		automigrateEnabled: opts.AutomigrateEnabled,
-		db:                 opts.DB,
-		dbDriverName:       opts.DbDriverName,
-		debugEnabled:       opts.DebugEnabled,
+		feedTableName:         opts.FeedTableName,
+		linkTableName:         opts.LinkTableName,
+		migrationTableName:    opts.MigrationTableName,
+		automigrateEnabled:    opts.AutomigrateEnabled,
+		db:                    opts.DB,
+		dbDriverName:          opts.DbDriverName,
+		debugEnabled:          opts.DebugEnabled,
+		cascadeMode:           opts.CascadeMode,
+		cascadeRestoreEnabled: opts.CascadeRestoreEnabled,
+
+		moderationAutoHideThreshold: opts.ModerationAutoHideThreshold,
 	}
 
 	if store.automigrateEnabled {
@@ -3,15 +3,25 @@ package feedstore
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/dialect/mysql"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	_ "github.com/doug-martin/goqu/v9/dialect/sqlite3"
 	"github.com/dracory/sb"
 	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
 
 	_ "modernc.org/sqlite"
 )
@@ -116,6 +126,318 @@ func TestStoreAutoMigrate(t *testing.T) {
 	}
 }
 
+func TestStoreMigrate(t *testing.T) {
+	db := initDB(":memory:")
+	defer db.Close()
+	ctx := context.Background()
+
+	feedTable := "feed_migrate"
+	linkTable := "link_migrate"
+
+	// Tables as created by the first release, before any migration
+	statements := []string{
+		`CREATE TABLE "` + feedTable + `" ("id" TEXT NOT NULL PRIMARY KEY, "status" TEXT NOT NULL, "name" TEXT NOT NULL,
+			"description" TEXT NOT NULL, "url" TEXT NOT NULL, "fetch_interval" TEXT NOT NULL, "last_fetched_at" DATETIME NOT NULL,
+			"memo" TEXT NOT NULL, "created_at" DATETIME NOT NULL, "updated_at" DATETIME NOT NULL, "soft_deleted_at" DATETIME NOT NULL)`,
+		`CREATE TABLE "` + linkTable + `" ("id" TEXT NOT NULL PRIMARY KEY, "status" TEXT NOT NULL, "feed_id" TEXT NOT NULL,
+			"title" TEXT NOT NULL, "description" TEXT NOT NULL, "url" TEXT NOT NULL, "time" DATETIME NOT NULL,
+			"votes_up" INTEGER NOT NULL, "votes_down" INTEGER NOT NULL, "views" INTEGER NOT NULL, "report" TEXT NOT NULL,
+			"reported_at" DATETIME NOT NULL, "checked_at" DATETIME NOT NULL, "created_at" DATETIME NOT NULL,
+			"updated_at" DATETIME NOT NULL, "soft_deleted_at" DATETIME NOT NULL)`,
+		`INSERT INTO "` + feedTable + `" VALUES ('feed1', 'active', 'Feed', '', 'https://example.com/feed.xml', '3600',
+			'2020-01-01 00:00:00', '', '2020-01-01 00:00:00', '2020-01-01 00:00:00', '9999-12-31 23:59:59')`,
+		`INSERT INTO "` + linkTable + `" VALUES ('link1', 'active', 'feed1', 'Link', '', 'https://example.com/1', '2020-01-01 00:00:00',
+			0, 0, 0, '', '1900-01-01 00:00:00', '2020-01-01 00:00:00', '2020-01-01 00:00:00', '2020-01-01 00:00:00', '9999-12-31 23:59:59')`,
+		`INSERT INTO "` + linkTable + `" VALUES ('link2', 'active', 'feed1', 'Reported', '', 'https://example.com/2', '2020-01-01 00:00:00',
+			0, 0, 0, 'broken link', '2020-01-02 00:00:00', '2020-01-01 00:00:00', '2020-01-01 00:00:00', '2020-01-01 00:00:00', '9999-12-31 23:59:59')`,
+		`INSERT INTO "` + feedTable + `" VALUES ('feed2', 'active', 'Removed', '', 'https://example.com/removed.xml', '3600',
+			'2020-01-01 00:00:00', '', '2020-01-01 00:00:00', '2020-01-01 00:00:00', '2021-01-01 00:00:00')`,
+		`INSERT INTO "` + linkTable + `" VALUES ('link3', 'active', 'feed2', 'Removed', '', 'https://example.com/3', '2020-01-01 00:00:00',
+			0, 0, 0, '', '1900-01-01 00:00:00', '2020-01-01 00:00:00', '2020-01-01 00:00:00', '2020-01-01 00:00:00', '2021-01-01 00:00:00')`,
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("Creating the first release schema failed: %v", err)
+		}
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:            db,
+		FeedTableName: feedTable,
+		LinkTableName: linkTable,
+	})
+	if err != nil {
+		t.Fatalf("NewStore should not return an error, but got: %v", err)
+	}
+
+	pending, err := store.MigrationsPending(ctx)
+	if err != nil {
+		t.Fatalf("MigrationsPending should succeed, but got error: %v", err)
+	}
+	if len(pending) != 10 {
+		t.Fatalf("Expected 10 pending migrations, got %d", len(pending))
+	}
+	for i, migration := range pending {
+		if migration.Version != i+1 {
+			t.Errorf("Pending migration %d: expected version %d, got %d", i, i+1, migration.Version)
+		}
+		if migration.Description == "" {
+			t.Errorf("Pending migration %d should have a description", i)
+		}
+	}
+
+	if err := store.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate should succeed, but got error: %v", err)
+	}
+
+	pending, err = store.MigrationsPending(ctx)
+	if err != nil {
+		t.Fatalf("MigrationsPending should succeed, but got error: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("Expected no pending migrations after Migrate, got %v", pending)
+	}
+
+	// The existing rows are readable with the new columns filled in
+	feed, err := store.FeedFindByID(ctx, "feed1")
+	if err != nil {
+		t.Fatalf("FeedFindByID should succeed, but got error: %v", err)
+	}
+	if feed == nil {
+		t.Fatal("FeedFindByID should find the existing feed")
+	}
+	if feed.Category() != "" || feed.ETag() != "" || feed.RetentionMaxItems() != "0" || feed.RetentionMaxAge() != "0" {
+		t.Errorf("Expected the added feed columns to hold their defaults, got %v", feed.Data())
+	}
+	if next := carbon.Parse(feed.NextFetchAt(), carbon.UTC).ToDateTimeString(carbon.UTC); next != "2020-01-01 01:00:00" {
+		t.Errorf("Expected the fetched feed to be due one fetch interval after its last fetch, got '%s'", feed.NextFetchAt())
+	}
+
+	link, err := store.LinkFindByID(ctx, "link1")
+	if err != nil {
+		t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+	}
+	if link == nil {
+		t.Fatal("LinkFindByID should find the existing link")
+	}
+	if link.GUID() != "link1" {
+		t.Errorf("Expected the GUID of the existing link to be its ID, got '%s'", link.GUID())
+	}
+	if link.ReportCount() != "0" {
+		t.Errorf("Expected an unreported link to have no reports, got '%s'", link.ReportCount())
+	}
+
+	// A report of an existing link counts as one
+	reported, err := store.LinkFindByID(ctx, "link2")
+	if err != nil {
+		t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+	}
+	if reported.ReportCount() != "1" {
+		t.Errorf("Expected a reported link to have one report, got '%s'", reported.ReportCount())
+	}
+	if reports := LinkReports(reported); len(reports) != 1 || reports[0].Reason != "broken link" {
+		t.Errorf("Expected the plain text report to be read as one report, got %v", reports)
+	}
+
+	// A link soft deleted at the time of its soft deleted feed is marked as
+	// soft deleted with it
+	if link.CascadeDeletedBy() != "" {
+		t.Errorf("Expected a link which is not soft deleted to have no cascade marker, got '%s'", link.CascadeDeletedBy())
+	}
+	cascaded, err := store.LinkFindByID(ctx, "link3", FindByIDOptions{WithSoftDeleted: true})
+	if err != nil {
+		t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+	}
+	if cascaded.CascadeDeletedBy() != "feed2" {
+		t.Errorf("Expected the link soft deleted with its feed to be marked, got '%s'", cascaded.CascadeDeletedBy())
+	}
+
+	// The unique index on feed ID and GUID is in place
+	duplicate := NewLink().SetFeedID("feed1").SetGUID("link1").SetURL("https://example.com/2").SetStatus(LINK_STATUS_ACTIVE)
+	if err := store.LinkCreate(ctx, duplicate); err == nil {
+		t.Error("LinkCreate should fail for a duplicate GUID within the feed")
+	}
+
+	// Running the migrations again is a no-op
+	if err := store.Migrate(ctx); err != nil {
+		t.Fatalf("Second Migrate should succeed, but got error: %v", err)
+	}
+
+	var applied int
+	err = db.QueryRow(`SELECT COUNT(*) FROM "` + feedTable + `_migrations"`).Scan(&applied)
+	if err != nil {
+		t.Fatalf("Counting the applied migrations failed: %v", err)
+	}
+	if applied != 10 {
+		t.Errorf("Expected 10 applied migrations, got %d", applied)
+	}
+}
+
+func TestStoreIndexes(t *testing.T) {
+	db := initDB(":memory:")
+	defer db.Close()
+
+	feedTable := "feed_indexes"
+	linkTable := "link_indexes"
+	store := createTestStore(t, db, feedTable, linkTable)
+
+	testCases := []struct {
+		name    string
+		dataset func() (*goqu.SelectDataset, []any, error)
+		index   string
+	}{
+		{
+			name: "links by feed ordered by time",
+			dataset: func() (*goqu.SelectDataset, []any, error) {
+				return LinkQuery().SetFeedID("feed1").SetOrderBy(COLUMN_TIME).ToSelectDataset(store)
+			},
+			index: linkTable + "_feed_id_time_index",
+		},
+		{
+			name: "links by url",
+			dataset: func() (*goqu.SelectDataset, []any, error) {
+				return LinkQuery().SetURL("https://example.com").ToSelectDataset(store)
+			},
+			index: linkTable + "_url_index",
+		},
+		{
+			name: "links by status",
+			dataset: func() (*goqu.SelectDataset, []any, error) {
+				return LinkQuery().SetStatus(LINK_STATUS_ACTIVE).ToSelectDataset(store)
+			},
+			index: linkTable + "_status_index",
+		},
+		{
+			name: "soft deleted links",
+			dataset: func() (*goqu.SelectDataset, []any, error) {
+				return LinkQuery().SetOnlySoftDeleted(true).ToSelectDataset(store)
+			},
+			index: linkTable + "_soft_deleted_at_index",
+		},
+		{
+			name: "reported links ordered by report count",
+			dataset: func() (*goqu.SelectDataset, []any, error) {
+				return LinkQuery().SetReportCountGte(1).SetOrderBy(COLUMN_REPORT_COUNT).ToSelectDataset(store)
+			},
+			index: linkTable + "_report_count_index",
+		},
+		{
+			name: "feeds due for fetching",
+			dataset: func() (*goqu.SelectDataset, []any, error) {
+				return FeedQuery().SetLastFetchedAtLte("2020-01-01 00:00:00").ToSelectDataset(store)
+			},
+			index: feedTable + "_last_fetched_at_index",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, _, err := tc.dataset()
+			if err != nil {
+				t.Fatalf("ToSelectDataset should succeed, but got error: %v", err)
+			}
+
+			sqlStr, params, err := q.Prepared(true).ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL should succeed, but got error: %v", err)
+			}
+
+			rows, err := db.Query("EXPLAIN QUERY PLAN "+sqlStr, params...)
+			if err != nil {
+				t.Fatalf("EXPLAIN QUERY PLAN should succeed, but got error: %v", err)
+			}
+			defer rows.Close()
+
+			plan := []string{}
+			for rows.Next() {
+				var id, parent, notUsed int
+				var detail string
+				if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+					t.Fatalf("Scanning the query plan failed: %v", err)
+				}
+				plan = append(plan, detail)
+			}
+
+			if !strings.Contains(strings.Join(plan, "\n"), "INDEX "+tc.index) {
+				t.Errorf("Expected the query plan to use index %s, got %v", tc.index, plan)
+			}
+		})
+	}
+}
+
+func TestStoreErrors(t *testing.T) {
+	db := initDB(":memory:")
+	defer db.Close()
+	store := createTestStore(t, db, "feed_errors", "link_errors")
+	ctx := context.Background()
+
+	// Not found
+	if _, err := store.FeedFindByID(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FeedFindByID should return ErrNotFound, but got: %v", err)
+	}
+	if err := store.FeedSoftDeleteByID(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FeedSoftDeleteByID should return ErrNotFound, but got: %v", err)
+	}
+	if _, err := store.LinkFindByID(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LinkFindByID should return ErrNotFound, but got: %v", err)
+	}
+	if err := store.LinkRestoreByID(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LinkRestoreByID should return ErrNotFound, but got: %v", err)
+	}
+
+	// Validation
+	_, err := store.FeedList(ctx, FeedQuery().SetStatus(""))
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("FeedList with an invalid query should return ErrValidation, but got: %v", err)
+	}
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("FeedList with an invalid query should return a ValidationError, but got: %T", err)
+	}
+	if validationErr.Field != "status" {
+		t.Errorf("Expected the invalid field to be 'status', got '%s'", validationErr.Field)
+	}
+	if validationErr.Error() != "feed query: status cannot be empty" {
+		t.Errorf("Unexpected validation message '%s'", validationErr.Error())
+	}
+
+	if _, err := store.LinkCount(ctx, LinkQuery().SetIDIn([]string{})); !errors.Is(err, ErrValidation) {
+		t.Errorf("LinkCount with an invalid query should return ErrValidation, but got: %v", err)
+	}
+	if err := store.FeedCreate(ctx, nil); !errors.As(err, &validationErr) || validationErr.Field != "feed" {
+		t.Errorf("FeedCreate with a nil feed should return a ValidationError for 'feed', but got: %v", err)
+	}
+	if err := store.LinkUpsert(ctx, NewLink().SetGUID("guid")); !errors.As(err, &validationErr) || validationErr.Field != "feed_id" {
+		t.Errorf("LinkUpsert without feed ID should return a ValidationError for 'feed_id', but got: %v", err)
+	}
+	if _, err := NewStore(NewStoreOptions{FeedTableName: "feed", LinkTableName: "link"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("NewStore without DB should return ErrValidation, but got: %v", err)
+	}
+
+	// Duplicates
+	feed := NewFeed().SetName("Feed").SetURL("https://example.com/feed.xml")
+	if err := store.FeedCreate(ctx, feed); err != nil {
+		t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+	}
+	sameID := NewFeed().SetName("Same ID").SetURL("https://example.com/other.xml")
+	sameID.SetID(feed.ID())
+	if err := store.FeedCreate(ctx, sameID); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("FeedCreate with an existing ID should return ErrDuplicate, but got: %v", err)
+	}
+
+	link := NewLink().SetFeedID(feed.ID()).SetGUID("guid").SetTitle("Link").SetURL("https://example.com/1").SetStatus(LINK_STATUS_ACTIVE)
+	if err := store.LinkCreate(ctx, link); err != nil {
+		t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+	}
+	sameGUID := NewLink().SetFeedID(feed.ID()).SetGUID("guid").SetTitle("Same GUID").SetURL("https://example.com/2").SetStatus(LINK_STATUS_ACTIVE)
+	if err := store.LinkCreate(ctx, sameGUID); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("LinkCreate with an existing GUID should return ErrDuplicate, but got: %v", err)
+	}
+	if err := store.LinkCreateMany(ctx, []LinkInterface{sameGUID}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("LinkCreateMany with an existing GUID should return ErrDuplicate, but got: %v", err)
+	}
+}
+
 func TestStoreEnableDebug(t *testing.T) {
 	db := initDB(":memory:")
 	defer db.Close()
@@ -189,8 +511,8 @@ func TestStoreFeedDelete(t *testing.T) {
 
 	// 4. Verify it's gone
 	foundFeed, err = store.FeedFindByID(ctx, feed.ID())
-	if err != nil {
-		t.Fatalf("FeedFindByID should succeed after delete, but got error: %v", err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("FeedFindByID should return ErrNotFound, but got: %v", err)
 	}
 	if foundFeed != nil {
 		t.Error("Feed should not be found after delete, but was found")
@@ -241,8 +563,8 @@ func TestStoreFeedDeleteByID(t *testing.T) {
 
 	// 4. Verify it's gone
 	foundFeed, err = store.FeedFindByID(ctx, feedID)
-	if err != nil {
-		t.Fatalf("FeedFindByID should succeed after delete, but got error: %v", err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("FeedFindByID should return ErrNotFound, but got: %v", err)
 	}
 	if foundFeed != nil {
 		t.Error("Feed should not be found after delete, but was found")
@@ -263,8 +585,8 @@ func TestStoreFeedList(t *testing.T) {
 
 	// Create test feeds
 	feed1 := NewFeed().SetName("Feed 1").SetStatus(FEED_STATUS_ACTIVE)
-	feed2 := NewFeed().SetName("Feed 2").SetStatus(FEED_STATUS_INACTIVE)
-	feed3 := NewFeed().SetName("Feed 3").SetStatus(FEED_STATUS_ACTIVE)
+	feed2 := NewFeed().SetName("Feed 2").SetStatus(FEED_STATUS_INACTIVE).SetURL("https://example.com/feed2.xml")
+	feed3 := NewFeed().SetName("Feed 3").SetStatus(FEED_STATUS_ACTIVE).SetLastFetchedAt("2024-01-01 00:00:00").SetCategory("News")
 	feed4 := NewFeed().SetName("Feed 4").SetStatus(FEED_STATUS_ACTIVE) // To be soft deleted
 
 	if err := store.FeedCreate(ctx, feed1); err != nil {
@@ -318,6 +640,30 @@ func TestStoreFeedList(t *testing.T) {
 			expectedCount: 1,
 			expectedIDs:   []string{feed2.ID()},
 		},
+		{
+			name:          "List with LastFetchedAt Gte",
+			query:         FeedQuery().SetLastFetchedAtGte("2023-01-01 00:00:00").SetLimit(10),
+			expectedCount: 1,
+			expectedIDs:   []string{feed3.ID()},
+		},
+		{
+			name:          "List with LastFetchedAt Lte",
+			query:         FeedQuery().SetLastFetchedAtLte("2023-01-01 00:00:00").SetLimit(10),
+			expectedCount: 2,
+			expectedIDs:   []string{feed1.ID(), feed2.ID()},
+		},
+		{
+			name:          "List with URL",
+			query:         FeedQuery().SetURL("https://example.com/feed2.xml"),
+			expectedCount: 1,
+			expectedIDs:   []string{feed2.ID()},
+		},
+		{
+			name:          "List with Category",
+			query:         FeedQuery().SetCategory("News").SetLimit(10),
+			expectedCount: 1,
+			expectedIDs:   []string{feed3.ID()},
+		},
 		{
 			name:          "List with Limit",
 			query:         FeedQuery().SetLimit(2),
@@ -449,8 +795,8 @@ func TestStoreFeedSoftDelete(t *testing.T) {
 
 	// 5. Verify it's not found by default FindByID
 	foundFeed, err = store.FeedFindByID(ctx, feed.ID())
-	if err != nil {
-		t.Fatalf("FeedFindByID after soft delete failed: %v", err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("FeedFindByID should return ErrNotFound, but got: %v", err)
 	}
 	if foundFeed != nil {
 		t.Error("Feed should not be found by default FindByID after soft delete")
@@ -506,8 +852,8 @@ func TestStoreFeedSoftDeleteByID(t *testing.T) {
 
 	// 4. Verify it's not found by default FindByID
 	foundFeed, err = store.FeedFindByID(ctx, feedID)
-	if err != nil {
-		t.Fatalf("FeedFindByID after soft delete failed: %v", err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("FeedFindByID should return ErrNotFound, but got: %v", err)
 	}
 	if foundFeed != nil {
 		t.Error("Feed should not be found by default FindByID after soft delete")
@@ -743,8 +1089,8 @@ func TestStoreLinkDelete(t *testing.T) {
 
 	// 4. Verify it's gone
 	foundLink, err = store.LinkFindByID(ctx, link.ID())
-	if err != nil {
-		t.Fatalf("LinkFindByID after delete failed: %v", err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("LinkFindByID should return ErrNotFound, but got: %v", err)
 	}
 	if foundLink != nil {
 		t.Error("Link should not be found after delete")
@@ -794,8 +1140,8 @@ func TestStoreLinkDeleteByID(t *testing.T) {
 
 	// 4. Verify it's gone
 	foundLink, err = store.LinkFindByID(ctx, linkID)
-	if err != nil {
-		t.Fatalf("LinkFindByID after delete failed: %v", err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("LinkFindByID should return ErrNotFound, but got: %v", err)
 	}
 	if foundLink != nil {
 		t.Error("Link should not be found after delete")
@@ -1010,8 +1356,8 @@ func TestStoreLinkSoftDelete(t *testing.T) {
 
 	// 5. Verify it's not found by default FindByID
 	foundLink, err = store.LinkFindByID(ctx, link.ID())
-	if err != nil {
-		t.Fatalf("LinkFindByID after soft delete failed: %v", err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("LinkFindByID should return ErrNotFound, but got: %v", err)
 	}
 	if foundLink != nil {
 		t.Error("Link should not be found by default FindByID after soft delete")
@@ -1067,8 +1413,8 @@ func TestStoreLinkSoftDeleteByID(t *testing.T) {
 
 	// 4. Verify it's not found by default FindByID
 	foundLink, err = store.LinkFindByID(ctx, linkID)
-	if err != nil {
-		t.Fatalf("LinkFindByID after soft delete failed: %v", err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("LinkFindByID should return ErrNotFound, but got: %v", err)
 	}
 	if foundLink != nil {
 		t.Error("Link should not be found by default FindByID after soft delete")
@@ -1184,3 +1530,968 @@ func TestStoreLinkUpdate(t *testing.T) {
 		t.Error("LinkUpdate should return error for nil link")
 	}
 }
+
+func TestStoreLinkUpsert(t *testing.T) {
+	db := initDB(":memory:")
+	defer db.Close()
+	store := createTestStore(t, db, "feed_link_upsert", "link_link_upsert")
+	ctx := context.Background()
+
+	// 1. Insert a new entry
+	link := NewLink().SetTitle("Original Title").SetDescription("Original Description").SetStatus(LINK_STATUS_ACTIVE).SetFeedID("feedX").SetURL("http://entry.url").SetGUID("entry-1")
+	err := store.LinkUpsert(ctx, link)
+	if err != nil {
+		t.Fatalf("LinkUpsert (insert) failed: %v", err)
+	}
+	originalID := link.ID()
+
+	// 2. Upsert the same entry again with a changed title and description
+	again := NewLink().SetTitle("Changed Title").SetDescription("Changed Description").SetStatus(LINK_STATUS_ACTIVE).SetFeedID("feedX").SetURL("http://entry.url").SetGUID("entry-1")
+	err = store.LinkUpsert(ctx, again)
+	if err != nil {
+		t.Fatalf("LinkUpsert (update) failed: %v", err)
+	}
+	if again.ID() != originalID {
+		t.Errorf("LinkUpsert should set the ID of the existing row, expected '%s', got '%s'", originalID, again.ID())
+	}
+
+	count, err := store.LinkCount(ctx, LinkQuery().SetFeedID("feedX"))
+	if err != nil {
+		t.Fatalf("LinkCount failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Upserting the same GUID should not duplicate the link, expected 1, got %d", count)
+	}
+
+	found, err := store.LinkFindByID(ctx, originalID)
+	if err != nil || found == nil {
+		t.Fatalf("LinkFindByID after upsert failed: %v", err)
+	}
+	if found.Title() != "Changed Title" {
+		t.Errorf("Title should be updated, expected 'Changed Title', got '%s'", found.Title())
+	}
+	if found.Description() != "Changed Description" {
+		t.Errorf("Description should be updated, expected 'Changed Description', got '%s'", found.Description())
+	}
+
+	// 3. Same GUID in another feed is a different entry
+	otherFeed := NewLink().SetTitle("Other Feed").SetStatus(LINK_STATUS_ACTIVE).SetFeedID("feedY").SetURL("http://entry.url").SetGUID("entry-1")
+	if err := store.LinkUpsert(ctx, otherFeed); err != nil {
+		t.Fatalf("LinkUpsert for another feed failed: %v", err)
+	}
+	if otherFeed.ID() == originalID {
+		t.Error("The same GUID in another feed should create a new link")
+	}
+
+	// 4. Links without an explicit GUID do not collide
+	for i := 0; i < 2; i++ {
+		if err := store.LinkCreate(ctx, NewLink().SetTitle("No GUID").SetStatus(LINK_STATUS_ACTIVE).SetFeedID("feedX").SetURL("http://no.guid")); err != nil {
+			t.Fatalf("LinkCreate without GUID failed: %v", err)
+		}
+	}
+
+	// 5. Invalid input
+	if err := store.LinkUpsert(ctx, nil); err == nil {
+		t.Error("LinkUpsert should return error for nil link")
+	}
+	if err := store.LinkUpsert(ctx, NewLink().SetFeedID("feedX").SetGUID("")); err == nil {
+		t.Error("LinkUpsert should return error for empty GUID")
+	}
+	if err := store.LinkUpsert(ctx, NewLink().SetGUID("entry-2")); err == nil {
+		t.Error("LinkUpsert should return error for empty feed ID")
+	}
+}
+
+func TestStoreLinkUpsertSQL(t *testing.T) {
+	data := NewLink().SetFeedID("feed1").SetGUID("guid1").SetTitle("Title").Data()
+
+	testCases := []struct {
+		driverName string
+		expected   []string
+	}{
+		{
+			driverName: "sqlite3",
+			expected: []string{
+				"INSERT INTO `links`",
+				`ON CONFLICT ("feed_id", "guid") DO UPDATE SET`,
+				`"title" = excluded."title"`,
+			},
+		},
+		{
+			driverName: sb.DIALECT_POSTGRES,
+			expected: []string{
+				`INSERT INTO "links"`,
+				"$1",
+				`ON CONFLICT ("feed_id", "guid") DO UPDATE SET`,
+				`"title" = excluded."title"`,
+			},
+		},
+		{
+			driverName: sb.DIALECT_MYSQL,
+			expected: []string{
+				"INSERT INTO `links`",
+				"ON DUPLICATE KEY UPDATE",
+				"`title` = VALUES(`title`)",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.driverName, func(t *testing.T) {
+			store := &storeImplementation{dbDriverName: tc.driverName, linkTableName: "links"}
+
+			sqlStr, params, err := store.sqlLinkUpsert(data)
+			if err != nil {
+				t.Fatalf("sqlLinkUpsert should succeed, but got error: %v", err)
+			}
+			for _, expected := range tc.expected {
+				if !strings.Contains(sqlStr, expected) {
+					t.Errorf("Expected the upsert to contain %q, got: %s", expected, sqlStr)
+				}
+			}
+			if strings.Contains(sqlStr, "IGNORE") {
+				t.Errorf("Expected the upsert not to ignore errors, got: %s", sqlStr)
+			}
+			if len(params) != len(data) {
+				t.Errorf("Expected %d parameters, got %d", len(data), len(params))
+			}
+		})
+	}
+}
+
+func TestStoreLinkGUIDIndexSQL(t *testing.T) {
+	testCases := []struct {
+		driverName string
+		expected   string
+	}{
+		{sb.DIALECT_SQLITE, `("feed_id", "guid")`},
+		{sb.DIALECT_POSTGRES, `("feed_id", "guid")`},
+		{sb.DIALECT_MYSQL, "(`feed_id`, `guid`(512))"},
+	}
+
+	for _, tc := range testCases {
+		store := &storeImplementation{dbDriverName: tc.driverName}
+
+		sqlStr := store.sqlIndexCreate("links", "links_feed_id_guid_unique", true, linkGUIDUniqueColumns...)
+		if !strings.Contains(sqlStr, tc.expected) {
+			t.Errorf("%s: expected the GUID index on %s, got: %s", tc.driverName, tc.expected, sqlStr)
+		}
+	}
+}
+
+// recordingDriver is a database/sql driver which records the statements it
+// is given. Every query returns a count of 0, so the schema lookups of the
+// migrations find nothing.
+type recordingDriver struct {
+	mutex      sync.Mutex
+	statements []string
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+func (d *recordingDriver) record(query string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.statements = append(d.statements, query)
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{driver: c.driver, query: query}, nil
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *recordingConn) Commit() error { return nil }
+
+func (c *recordingConn) Rollback() error { return nil }
+
+type recordingStmt struct {
+	driver *recordingDriver
+	query  string
+}
+
+func (s *recordingStmt) Close() error { return nil }
+
+func (s *recordingStmt) NumInput() int { return -1 }
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.driver.record(s.query)
+	return driver.RowsAffected(0), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.driver.record(s.query)
+	return &recordingRows{}, nil
+}
+
+type recordingRows struct {
+	done bool
+}
+
+func (r *recordingRows) Columns() []string { return []string{"count"} }
+
+func (r *recordingRows) Close() error { return nil }
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(0)
+	return nil
+}
+
+func TestStoreMigrateLinkGUIDMySQL(t *testing.T) {
+	recorder := &recordingDriver{}
+	sql.Register("feedstore_recording_mysql", recorder)
+
+	db, err := sql.Open("feedstore_recording_mysql", "")
+	if err != nil {
+		t.Fatalf("Opening the recording database failed: %v", err)
+	}
+	defer db.Close()
+
+	store := &storeImplementation{
+		db:            db,
+		dbDriverName:  sb.DIALECT_MYSQL,
+		feedTableName: "feeds",
+		linkTableName: "links",
+	}
+
+	migrations := store.migrations()
+	ctx := context.Background()
+
+	// Migration 3 adds the guid as VARCHAR, which is too short for the
+	// prefix MySQL indexes, so it must not create the index
+	if err := migrations[2].up(ctx, store); err != nil {
+		t.Fatalf("Migration 3 should succeed, but got error: %v", err)
+	}
+
+	migration3 := strings.Join(recorder.statements, "\n")
+	if !strings.Contains(migration3, "ALTER TABLE `links` ADD `guid` VARCHAR(255)") {
+		t.Errorf("Expected migration 3 to add the guid column, got:\n%s", migration3)
+	}
+	if strings.Contains(migration3, "CREATE UNIQUE INDEX") {
+		t.Errorf("Expected migration 3 not to index the guid on MySQL, got:\n%s", migration3)
+	}
+
+	// Migration 9 makes the guid TEXT, and then indexes its prefix
+	recorder.statements = nil
+
+	if err := migrations[8].up(ctx, store); err != nil {
+		t.Fatalf("Migration 9 should succeed, but got error: %v", err)
+	}
+
+	migration9 := strings.Join(recorder.statements, "\n")
+	change := strings.Index(migration9, "MODIFY COLUMN `guid`")
+	index := strings.Index(migration9, "CREATE UNIQUE INDEX `links_feed_id_guid_unique` ON `links` (`feed_id`, `guid`(512))")
+	if change < 0 || index < 0 || index < change {
+		t.Errorf("Expected migration 9 to change the guid to TEXT and then index its prefix, got:\n%s", migration9)
+	}
+}
+
+func TestStoreIndexExistsSQL(t *testing.T) {
+	testCases := []struct {
+		driverName string
+		expected   string
+	}{
+		{sb.DIALECT_SQLITE, `"sqlite_master"`},
+		{sb.DIALECT_POSTGRES, `("schemaname" = current_schema())`},
+		{sb.DIALECT_MYSQL, "(`table_schema` = DATABASE())"},
+	}
+
+	for _, tc := range testCases {
+		store := &storeImplementation{dbDriverName: tc.driverName}
+
+		sqlStr, params, err := store.sqlIndexExists("links", "links_url_index")
+		if err != nil {
+			t.Fatalf("%s: sqlIndexExists should succeed, but got error: %v", tc.driverName, err)
+		}
+		if !strings.Contains(sqlStr, tc.expected) {
+			t.Errorf("%s: expected the lookup to contain %s, got: %s", tc.driverName, tc.expected, sqlStr)
+		}
+		if !reflect.DeepEqual(params[len(params)-2:], []any{"links", "links_url_index"}) {
+			t.Errorf("%s: expected the table and index names as parameters, got %v", tc.driverName, params)
+		}
+	}
+}
+
+func TestLinkRankingScoreSQL(t *testing.T) {
+	ranking := DefaultLinkRanking()
+	ranking.ViewsWeight = 0.5
+	ranking.Now = "2024-01-01 00:00:00"
+
+	testCases := []struct {
+		driverName string
+		expected   []string
+	}{
+		{
+			driverName: sb.DIALECT_SQLITE,
+			expected: []string{
+				`(1 * "votes_up" - 1 * "votes_down" + 0.5 * "views")`,
+				`MAX((julianday('2024-01-01 00:00:00') - julianday(CASE WHEN "time" > '0002-01-01 00:00:00' THEN "time" ELSE "created_at" END)) * 24.0, 0)`,
+				"+ 2, 1.8)",
+			},
+		},
+		{
+			driverName: sb.DIALECT_POSTGRES,
+			expected: []string{
+				`(CAST(1 AS DOUBLE PRECISION) * "votes_up" - CAST(1 AS DOUBLE PRECISION) * "votes_down" + CAST(0.5 AS DOUBLE PRECISION) * "views")`,
+				`GREATEST(EXTRACT(EPOCH FROM (CAST('2024-01-01 00:00:00' AS TIMESTAMP) - CASE WHEN "time" > '0002-01-01 00:00:00' THEN "time" ELSE "created_at" END)) / 3600.0, 0)`,
+				"+ CAST(2 AS DOUBLE PRECISION), CAST(1.8 AS DOUBLE PRECISION))",
+			},
+		},
+		{
+			driverName: sb.DIALECT_MYSQL,
+			expected: []string{
+				"(1 * `votes_up` - 1 * `votes_down` + 0.5 * `views`)",
+				"GREATEST(TIMESTAMPDIFF(SECOND, CASE WHEN `time` > '0002-01-01 00:00:00' THEN `time` ELSE `created_at` END, '2024-01-01 00:00:00') / 3600.0, 0)",
+				"+ 2, 1.8)",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.driverName, func(t *testing.T) {
+			sqlStr, _, err := goqu.Dialect(tc.driverName).
+				From("links").
+				Select(linkRankingScore(tc.driverName, ranking)).
+				ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL should succeed, but got error: %v", err)
+			}
+			if !strings.Contains(sqlStr, " / POWER(") {
+				t.Errorf("Expected the score to divide the points by the aged power, got: %s", sqlStr)
+			}
+			for _, expected := range tc.expected {
+				if !strings.Contains(sqlStr, expected) {
+					t.Errorf("Expected the score to contain %q, got: %s", expected, sqlStr)
+				}
+			}
+		})
+	}
+}
+
+func TestNewStoreDialectNotRegistered(t *testing.T) {
+	db := initDB(":memory:")
+	defer db.Close()
+
+	goqu.DeregisterDialect(sb.DIALECT_MYSQL)
+	defer goqu.RegisterDialect(sb.DIALECT_MYSQL, mysql.DialectOptions())
+
+	_, err := NewStore(NewStoreOptions{
+		DB:            db,
+		DbDriverName:  sb.DIALECT_MYSQL,
+		FeedTableName: "feed_dialect",
+		LinkTableName: "link_dialect",
+	})
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("NewStore should return ErrValidation when the goqu dialect is not registered, but got: %v", err)
+	}
+}
+
+func TestStoreWithTx(t *testing.T) {
+	// A file database, an in-memory one is private to each connection
+	db := initDB(t.TempDir() + "/tx.db")
+	defer db.Close()
+	store := createTestStore(t, db, "feed_tx", "link_tx")
+	ctx := context.Background()
+
+	// Helper function to create a feed with one link in the transaction
+	createFeedWithLink := func(tx StoreInterface, name string) (FeedInterface, error) {
+		feed := NewFeed().SetName(name)
+		if err := tx.FeedCreate(ctx, feed); err != nil {
+			return nil, err
+		}
+
+		link := NewLink().SetFeedID(feed.ID()).SetTitle(name + " link").SetURL("https://example.com/" + name).SetStatus(LINK_STATUS_ACTIVE)
+		if err := tx.LinkCreate(ctx, link); err != nil {
+			return nil, err
+		}
+
+		// Reads in the transaction see its own writes
+		count, err := tx.LinkCount(ctx, LinkQuery().SetFeedID(feed.ID()))
+		if err != nil {
+			return nil, err
+		}
+		if count != 1 {
+			return nil, fmt.Errorf("expected 1 link within the transaction, got %d", count)
+		}
+
+		return feed, nil
+	}
+
+	// Helper function to count the feeds and links outside of any transaction
+	countAll := func() (int64, int64) {
+		feeds, err := store.FeedCount(ctx, FeedQuery())
+		if err != nil {
+			t.Fatalf("FeedCount should succeed, but got error: %v", err)
+		}
+		links, err := store.LinkCount(ctx, LinkQuery())
+		if err != nil {
+			t.Fatalf("LinkCount should succeed, but got error: %v", err)
+		}
+		return feeds, links
+	}
+
+	t.Run("Commit on success", func(t *testing.T) {
+		err := store.WithTx(ctx, func(tx StoreInterface) error {
+			_, err := createFeedWithLink(tx, "committed")
+			return err
+		})
+		if err != nil {
+			t.Fatalf("WithTx should succeed, but got error: %v", err)
+		}
+		if feeds, links := countAll(); feeds != 1 || links != 1 {
+			t.Errorf("Expected 1 feed and 1 link after commit, got %d and %d", feeds, links)
+		}
+	})
+
+	t.Run("Rollback on error", func(t *testing.T) {
+		errIngest := fmt.Errorf("ingest failed")
+		err := store.WithTx(ctx, func(tx StoreInterface) error {
+			if _, err := createFeedWithLink(tx, "failed"); err != nil {
+				return err
+			}
+			return errIngest
+		})
+		if err != errIngest {
+			t.Errorf("WithTx should return the error of the function, got %v", err)
+		}
+		if feeds, links := countAll(); feeds != 1 || links != 1 {
+			t.Errorf("Expected the failed ingest to be rolled back, got %d feeds and %d links", feeds, links)
+		}
+	})
+
+	t.Run("Rollback on panic", func(t *testing.T) {
+		func() {
+			defer func() {
+				if r := recover(); r != "boom" {
+					t.Errorf("WithTx should re-raise the panic, got %v", r)
+				}
+			}()
+
+			store.WithTx(ctx, func(tx StoreInterface) error {
+				if _, err := createFeedWithLink(tx, "panicked"); err != nil {
+					return err
+				}
+				panic("boom")
+			})
+		}()
+
+		if feeds, links := countAll(); feeds != 1 || links != 1 {
+			t.Errorf("Expected the panicked ingest to be rolled back, got %d feeds and %d links", feeds, links)
+		}
+	})
+
+	t.Run("Nested WithTx joins the transaction", func(t *testing.T) {
+		errOuter := fmt.Errorf("outer failed")
+		err := store.WithTx(ctx, func(tx StoreInterface) error {
+			errNested := tx.WithTx(ctx, func(nested StoreInterface) error {
+				_, err := createFeedWithLink(nested, "nested")
+				return err
+			})
+			if errNested != nil {
+				return errNested
+			}
+			return errOuter
+		})
+		if err != errOuter {
+			t.Errorf("WithTx should return the error of the outer function, got %v", err)
+		}
+		if feeds, links := countAll(); feeds != 1 || links != 1 {
+			t.Errorf("Expected the nested writes to be rolled back with the outer transaction, got %d feeds and %d links", feeds, links)
+		}
+	})
+}
+
+func TestStoreFeedCreateMany(t *testing.T) {
+	db := initDB(":memory:")
+	defer db.Close()
+	store := createTestStore(t, db, "feed_create_many", "link_create_many")
+	ctx := context.Background()
+
+	feeds := []FeedInterface{}
+	for i := 0; i < 3; i++ {
+		feeds = append(feeds, NewFeed().SetName(fmt.Sprintf("Feed %d", i)).SetStatus(FEED_STATUS_ACTIVE))
+	}
+
+	if err := store.FeedCreateMany(ctx, feeds); err != nil {
+		t.Fatalf("FeedCreateMany should succeed, but got error: %v", err)
+	}
+
+	count, err := store.FeedCount(ctx, FeedQuery())
+	if err != nil {
+		t.Fatalf("FeedCount should succeed, but got error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 feeds to be created, got %d", count)
+	}
+
+	for _, feed := range feeds {
+		if len(feed.DataChanged()) != 0 {
+			t.Errorf("Feed %s should be marked as not dirty after FeedCreateMany", feed.ID())
+		}
+	}
+
+	if err := store.FeedCreateMany(ctx, []FeedInterface{}); err != nil {
+		t.Errorf("FeedCreateMany with no feeds should be a no-op, but got error: %v", err)
+	}
+}
+
+func TestStoreLinkCreateMany(t *testing.T) {
+	db := initDB(":memory:")
+	defer db.Close()
+	store := createTestStore(t, db, "feed_link_create_many", "link_link_create_many")
+	ctx := context.Background()
+
+	// More links than fit in one statement, to insert in several chunks
+	links := []LinkInterface{}
+	for i := 0; i < 250; i++ {
+		links = append(links, NewLink().
+			SetFeedID("feed1").
+			SetTitle(fmt.Sprintf("Link %d", i)).
+			SetURL(fmt.Sprintf("https://example.com/%d", i)).
+			SetStatus(LINK_STATUS_ACTIVE))
+	}
+
+	if err := store.LinkCreateMany(ctx, links); err != nil {
+		t.Fatalf("LinkCreateMany should succeed, but got error: %v", err)
+	}
+
+	count, err := store.LinkCount(ctx, LinkQuery().SetFeedID("feed1"))
+	if err != nil {
+		t.Fatalf("LinkCount should succeed, but got error: %v", err)
+	}
+	if count != 250 {
+		t.Errorf("Expected 250 links to be created, got %d", count)
+	}
+
+	for _, link := range links {
+		if link.CreatedAt() != links[0].CreatedAt() || link.UpdatedAt() != links[0].CreatedAt() {
+			t.Fatalf("All links should share the same timestamps, got created '%s' and updated '%s', expected '%s'", link.CreatedAt(), link.UpdatedAt(), links[0].CreatedAt())
+		}
+		if len(link.DataChanged()) != 0 {
+			t.Fatalf("Link %s should be marked as not dirty after LinkCreateMany", link.ID())
+		}
+	}
+
+	// A failing chunk rolls back the whole batch
+	duplicate := []LinkInterface{
+		NewLink().SetFeedID("feed2").SetTitle("New").SetURL("https://example.com/new").SetStatus(LINK_STATUS_ACTIVE),
+		links[0],
+	}
+	if err := store.LinkCreateMany(ctx, duplicate); err == nil {
+		t.Fatal("LinkCreateMany should fail for a link which already exists")
+	}
+
+	count, err = store.LinkCount(ctx, LinkQuery().SetFeedID("feed2"))
+	if err != nil {
+		t.Fatalf("LinkCount should succeed, but got error: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected the failed batch to be rolled back, got %d links", count)
+	}
+	if len(duplicate[0].DataChanged()) == 0 {
+		t.Error("Links of a failed batch should stay dirty")
+	}
+}
+
+func TestStoreFeedRestore(t *testing.T) {
+	db := initDB(":memory:")
+	defer db.Close()
+	store := createTestStore(t, db, "feed_restore", "link_feed_restore")
+	ctx := context.Background()
+
+	feeds := []FeedInterface{}
+	for i := 0; i < 4; i++ {
+		feed := NewFeed().SetName(fmt.Sprintf("Feed %d", i)).SetStatus(FEED_STATUS_ACTIVE)
+		if err := store.FeedCreate(ctx, feed); err != nil {
+			t.Fatalf("FeedCreate should succeed, but got error: %v", err)
+		}
+		if err := store.FeedSoftDelete(ctx, feed); err != nil {
+			t.Fatalf("FeedSoftDelete should succeed, but got error: %v", err)
+		}
+		feeds = append(feeds, feed)
+	}
+
+	// FeedFindByID only finds soft deleted feeds when asked to
+	found, err := store.FeedFindByID(ctx, feeds[0].ID())
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("FeedFindByID should return ErrNotFound, but got: %v", err)
+	}
+	if found != nil {
+		t.Error("FeedFindByID should not find a soft deleted feed by default")
+	}
+
+	found, err = store.FeedFindByID(ctx, feeds[0].ID(), FindByIDOptions{WithSoftDeleted: true})
+	if err != nil {
+		t.Fatalf("FeedFindByID with soft deleted should succeed, but got error: %v", err)
+	}
+	if found == nil {
+		t.Fatal("FeedFindByID with soft deleted should find the soft deleted feed")
+	}
+
+	// Restore by object
+	if err := store.FeedRestore(ctx, found); err != nil {
+		t.Fatalf("FeedRestore should succeed, but got error: %v", err)
+	}
+	if found, _ := store.FeedFindByID(ctx, feeds[0].ID()); found == nil {
+		t.Error("Feed should be found after FeedRestore")
+	}
+
+	// Restore by ID
+	if err := store.FeedRestoreByID(ctx, feeds[1].ID()); err != nil {
+		t.Fatalf("FeedRestoreByID should succeed, but got error: %v", err)
+	}
+	if found, _ := store.FeedFindByID(ctx, feeds[1].ID()); found == nil {
+		t.Error("Feed should be found after FeedRestoreByID")
+	}
+
+	if err := store.FeedRestoreByID(ctx, "non-existent-id"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FeedRestoreByID should return ErrNotFound for a non-existent ID, but got: %v", err)
+	}
+
+	// Restore by query
+	restored, err := store.FeedRestoreByQuery(ctx, FeedQuery().SetIDIn([]string{feeds[0].ID(), feeds[2].ID()}))
+	if err != nil {
+		t.Fatalf("FeedRestoreByQuery should succeed, but got error: %v", err)
+	}
+	if restored != 1 {
+		t.Errorf("FeedRestoreByQuery should only restore soft deleted feeds, expected 1 got %d", restored)
+	}
+
+	count, err := store.FeedCount(ctx, FeedQuery())
+	if err != nil {
+		t.Fatalf("FeedCount should succeed, but got error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 feeds after restoring, got %d", count)
+	}
+
+	if err := store.FeedRestore(ctx, nil); err == nil {
+		t.Error("FeedRestore should return an error for a nil feed")
+	}
+}
+
+func TestStoreLinkRestore(t *testing.T) {
+	db := initDB(":memory:")
+	defer db.Close()
+	store := createTestStore(t, db, "feed_link_restore", "link_restore")
+	ctx := context.Background()
+
+	links := []LinkInterface{}
+	for i := 0; i < 4; i++ {
+		link := NewLink().SetFeedID("feed1").SetTitle(fmt.Sprintf("Link %d", i)).SetURL(fmt.Sprintf("https://example.com/%d", i)).SetStatus(LINK_STATUS_ACTIVE)
+		if err := store.LinkCreate(ctx, link); err != nil {
+			t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+		}
+		if err := store.LinkSoftDelete(ctx, link); err != nil {
+			t.Fatalf("LinkSoftDelete should succeed, but got error: %v", err)
+		}
+		links = append(links, link)
+	}
+
+	found, err := store.LinkFindByID(ctx, links[0].ID(), FindByIDOptions{WithSoftDeleted: true})
+	if err != nil || found == nil {
+		t.Fatalf("LinkFindByID with soft deleted should find the link, got %v, %v", found, err)
+	}
+
+	if err := store.LinkRestore(ctx, found); err != nil {
+		t.Fatalf("LinkRestore should succeed, but got error: %v", err)
+	}
+	if err := store.LinkRestoreByID(ctx, links[1].ID()); err != nil {
+		t.Fatalf("LinkRestoreByID should succeed, but got error: %v", err)
+	}
+
+	count, _ := store.LinkCount(ctx, LinkQuery().SetFeedID("feed1"))
+	if count != 2 {
+		t.Errorf("Expected 2 links after restoring by object and ID, got %d", count)
+	}
+
+	restored, err := store.LinkRestoreByQuery(ctx, LinkQuery().SetFeedID("feed1"))
+	if err != nil {
+		t.Fatalf("LinkRestoreByQuery should succeed, but got error: %v", err)
+	}
+	if restored != 2 {
+		t.Errorf("LinkRestoreByQuery should restore the 2 remaining links, got %d", restored)
+	}
+
+	count, _ = store.LinkCount(ctx, LinkQuery().SetFeedID("feed1"))
+	if count != 4 {
+		t.Errorf("Expected 4 links after restoring by query, got %d", count)
+	}
+}
+
+func TestNewStoreInvalidCascadeMode(t *testing.T) {
+	_, err := NewStore(NewStoreOptions{
+		DB:            initDB(":memory:"),
+		FeedTableName: "feed_cascade_invalid",
+		LinkTableName: "link_cascade_invalid",
+		CascadeMode:   "invalid",
+	})
+	if err == nil {
+		t.Error("NewStore should return an error for an unknown cascade mode")
+	}
+}
+
+func TestStorePurgeSoftDeleted(t *testing.T) {
+	db := initDB(":memory:")
+	defer db.Close()
+	store := createTestStore(t, db, "feed_purge", "link_purge")
+	ctx := context.Background()
+
+	longAgo := "2020-01-01 00:00:00"
+	recently := carbon.Now(carbon.UTC).SubHours(1).ToDateTimeString(carbon.UTC)
+
+	feeds := []FeedInterface{
+		NewFeed().SetName("Old 1").SetSoftDeletedAt(longAgo),
+		NewFeed().SetName("Old 2").SetSoftDeletedAt(longAgo),
+		NewFeed().SetName("Recent").SetSoftDeletedAt(recently),
+		NewFeed().SetName("Active"),
+	}
+	if err := store.FeedCreateMany(ctx, feeds); err != nil {
+		t.Fatalf("FeedCreateMany should succeed, but got error: %v", err)
+	}
+
+	// More old links than fit in one batch
+	links := []LinkInterface{}
+	for i := 0; i < PURGE_BATCH_SIZE+20; i++ {
+		links = append(links, NewLink().SetFeedID("feed1").SetTitle("Old").SetURL(fmt.Sprintf("https://example.com/%d", i)).SetStatus(LINK_STATUS_ACTIVE).SetSoftDeletedAt(longAgo))
+	}
+	links = append(links,
+		NewLink().SetFeedID("feed1").SetTitle("Recent").SetURL("https://example.com/recent").SetStatus(LINK_STATUS_ACTIVE).SetSoftDeletedAt(recently),
+		NewLink().SetFeedID("feed1").SetTitle("Active").SetURL("https://example.com/active").SetStatus(LINK_STATUS_ACTIVE))
+	if err := store.LinkCreateMany(ctx, links); err != nil {
+		t.Fatalf("LinkCreateMany should succeed, but got error: %v", err)
+	}
+
+	result, err := store.PurgeSoftDeleted(ctx, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("PurgeSoftDeleted should succeed, but got error: %v", err)
+	}
+	if result.Feeds != 2 {
+		t.Errorf("Expected 2 feeds to be purged, got %d", result.Feeds)
+	}
+	if result.Links != int64(PURGE_BATCH_SIZE+20) {
+		t.Errorf("Expected %d links to be purged, got %d", PURGE_BATCH_SIZE+20, result.Links)
+	}
+
+	feedCount, _ := store.FeedCount(ctx, FeedQuery().SetWithSoftDeleted(true))
+	if feedCount != 2 {
+		t.Errorf("Expected the recently deleted and the active feed to remain, got %d feeds", feedCount)
+	}
+	linkCount, _ := store.LinkCount(ctx, LinkQuery().SetWithSoftDeleted(true))
+	if linkCount != 2 {
+		t.Errorf("Expected the recently deleted and the active link to remain, got %d links", linkCount)
+	}
+
+	if _, err := store.PurgeSoftDeleted(ctx, -time.Hour); err == nil {
+		t.Error("PurgeSoftDeleted should return an error for a negative retention window")
+	}
+}
+
+func TestStoreEnforceRetention(t *testing.T) {
+	db := initDB(":memory:")
+	defer db.Close()
+	store := createTestStore(t, db, "feed_retention", "link_retention")
+	ctx := context.Background()
+
+	byItems := NewFeed().SetName("Max items").SetRetentionMaxItems("3")
+	byAge := NewFeed().SetName("Max age").SetRetentionMaxAge(fmt.Sprint(7 * 24 * 60 * 60))
+	unlimited := NewFeed().SetName("Unlimited")
+	if err := store.FeedCreateMany(ctx, []FeedInterface{byItems, byAge, unlimited}); err != nil {
+		t.Fatalf("FeedCreateMany should succeed, but got error: %v", err)
+	}
+
+	found, err := store.FeedFindByID(ctx, byItems.ID())
+	if err != nil || found == nil {
+		t.Fatalf("FeedFindByID should find the feed, got %v, %v", found, err)
+	}
+	if maxItems, _ := found.RetentionMaxItemsInt64(); maxItems != 3 {
+		t.Errorf("RetentionMaxItems should be stored, expected 3 got %d", maxItems)
+	}
+
+	now := carbon.Now(carbon.UTC)
+	links := []LinkInterface{}
+	for _, feed := range []FeedInterface{byItems, byAge, unlimited} {
+		// Links 1, 3, 5, 10 and 20 days old
+		for _, days := range []int{1, 3, 5, 10, 20} {
+			links = append(links, NewLink().
+				SetFeedID(feed.ID()).
+				SetTitle(fmt.Sprintf("%d days old", days)).
+				SetURL(fmt.Sprintf("https://example.com/%s/%d", feed.ID(), days)).
+				SetStatus(LINK_STATUS_ACTIVE).
+				SetTime(now.Copy().SubDays(days).ToDateTimeString(carbon.UTC)))
+		}
+	}
+	if err := store.LinkCreateMany(ctx, links); err != nil {
+		t.Fatalf("LinkCreateMany should succeed, but got error: %v", err)
+	}
+
+	removed, err := store.EnforceRetention(ctx, RetentionOptions{})
+	if err != nil {
+		t.Fatalf("EnforceRetention should succeed, but got error: %v", err)
+	}
+	if removed != 4 {
+		t.Errorf("Expected 4 links to be removed (2 beyond max items, 2 beyond max age), got %d", removed)
+	}
+
+	// Helper function to list the titles of the visible links of a feed
+	titles := func(feed FeedInterface) []string {
+		list, err := store.LinkList(ctx, LinkQuery().SetFeedID(feed.ID()))
+		if err != nil {
+			t.Fatalf("LinkList should succeed, but got error: %v", err)
+		}
+		return lo.Map(list, func(link LinkInterface, _ int) string { return link.Title() })
+	}
+
+	if kept := titles(byItems); !elementsMatch(t, []string{"1 days old", "3 days old", "5 days old"}, kept) {
+		t.Errorf("Expected the 3 newest links to be kept, got %v", kept)
+	}
+	if kept := titles(byAge); !elementsMatch(t, []string{"1 days old", "3 days old", "5 days old"}, kept) {
+		t.Errorf("Expected the links of the last 7 days to be kept, got %v", kept)
+	}
+	if kept := titles(unlimited); len(kept) != 5 {
+		t.Errorf("Expected all links of a feed without retention to be kept, got %v", kept)
+	}
+
+	// Soft deleted by default, hard deleted on request
+	if count, _ := store.LinkCount(ctx, LinkQuery().SetWithSoftDeleted(true)); count != 15 {
+		t.Errorf("Expected the removed links to be soft deleted, got %d stored links", count)
+	}
+
+	byItems.SetRetentionMaxItems("1")
+	if err := store.FeedUpdate(ctx, byItems); err != nil {
+		t.Fatalf("FeedUpdate should succeed, but got error: %v", err)
+	}
+
+	removed, err = store.FeedEnforceRetention(ctx, byItems, RetentionOptions{HardDelete: true})
+	if err != nil {
+		t.Fatalf("FeedEnforceRetention should succeed, but got error: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Expected 2 links to be removed, got %d", removed)
+	}
+	if count, _ := store.LinkCount(ctx, LinkQuery().SetWithSoftDeleted(true)); count != 13 {
+		t.Errorf("Expected the removed links to be hard deleted, got %d stored links", count)
+	}
+}
+
+func TestStoreLinkCountersConcurrent(t *testing.T) {
+	// A file database, so the goroutines use separate connections
+	db := initDB(filepath.Join(t.TempDir(), "counters.db"))
+	defer db.Close()
+
+	store := createTestStore(t, db, "feeds_counters", "links_counters")
+	ctx := context.Background()
+
+	link := NewLink().SetFeedID("feed1").SetTitle("Link").SetURL("https://example.com/1").SetStatus(LINK_STATUS_ACTIVE)
+	if err := store.LinkCreate(ctx, link); err != nil {
+		t.Fatalf("LinkCreate should succeed, but got error: %v", err)
+	}
+
+	const workers = 10
+	const iterations = 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*iterations*2)
+	maxViews := make(chan int64, workers*iterations)
+
+	for worker := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range iterations {
+				views, err := store.LinkIncrementViews(ctx, link.ID(), 1)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				maxViews <- views
+
+				if _, _, err := store.LinkVote(ctx, link.ID(), worker%2 == 0); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	close(maxViews)
+
+	for err := range errs {
+		t.Fatalf("Concurrent counter updates should succeed, but got error: %v", err)
+	}
+
+	// Every increment returned a distinct number of views
+	seen := map[int64]bool{}
+	for views := range maxViews {
+		if seen[views] {
+			t.Errorf("LinkIncrementViews returned %d views twice", views)
+		}
+		seen[views] = true
+	}
+
+	found, err := store.LinkFindByID(ctx, link.ID())
+	if err != nil {
+		t.Fatalf("LinkFindByID should succeed, but got error: %v", err)
+	}
+
+	views, _ := found.ViewsInt64()
+	votesUp, _ := found.VotesUpInt64()
+	votesDown, _ := found.VotesDownInt64()
+
+	if views != workers*iterations {
+		t.Errorf("Expected %d views, got %d", workers*iterations, views)
+	}
+	if votesUp != workers/2*iterations || votesDown != workers/2*iterations {
+		t.Errorf("Expected %d up and down votes, got %d and %d", workers/2*iterations, votesUp, votesDown)
+	}
+}
+
+func TestStoreModerationAutoHideThreshold(t *testing.T) {
+	db := initDB(":memory:")
+	defer db.Close()
+
+	_, err := NewStore(NewStoreOptions{
+		DB:                          db,
+		FeedTableName:               "feed_auto_hide",
+		LinkTableName:               "link_auto_hide",
+		ModerationAutoHideThreshold: -1,
+	})
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("NewStore should return a validation error for a negative auto hide threshold, but got: %v", err)
+	}
+}
+
+func TestFeedNextFetchAt(t *testing.T) {
+	feed := NewFeed()
+	if feed.NextFetchAt() != sb.NULL_DATETIME {
+		t.Errorf("Expected a feed which was never fetched to be due at once, got '%s'", feed.NextFetchAt())
+	}
+
+	feed.SetFetchInterval("3600").SetLastFetchedAt("2020-01-01 00:00:00")
+	if feed.NextFetchAt() != "2020-01-01 01:00:00" {
+		t.Errorf("Expected the feed to be due one fetch interval after its last fetch, got '%s'", feed.NextFetchAt())
+	}
+
+	feed.SetFetchInterval("60")
+	if feed.NextFetchAt() != "2020-01-01 00:01:00" {
+		t.Errorf("Expected a new fetch interval to move the next fetch, got '%s'", feed.NextFetchAt())
+	}
+
+	feed.SetFetchInterval("0")
+	if feed.NextFetchAt() != "2020-01-01 00:10:00" {
+		t.Errorf("Expected an invalid fetch interval to count as the default, got '%s'", feed.NextFetchAt())
+	}
+}